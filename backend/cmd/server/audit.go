@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"hkers-backend/internal/audit"
+	"hkers-backend/internal/config"
+	databaseconfig "hkers-backend/internal/config/database"
+)
+
+const auditUsage = `usage:
+  server audit verify                 walk the audit log hash chain and report the first broken link
+  server audit prune [-before TIME]   archive entries changed before TIME (RFC 3339, default now - AUDIT_RETENTION)
+                                      into a signed checkpoint and delete them`
+
+// runAudit runs an audit maintenance subcommand and returns the process exit code.
+func runAudit(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, auditUsage)
+		return 2
+	}
+
+	ctx := context.Background()
+	pool, err := databaseconfig.InitDB(ctx, &cfg.Database)
+	if err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return 1
+	}
+	defer pool.Close()
+
+	auditService := audit.NewService(pool, &cfg.Audit)
+
+	switch args[0] {
+	case "verify":
+		report, err := auditService.VerifyChain(ctx)
+		if err != nil {
+			log.Printf("Failed to verify audit log: %v", err)
+			return 1
+		}
+		printJSON(report)
+		if !report.Valid {
+			return 1
+		}
+		return 0
+
+	case "prune":
+		flags := flag.NewFlagSet("audit prune", flag.ContinueOnError)
+		before := flags.String("before", "", "archive entries changed before this RFC 3339 time")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		cutoff := time.Now().Add(-auditService.Retention())
+		if *before != "" {
+			if cutoff, err = time.Parse(time.RFC3339, *before); err != nil {
+				log.Printf("Invalid -before: %v", err)
+				return 2
+			}
+		}
+
+		checkpoint, err := auditService.Prune(ctx, cutoff)
+		if errors.Is(err, audit.ErrNothingToPrune) {
+			log.Printf("No audit log entries changed before %s", cutoff.Format(time.RFC3339))
+			return 0
+		}
+		if err != nil {
+			log.Printf("Failed to prune audit log: %v", err)
+			return 1
+		}
+		printJSON(checkpoint)
+		return 0
+
+	default:
+		fmt.Fprintln(os.Stderr, auditUsage)
+		return 2
+	}
+}
+
+func printJSON(v any) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"hkers-backend/internal/alert"
+	"hkers-backend/internal/config"
+	databaseconfig "hkers-backend/internal/config/database"
+)
+
+const districtsUsage = `usage:
+  server districts load [-srid N] FILE   load the 18 district boundaries from a GeoJSON FeatureCollection
+                                         (FILE "-" reads stdin); -srid overrides the file's crs
+  server districts count                 report how many district boundaries are loaded`
+
+// runDistricts runs a district boundary subcommand and returns the process exit code.
+func runDistricts(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, districtsUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "load", "count":
+	default:
+		fmt.Fprintln(os.Stderr, districtsUsage)
+		return 2
+	}
+
+	ctx := context.Background()
+	pool, err := databaseconfig.InitDB(ctx, &cfg.Database)
+	if err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return 1
+	}
+	defer pool.Close()
+
+	alertService := alert.NewService(pool)
+
+	if args[0] == "count" {
+		count, err := alertService.CountDistricts(ctx)
+		if err != nil {
+			log.Printf("Failed to count districts: %v", err)
+			return 1
+		}
+		printJSON(map[string]any{"loaded": count, "expected": len(alert.Districts)})
+		return 0
+	}
+
+	flags := flag.NewFlagSet("districts load", flag.ContinueOnError)
+	srid := flags.Int("srid", 0, "SRID of the file's coordinates (default: the file's crs, or 4326)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, districtsUsage)
+		return 2
+	}
+
+	var input io.Reader = os.Stdin
+	if name := flags.Arg(0); name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			log.Printf("Failed to open boundaries: %v", err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+
+	boundaries, err := alert.ParseDistrictBoundaries(input, int32(*srid))
+	if err != nil {
+		log.Printf("Failed to read boundaries: %v", err)
+		return 1
+	}
+	report, err := alertService.LoadDistricts(ctx, boundaries)
+	if err != nil {
+		log.Printf("Failed to load districts: %v", err)
+		return 1
+	}
+	printJSON(report)
+	if len(report.Missing) > 0 {
+		log.Printf("WARNING: the file has no boundary for districts %v; their stored boundaries were left as they were", report.Missing)
+	}
+	return 0
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/gob"
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/gin-gonic/gin"
 
@@ -31,6 +32,14 @@ func main() {
 		gin.SetMode(cfg.Server.GinMode)
 	}
 
+	// Maintenance subcommands run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "audit" {
+		os.Exit(runAudit(cfg, os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "districts" {
+		os.Exit(runDistricts(cfg, os.Args[2:]))
+	}
+
 	// Bootstrap all application components
 	bootstrap, err := app.Bootstrap(cfg)
 	if err != nil {
@@ -38,6 +47,8 @@ func main() {
 	}
 	defer bootstrap.Database.Close()
 	defer bootstrap.Redis.Close()
+	defer bootstrap.NewsWorker.Stop()
+	defer bootstrap.DevOIDC.Close()
 
 	// Start server
 	addr := cfg.Server.Host + ":" + cfg.Server.Port
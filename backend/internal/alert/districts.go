@@ -0,0 +1,212 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var ErrInvalidBoundaries = errors.New("invalid district boundaries")
+
+// District is one of Hong Kong's 18 districts, with the codes used in news tags.
+type District struct {
+	Code   string `json:"code"`
+	NameEn string `json:"name_en"`
+	NameZh string `json:"name_zh"`
+}
+
+// Districts lists the 18 districts. Their boundaries are loaded separately,
+// from the government's district boundary data (see LoadDistricts).
+var Districts = []District{
+	{"CW", "Central and Western", "中西區"},
+	{"WC", "Wan Chai", "灣仔"},
+	{"EA", "Eastern", "東區"},
+	{"SO", "Southern", "南區"},
+	{"YTM", "Yau Tsim Mong", "油尖旺"},
+	{"SSP", "Sham Shui Po", "深水埗"},
+	{"KC", "Kowloon City", "九龍城"},
+	{"WTS", "Wong Tai Sin", "黃大仙"},
+	{"KT", "Kwun Tong", "觀塘"},
+	{"KWT", "Kwai Tsing", "葵青"},
+	{"TW", "Tsuen Wan", "荃灣"},
+	{"TM", "Tuen Mun", "屯門"},
+	{"YL", "Yuen Long", "元朗"},
+	{"NO", "North", "北區"},
+	{"TP", "Tai Po", "大埔"},
+	{"ST", "Sha Tin", "沙田"},
+	{"SK", "Sai Kung", "西貢"},
+	{"IS", "Islands", "離島"},
+}
+
+// WGS84 is the SRID of GeoJSON without a crs member.
+const WGS84 = 4326
+
+// DistrictBoundaries holds the polygons of a GeoJSON file, grouped by district code.
+type DistrictBoundaries struct {
+	SRID     int32
+	Polygons map[string][]json.RawMessage
+	Skipped  int // Features that named no district or had no polygon
+}
+
+// DistrictLoadReport describes what LoadDistricts stored.
+type DistrictLoadReport struct {
+	Loaded          []string `json:"loaded"`
+	Missing         []string `json:"missing"` // Districts without features, left as they were
+	SkippedFeatures int      `json:"skipped_features"`
+}
+
+type featureCollection struct {
+	Type string `json:"type"`
+	CRS  *struct {
+		Properties struct {
+			Name string `json:"name"` // e.g. "urn:ogc:def:crs:EPSG::2326"
+		} `json:"properties"`
+	} `json:"crs"`
+	Features []struct {
+		Properties map[string]any  `json:"properties"`
+		Geometry   json.RawMessage `json:"geometry"`
+	} `json:"features"`
+}
+
+var crsCode = regexp.MustCompile(`EPSG:+(\d+)$`)
+
+// ParseDistrictBoundaries reads a GeoJSON FeatureCollection of district
+// boundaries, such as the government's district boundary data. A feature
+// belongs to the district one of its properties names, in English or Chinese,
+// with or without "District" or 區; a district may span several features. The
+// SRID comes from the file's crs member, or srid if it is positive.
+func ParseDistrictBoundaries(r io.Reader, srid int32) (*DistrictBoundaries, error) {
+	var collection featureCollection
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBoundaries, err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%w: expected a FeatureCollection, got %q", ErrInvalidBoundaries, collection.Type)
+	}
+
+	boundaries := &DistrictBoundaries{SRID: WGS84, Polygons: map[string][]json.RawMessage{}}
+	if collection.CRS != nil {
+		if m := crsCode.FindStringSubmatch(collection.CRS.Properties.Name); m != nil {
+			code, err := strconv.ParseInt(m[1], 10, 32)
+			if err == nil {
+				boundaries.SRID = int32(code)
+			}
+		}
+	}
+	if srid > 0 {
+		boundaries.SRID = srid
+	}
+
+	for _, feature := range collection.Features {
+		var geometry struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(feature.Geometry, &geometry); err != nil ||
+			(geometry.Type != "Polygon" && geometry.Type != "MultiPolygon") {
+			boundaries.Skipped++
+			continue
+		}
+		code, ok := featureDistrict(feature.Properties)
+		if !ok {
+			boundaries.Skipped++
+			continue
+		}
+		boundaries.Polygons[code] = append(boundaries.Polygons[code], feature.Geometry)
+	}
+	return boundaries, nil
+}
+
+// featureDistrict returns the district named by one of a feature's properties,
+// looking at the properties in name order.
+func featureDistrict(properties map[string]any) (string, bool) {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		value, ok := properties[key].(string)
+		if !ok {
+			continue
+		}
+		name := normalizeDistrictName(value)
+		for _, d := range Districts {
+			if name == normalizeDistrictName(d.NameEn) || name == normalizeDistrictName(d.NameZh) {
+				return d.Code, true
+			}
+		}
+	}
+	return "", false
+}
+
+// normalizeDistrictName folds the spellings of a district name, so that
+// "CENTRAL & WESTERN DISTRICT" and "Central and Western" compare equal, as do
+// 沙田區 and 沙田.
+func normalizeDistrictName(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.TrimSuffix(name, " district")
+	return strings.TrimSuffix(name, "區")
+}
+
+// LoadDistricts stores the boundaries of the districts in boundaries, replacing
+// any loaded before, in one transaction. Districts the file has no features for
+// are left as they were.
+func (s *Service) LoadDistricts(ctx context.Context, boundaries *DistrictBoundaries) (*DistrictLoadReport, error) {
+	report := &DistrictLoadReport{
+		Loaded:          []string{},
+		Missing:         []string{},
+		SkippedFeatures: boundaries.Skipped,
+	}
+	for _, d := range Districts {
+		if len(boundaries.Polygons[d.Code]) == 0 {
+			report.Missing = append(report.Missing, d.Code)
+		}
+	}
+
+	err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		report.Loaded = report.Loaded[:0]
+		for _, d := range Districts {
+			polygons := boundaries.Polygons[d.Code]
+			if len(polygons) == 0 {
+				continue
+			}
+			geometry, err := json.Marshal(map[string]any{
+				"type":       "GeometryCollection",
+				"geometries": polygons,
+			})
+			if err != nil {
+				return err
+			}
+			if err := q.UpsertDistrict(ctx, db.UpsertDistrictParams{
+				Code:     d.Code,
+				NameEn:   d.NameEn,
+				NameZh:   d.NameZh,
+				Geometry: string(geometry),
+				Srid:     boundaries.SRID,
+			}); err != nil {
+				return fmt.Errorf("district %s: %w", d.Code, err)
+			}
+			report.Loaded = append(report.Loaded, d.Code)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// CountDistricts returns how many district boundaries are loaded.
+func (s *Service) CountDistricts(ctx context.Context) (int64, error) {
+	return s.queries.CountDistricts(ctx)
+}
@@ -0,0 +1,76 @@
+package alert
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+)
+
+// Handler handles alert HTTP requests.
+type Handler struct {
+	alertService ServiceInterface
+}
+
+// NewHandler creates a new alert Handler instance.
+func NewHandler(alertService ServiceInterface) HandlerInterface {
+	return &Handler{
+		alertService: alertService,
+	}
+}
+
+// ListAlerts returns the official alerts in force, worst first. With lat and
+// lng, only the alerts covering that point are returned.
+// GET /api/v1/alerts?lat=&lng=
+func (h *Handler) ListAlerts(ctx *gin.Context) {
+	var point *Point
+	if ctx.Query("lat") != "" || ctx.Query("lng") != "" {
+		lat, err := strconv.ParseFloat(ctx.Query("lat"), 64)
+		if err != nil || lat < -90 || lat > 90 {
+			response.Error(ctx, http.StatusBadRequest, "lat must be between -90 and 90 and is required with lng")
+			return
+		}
+		lng, err := strconv.ParseFloat(ctx.Query("lng"), 64)
+		if err != nil || lng < -180 || lng > 180 {
+			response.Error(ctx, http.StatusBadRequest, "lng must be between -180 and 180 and is required with lat")
+			return
+		}
+		point = &Point{Lat: lat, Lng: lng}
+	}
+
+	alerts, err := h.alertService.ListActiveAlerts(ctx.Request.Context(), point)
+	if err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to list alerts")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"alerts": alerts,
+		"total":  len(alerts),
+	})
+}
+
+// GetAlert returns an alert, including ones no longer in force.
+// GET /api/v1/alerts/:id
+func (h *Handler) GetAlert(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid id")
+		return
+	}
+
+	alert, err := h.alertService.GetAlert(ctx.Request.Context(), int32(id))
+	if err != nil {
+		if errors.Is(err, ErrAlertNotFound) {
+			response.Error(ctx, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(ctx, http.StatusInternalServerError, "Failed to get alert")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, alert)
+}
@@ -0,0 +1,19 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceInterface defines the interface for alert services
+type ServiceInterface interface {
+	ListActiveAlerts(ctx context.Context, point *Point) ([]Alert, error)
+	GetAlert(ctx context.Context, id int32) (*Alert, error)
+}
+
+// HandlerInterface defines the interface for alert HTTP handlers
+type HandlerInterface interface {
+	ListAlerts(ctx *gin.Context)
+	GetAlert(ctx *gin.Context)
+}
@@ -0,0 +1,22 @@
+package alert
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// RegisterAlertRoutes registers alert routes on the given router.
+func RegisterAlertRoutes(router *gin.Engine, alertSvc ServiceInterface, jwtManager response.JWTManager) {
+	h := NewHandler(alertSvc)
+
+	// Alert routes - require JWT authentication; alerts are read like news
+	alerts := router.Group("/api/v1/alerts")
+	alerts.Use(middleware.JWTAuth(jwtManager))
+	{
+		alerts.GET("", middleware.RequirePermission(db.AppPermissionReadNews), h.ListAlerts)
+		alerts.GET("/:id", middleware.RequirePermission(db.AppPermissionReadNews), h.GetAlert)
+	}
+}
@@ -0,0 +1,139 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hkers-backend/internal/core/dbtx"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var ErrAlertNotFound = errors.New("alert not found")
+
+// Alert is the API representation of an official CAP alert. Area is a GeoJSON
+// MultiPolygon, or null if the alert has no usable shapes. TerritoryWide alerts
+// cover the sender's whole territory; others without an area are located by
+// the districts their geocodes name, if any.
+// Infos holds the texts of every <info> block, e.g. in English and Chinese.
+type Alert struct {
+	ID            int32              `json:"id"`
+	NewsID        pgtype.Int4        `json:"news_id"`
+	Sender        string             `json:"sender"`
+	Identifier    string             `json:"identifier"`
+	SentAt        pgtype.Timestamptz `json:"sent_at"`
+	Status        string             `json:"status"`
+	MsgType       string             `json:"msg_type"`
+	Event         string             `json:"event"`
+	Headline      pgtype.Text        `json:"headline"`
+	Description   pgtype.Text        `json:"description"`
+	Instruction   pgtype.Text        `json:"instruction"`
+	Language      pgtype.Text        `json:"language"`
+	Categories    []string           `json:"categories"`
+	Severity      db.AlertSeverity   `json:"severity"`
+	Urgency       db.AlertUrgency    `json:"urgency"`
+	Certainty     db.AlertCertainty  `json:"certainty"`
+	EffectiveAt   pgtype.Timestamptz `json:"effective_at"`
+	OnsetAt       pgtype.Timestamptz `json:"onset_at"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	AreaDesc      pgtype.Text        `json:"area_desc"`
+	Area          json.RawMessage    `json:"area"`
+	TerritoryWide bool               `json:"territory_wide"`
+	Geocodes      json.RawMessage    `json:"geocodes"`
+	Infos         json.RawMessage    `json:"infos"`
+	SupersededAt  pgtype.Timestamptz `json:"superseded_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Active        bool               `json:"active"`
+}
+
+// Point is a WGS84 location.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Service handles alert business logic. Alerts are written by the news feed
+// worker only; the service reads them and loads the district boundaries that
+// locate them.
+type Service struct {
+	queries *db.Queries
+	runner  *dbtx.Runner
+}
+
+// NewService creates a new alert service instance.
+func NewService(pool *pgxpool.Pool) *Service {
+	return &Service{
+		queries: db.New(pool),
+		runner:  dbtx.New(pool),
+	}
+}
+
+// ListActiveAlerts returns the alerts in force, worst first. With a point,
+// only the alerts covering it are returned.
+func (s *Service) ListActiveAlerts(ctx context.Context, point *Point) ([]Alert, error) {
+	params := db.ListActiveAlertsParams{}
+	if point != nil {
+		params.Lat = pgtype.Float8{Float64: point.Lat, Valid: true}
+		params.Lng = pgtype.Float8{Float64: point.Lng, Valid: true}
+	}
+
+	rows, err := s.queries.ListActiveAlerts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]Alert, 0, len(rows))
+	for _, row := range rows {
+		alerts = append(alerts, toAlert(db.GetAlertByIDRow(row)))
+	}
+	return alerts, nil
+}
+
+// GetAlert returns an alert, whether or not it is still in force.
+func (s *Service) GetAlert(ctx context.Context, id int32) (*Alert, error) {
+	row, err := s.queries.GetAlertByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, err
+	}
+	alert := toAlert(row)
+	return &alert, nil
+}
+
+func toAlert(row db.GetAlertByIDRow) Alert {
+	return Alert{
+		ID:            row.ID,
+		NewsID:        row.NewsID,
+		Sender:        row.Sender,
+		Identifier:    row.Identifier,
+		SentAt:        row.SentAt,
+		Status:        row.Status,
+		MsgType:       row.MsgType,
+		Event:         row.Event,
+		Headline:      row.Headline,
+		Description:   row.Description,
+		Instruction:   row.Instruction,
+		Language:      row.Language,
+		Categories:    row.Categories,
+		Severity:      row.Severity,
+		Urgency:       row.Urgency,
+		Certainty:     row.Certainty,
+		EffectiveAt:   row.EffectiveAt,
+		OnsetAt:       row.OnsetAt,
+		ExpiresAt:     row.ExpiresAt,
+		AreaDesc:      row.AreaDesc,
+		Area:          json.RawMessage(row.Area),
+		TerritoryWide: row.TerritoryWide,
+		Geocodes:      json.RawMessage(row.Geocodes),
+		Infos:         json.RawMessage(row.Infos),
+		SupersededAt:  row.SupersededAt,
+		CreatedAt:     row.CreatedAt,
+		Active:        row.Active,
+	}
+}
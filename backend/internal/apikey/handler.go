@@ -0,0 +1,121 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// Handler handles API key admin HTTP requests.
+type Handler struct {
+	apiKeyService ServiceInterface
+}
+
+// NewHandler creates a new API key Handler instance.
+func NewHandler(apiKeyService ServiceInterface) HandlerInterface {
+	return &Handler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// createAPIKeyRequest is the body accepted when creating a key.
+// RateLimitPerMinute defaults to DefaultRateLimitPerMinute; 0 disables the limit.
+type createAPIKeyRequest struct {
+	Name               string   `json:"name" binding:"required,max=255"`
+	Permissions        []string `json:"permissions" binding:"required"`
+	RateLimitPerMinute *int32   `json:"rate_limit_per_minute" binding:"omitempty,gte=0,lte=100000"`
+}
+
+// CreateAPIKey creates a service account key. The full key is returned only in this response.
+// POST /api/v1/admin/api-keys
+func (h *Handler) CreateAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	// Keys are always owned by a user, never minted by another key
+	if _, ok := middleware.GetUserIDFromContext(ctx); !ok {
+		response.Error(ctx, http.StatusForbidden, "API keys can only be created by users")
+		return
+	}
+	creator, ok := middleware.GetPrincipalFromContext(ctx)
+	if !ok {
+		response.Error(ctx, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	input := CreateAPIKeyInput{
+		Name:               req.Name,
+		RateLimitPerMinute: DefaultRateLimitPerMinute,
+	}
+	if req.RateLimitPerMinute != nil {
+		input.RateLimitPerMinute = *req.RateLimitPerMinute
+	}
+	for _, permission := range req.Permissions {
+		input.Permissions = append(input.Permissions, db.AppPermission(permission))
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(ctx.Request.Context(), creator, input)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to create API key")
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, key)
+}
+
+// ListAPIKeys returns every key, newest first. Secrets are never included.
+// GET /api/v1/admin/api-keys
+func (h *Handler) ListAPIKeys(ctx *gin.Context) {
+	keys, err := h.apiKeyService.ListAPIKeys(ctx.Request.Context())
+	if err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to list API keys")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"api_keys": keys,
+	})
+}
+
+// RevokeAPIKey permanently disables a key.
+// DELETE /api/v1/admin/api-keys/:id
+func (h *Handler) RevokeAPIKey(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid id")
+		return
+	}
+
+	key, err := h.apiKeyService.RevokeAPIKey(ctx.Request.Context(), int32(id))
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to revoke API key")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, key)
+}
+
+// writeServiceError maps service errors to HTTP responses.
+func writeServiceError(ctx *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrAPIKeyNotFound):
+		response.Error(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrAlreadyRevoked):
+		response.Error(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrNoPermissions), errors.Is(err, ErrUnknownPermission):
+		response.Error(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrPermissionNotGrantable):
+		response.Error(ctx, http.StatusForbidden, err.Error())
+	default:
+		response.Error(ctx, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,24 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+)
+
+// ServiceInterface defines the interface for API key services
+type ServiceInterface interface {
+	CreateAPIKey(ctx context.Context, creator *response.Principal, input CreateAPIKeyInput) (*CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int32) (*APIKey, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*response.Principal, error)
+}
+
+// HandlerInterface defines the interface for API key HTTP handlers
+type HandlerInterface interface {
+	CreateAPIKey(ctx *gin.Context)
+	ListAPIKeys(ctx *gin.Context)
+	RevokeAPIKey(ctx *gin.Context)
+}
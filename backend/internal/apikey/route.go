@@ -0,0 +1,24 @@
+package apikey
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// RegisterAPIKeyRoutes registers the API key admin routes on the given router.
+func RegisterAPIKeyRoutes(router *gin.Engine, apiKeySvc ServiceInterface, jwtManager response.JWTManager) {
+	h := NewHandler(apiKeySvc)
+
+	// Admin API key routes - require JWT authentication
+	admin := router.Group("/api/v1/admin/api-keys")
+	admin.Use(middleware.JWTAuth(jwtManager))
+	admin.Use(middleware.RequirePermission(db.AppPermissionManageApiKeys))
+	{
+		admin.GET("", h.ListAPIKeys)
+		admin.POST("", h.CreateAPIKey)
+		admin.DELETE("/:id", h.RevokeAPIKey)
+	}
+}
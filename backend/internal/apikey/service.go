@@ -0,0 +1,316 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/pgerr"
+	"hkers-backend/internal/core/response"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	ErrAPIKeyNotFound         = errors.New("API key not found")
+	ErrAlreadyRevoked         = errors.New("API key is already revoked")
+	ErrNoPermissions          = errors.New("at least one permission is required")
+	ErrUnknownPermission      = errors.New("unknown permission")
+	ErrPermissionNotGrantable = errors.New("cannot grant a permission you do not hold")
+)
+
+const (
+	// DefaultRateLimitPerMinute applies when a key is created without an explicit limit.
+	DefaultRateLimitPerMinute = 60
+
+	// keyScheme starts every key so that leaked keys are easy to recognise and scan for.
+	keyScheme = "hkers_"
+
+	// rateLimitKeyPrefix + key ID + minute window counts requests per key in Redis.
+	rateLimitKeyPrefix = "apikey:rate:"
+)
+
+// APIKey is a service account key as shown to admins. The secret is never included.
+type APIKey struct {
+	ID                 int32              `json:"id"`
+	Name               string             `json:"name"`
+	Prefix             string             `json:"prefix"`
+	Permissions        []db.AppPermission `json:"permissions"`
+	RateLimitPerMinute int32              `json:"rate_limit_per_minute"`
+	CreatedBy          pgtype.Int4        `json:"created_by"`
+	LastUsedAt         pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt          pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+}
+
+// CreatedAPIKey is a newly created key. Key holds the full secret and is only
+// available in the creation response.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKeyInput describes a new service account key.
+type CreateAPIKeyInput struct {
+	Name               string
+	Permissions        []db.AppPermission
+	RateLimitPerMinute int32
+}
+
+// Service manages service account API keys and authenticates requests made with them.
+type Service struct {
+	queries *db.Queries
+	runner  *dbtx.Runner
+	redis   *redis.Client
+}
+
+// NewService creates a new API key service instance.
+// Rate limits are counted in Redis; with a nil client they are not enforced.
+func NewService(pool *pgxpool.Pool, redisClient *redis.Client) *Service {
+	return &Service{
+		queries: db.New(pool),
+		runner:  dbtx.New(pool),
+		redis:   redisClient,
+	}
+}
+
+// CreateAPIKey creates a key scoped to the given permissions on behalf of creator.
+// A key can only be granted permissions the creator holds.
+func (s *Service) CreateAPIKey(ctx context.Context, creator *response.Principal, input CreateAPIKeyInput) (*CreatedAPIKey, error) {
+	if len(input.Permissions) == 0 {
+		return nil, ErrNoPermissions
+	}
+	held := make(map[db.AppPermission]struct{}, len(creator.Permissions))
+	for _, permission := range creator.Permissions {
+		held[permission] = struct{}{}
+	}
+	var notHeld []db.AppPermission
+	for _, permission := range input.Permissions {
+		if _, ok := held[permission]; ok {
+			continue
+		}
+		// A name that is no permission at all is a bad request, not a refusal
+		if _, err := s.queries.GetPermissionByName(ctx, permission); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) || pgerr.IsInvalidTextInput(err) {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, permission)
+			}
+			return nil, err
+		}
+		notHeld = append(notHeld, permission)
+	}
+	if len(notHeld) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPermissionNotGrantable, notHeld[0])
+	}
+
+	prefix, key, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var created APIKey
+	err = s.runner.InTx(ctx, func(q *db.Queries) error {
+		row, err := q.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+			Name:               input.Name,
+			Prefix:             prefix,
+			KeyHash:            hashAPIKey(key),
+			RateLimitPerMinute: input.RateLimitPerMinute,
+			CreatedBy:          pgtype.Int4{Int32: creator.UserID, Valid: creator.UserID > 0},
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, permission := range input.Permissions {
+			if err := q.AddAPIKeyPermission(ctx, db.AddAPIKeyPermissionParams{
+				ApiKeyID: row.ID,
+				Name:     permission,
+			}); err != nil {
+				return err
+			}
+		}
+
+		permissions, err := q.GetAPIKeyPermissions(ctx, row.ID)
+		if err != nil {
+			return err
+		}
+		created = toAPIKey(row, permissions)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreatedAPIKey{APIKey: created, Key: key}, nil
+}
+
+// ListAPIKeys returns every key, newest first, including revoked ones.
+func (s *Service) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.queries.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]APIKey, 0, len(rows))
+	for _, row := range rows {
+		permissions, err := s.queries.GetAPIKeyPermissions(ctx, row.ID)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, toAPIKey(row, permissions))
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey permanently disables a key. Revoked keys stay listed for auditing.
+func (s *Service) RevokeAPIKey(ctx context.Context, id int32) (*APIKey, error) {
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.ApiKey, error) {
+		return q.RevokeAPIKey(ctx, id)
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		// Distinguish a missing key from one that was already revoked
+		if _, getErr := s.queries.GetAPIKeyByID(ctx, id); getErr != nil {
+			if errors.Is(getErr, pgx.ErrNoRows) {
+				return nil, ErrAPIKeyNotFound
+			}
+			return nil, getErr
+		}
+		return nil, ErrAlreadyRevoked
+	}
+
+	permissions, err := s.queries.GetAPIKeyPermissions(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	key := toAPIKey(row, permissions)
+	return &key, nil
+}
+
+// AuthenticateAPIKey resolves the service account principal for a key presented
+// in a request, enforcing the key's rate limit and recording its use.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (*response.Principal, error) {
+	prefix, ok := parseAPIKey(key)
+	if !ok {
+		return nil, response.ErrInvalidAPIKey
+	}
+
+	row, err := s.queries.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, response.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(row.KeyHash)) != 1 || row.RevokedAt.Valid {
+		return nil, response.ErrInvalidAPIKey
+	}
+
+	if err := s.checkRateLimit(ctx, row); err != nil {
+		return nil, err
+	}
+
+	// Usage bookkeeping rather than a change anyone makes, so it bypasses the runner
+	if err := s.queries.TouchAPIKeyLastUsed(ctx, row.ID); err != nil {
+		log.Printf("apikey: failed to record use of key %d: %v", row.ID, err)
+	}
+
+	permissions, err := s.queries.GetAPIKeyPermissions(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []db.AppPermission{}
+	}
+
+	return &response.Principal{
+		APIKeyID:    row.ID,
+		Username:    row.Name,
+		IsActive:    true,
+		Roles:       []db.AppRole{},
+		Permissions: permissions,
+	}, nil
+}
+
+// checkRateLimit counts the request against the key's fixed one-minute window.
+// Redis failures are logged and the request allowed, so an outage does not lock out partners.
+func (s *Service) checkRateLimit(ctx context.Context, key db.ApiKey) error {
+	if s.redis == nil || key.RateLimitPerMinute <= 0 {
+		return nil
+	}
+
+	counterKey := fmt.Sprintf("%s%d:%d", rateLimitKeyPrefix, key.ID, time.Now().Unix()/60)
+	pipe := s.redis.TxPipeline()
+	count := pipe.Incr(ctx, counterKey)
+	pipe.Expire(ctx, counterKey, 2*time.Minute)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("apikey: failed to check rate limit for key %d: %v", key.ID, err)
+		return nil
+	}
+
+	if count.Val() > int64(key.RateLimitPerMinute) {
+		return response.ErrRateLimited
+	}
+	return nil
+}
+
+func toAPIKey(row db.ApiKey, permissions []db.AppPermission) APIKey {
+	if permissions == nil {
+		permissions = []db.AppPermission{}
+	}
+	return APIKey{
+		ID:                 row.ID,
+		Name:               row.Name,
+		Prefix:             row.Prefix,
+		Permissions:        permissions,
+		RateLimitPerMinute: row.RateLimitPerMinute,
+		CreatedBy:          row.CreatedBy,
+		LastUsedAt:         row.LastUsedAt,
+		RevokedAt:          row.RevokedAt,
+		CreatedAt:          row.CreatedAt,
+	}
+}
+
+// newAPIKey returns a key of the form "hkers_<prefix>.<secret>". The prefix
+// identifies the key for lookup and display; the secret is 256 random bits.
+func newAPIKey() (prefix, key string, err error) {
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix = keyScheme + hex.EncodeToString(idBytes)
+	return prefix, prefix + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// parseAPIKey extracts the lookup prefix from a presented key.
+func parseAPIKey(key string) (string, bool) {
+	prefix, secret, ok := strings.Cut(strings.TrimSpace(key), ".")
+	if !ok || secret == "" || !strings.HasPrefix(prefix, keyScheme) {
+		return "", false
+	}
+	return prefix, true
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
@@ -3,25 +3,47 @@ package app
 import (
 	"context"
 	"log"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 
+	"hkers-backend/internal/alert"
+	"hkers-backend/internal/apikey"
+	"hkers-backend/internal/audit"
 	"hkers-backend/internal/auth"
+	"hkers-backend/internal/checkin"
 	"hkers-backend/internal/config"
 	databaseconfig "hkers-backend/internal/config/database"
 	redisconfig "hkers-backend/internal/config/redis"
+	"hkers-backend/internal/devoidc"
+	"hkers-backend/internal/donation"
+	"hkers-backend/internal/news"
+	"hkers-backend/internal/newsfeed"
+	"hkers-backend/internal/rbac"
+	"hkers-backend/internal/station"
 	"hkers-backend/internal/user"
 )
 
 // BootstrapResult contains all initialized components needed to run the server
 type BootstrapResult struct {
-	Database    *pgxpool.Pool
-	Redis       *redis.Client
-	AuthService auth.ServiceInterface
-	UserService user.ServiceInterface
-	Router      *gin.Engine
+	Database        *pgxpool.Pool
+	Redis           *redis.Client
+	JWTManager      *auth.JWTManager
+	AuthProviders   auth.ProviderRegistry
+	UserService     user.ServiceInterface
+	RBACService     rbac.ServiceInterface
+	StationService  station.ServiceInterface
+	CheckinService  checkin.ServiceInterface
+	DonationService donation.ServiceInterface
+	APIKeyService   apikey.ServiceInterface
+	AuditService    audit.ServiceInterface
+	NewsService     news.ServiceInterface
+	AlertService    alert.ServiceInterface
+	NewsWorker      *newsfeed.Worker
+	DevOIDC         *devoidc.Provider // nil unless DEV_OIDC_ENABLED
+	Router          *gin.Engine
 }
 
 // Bootstrap initializes all application components
@@ -42,37 +64,135 @@ func Bootstrap(cfg *config.Config) (*BootstrapResult, error) {
 		return nil, err
 	}
 
-	// Initialize services
-	var authService auth.ServiceInterface
-	if cfg.Auth.OIDC.Issuer != "" {
-		log.Printf("Initializing OIDC service with issuer: %s", cfg.Auth.OIDC.Issuer)
-		authService, err = auth.NewService(&cfg.Auth.OIDC)
+	// Load JWT signing and verification keys
+	keys, err := auth.LoadKeySet(&cfg.Auth.JWT)
+	if err != nil {
+		pool.Close()
+		redisClient.Close()
+		return nil, err
+	}
+
+	// Create JWT manager for token-based authentication (revocation state in Redis)
+	jwtManager := auth.NewJWTManager(keys, cfg.Auth.JWT.Duration, redisClient)
+
+	// Create refresh token manager (rotating opaque tokens stored hashed in Postgres)
+	refreshTokenManager := auth.NewRefreshTokenManager(pool, jwtManager, cfg.Auth.JWT.RefreshDuration)
+
+	// Initialize OIDC providers (empty registry when none are configured)
+	authProviders, err := auth.NewRegistry(cfg.Auth.OIDCProviders)
+	if err != nil {
+		pool.Close()
+		redisClient.Close()
+		return nil, err
+	}
+
+	// Initialize the in-process development provider (never in release mode)
+	var devProvider *devoidc.Provider
+	if cfg.Auth.DevOIDC.Enabled {
+		devProvider, err = devoidc.NewProvider(&cfg.Auth.DevOIDC)
+		if err == nil {
+			devConfig := devProvider.OIDCConfig()
+			var devService *auth.Service
+			devService, err = auth.NewServiceWithClient(&devConfig, devProvider.HTTPClient())
+			if err == nil {
+				err = authProviders.Register(devService)
+			}
+		}
 		if err != nil {
+			devProvider.Close()
 			pool.Close()
 			redisClient.Close()
 			return nil, err
 		}
-		log.Printf("OIDC service initialized successfully")
+		log.Printf("WARNING: development OIDC provider enabled at %s (anyone can sign in as a seeded user)", devProvider.Issuer())
+	}
+
+	if len(authProviders.Names()) == 0 {
+		log.Printf("OIDC not configured, logins are disabled")
 	} else {
-		log.Printf("OIDC not configured, skipping OIDC service initialization")
+		log.Printf("OIDC providers initialized: %s", strings.Join(authProviders.Names(), ", "))
+	}
+
+	// Initialize RBAC service (user status, roles and permissions, cached in Redis)
+	rbacService := rbac.NewService(pool, redisClient, cfg.RBAC.PermissionCacheTTL)
+
+	// Initialize user service (status changes invalidate cached principals)
+	userService := user.NewService(pool, rbacService)
+
+	// Make sure the development users exist and are active, with their roles
+	if devProvider != nil {
+		if err := devProvider.SeedUsers(ctx, pool, rbacService); err != nil {
+			devProvider.Close()
+			pool.Close()
+			redisClient.Close()
+			return nil, err
+		}
 	}
 
-	// Initialize user service
-	userService := user.NewService(pool)
+	// Initialize station service (vector tiles cached in Redis)
+	stationService := station.NewService(pool, redisClient, &cfg.Station)
+
+	// Initialize check-in service (accepted check-ins invalidate station tiles)
+	checkinService := checkin.NewService(pool, cfg.Checkin.MaxDistanceMeters, stationService)
+
+	// Initialize donation service
+	donationService := donation.NewService(pool)
+
+	// Initialize API key service (service accounts, rate limits counted in Redis)
+	apiKeyService := apikey.NewService(pool, redisClient)
+
+	// Initialize audit service (queries, verifies and prunes the audit trail)
+	auditService := audit.NewService(pool, &cfg.Audit)
+
+	// Initialize news service
+	newsService := news.NewService(pool)
+
+	// Initialize alert service (CAP alerts stored by the news feed worker)
+	alertService := alert.NewService(pool)
+	if count, err := alertService.CountDistricts(ctx); err != nil {
+		log.Printf("Failed to count district boundaries: %v", err)
+	} else if count == 0 {
+		log.Printf("WARNING: no district boundaries loaded; geocode-only alerts and district station news match nothing until they are loaded with `server districts load`")
+	}
+
+	// Start polling the RSS/Atom and CAP feeds in NEWS_FEEDS (a no-op without feeds)
+	newsWorker := newsfeed.NewWorker(pool, &cfg.News)
+	if err := newsWorker.Start(ctx); err != nil {
+		devProvider.Close()
+		pool.Close()
+		redisClient.Close()
+		return nil, err
+	}
+	if len(cfg.News.Feeds) > 0 {
+		log.Printf("News feed ingestion started for %d feed(s)", len(cfg.News.Feeds))
+	}
 
 	// Setup router
-	router, err := NewRouter(cfg, authService, userService)
+	router, err := NewRouter(cfg, jwtManager, refreshTokenManager, authProviders, userService, rbacService, stationService, checkinService, donationService, apiKeyService, auditService, newsService, alertService, devProvider)
 	if err != nil {
+		newsWorker.Stop()
+		devProvider.Close()
 		pool.Close()
 		redisClient.Close()
 		return nil, err
 	}
 
 	return &BootstrapResult{
-		Database:    pool,
-		Redis:       redisClient,
-		AuthService: authService,
-		UserService: userService,
-		Router:      router,
+		Database:        pool,
+		Redis:           redisClient,
+		JWTManager:      jwtManager,
+		AuthProviders:   authProviders,
+		UserService:     userService,
+		RBACService:     rbacService,
+		StationService:  stationService,
+		CheckinService:  checkinService,
+		DonationService: donationService,
+		APIKeyService:   apiKeyService,
+		AuditService:    auditService,
+		NewsService:     newsService,
+		AlertService:    alertService,
+		NewsWorker:      newsWorker,
+		DevOIDC:         devProvider,
+		Router:          router,
 	}, nil
 }
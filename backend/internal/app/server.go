@@ -9,18 +9,30 @@ import (
 	"github.com/gin-contrib/sessions/redis"
 	"github.com/gin-gonic/gin"
 
+	"hkers-backend/internal/alert"
+	"hkers-backend/internal/apikey"
+	"hkers-backend/internal/audit"
 	"hkers-backend/internal/auth"
+	"hkers-backend/internal/checkin"
 	"hkers-backend/internal/config"
 	redisconfig "hkers-backend/internal/config/redis"
+	"hkers-backend/internal/devoidc"
+	"hkers-backend/internal/donation"
 	"hkers-backend/internal/health"
 	"hkers-backend/internal/middleware"
+	"hkers-backend/internal/news"
+	"hkers-backend/internal/rbac"
+	"hkers-backend/internal/station"
 	"hkers-backend/internal/user"
 )
 
 // NewRouter configures the Gin engine with middleware and route groups.
-func NewRouter(cfg *config.Config, authSvc auth.ServiceInterface, userSvc user.ServiceInterface) (*gin.Engine, error) {
+func NewRouter(cfg *config.Config, jwtManager *auth.JWTManager, refreshTokens *auth.RefreshTokenManager, authProviders auth.ProviderRegistry, userSvc user.ServiceInterface, rbacSvc rbac.ServiceInterface, stationSvc station.ServiceInterface, checkinSvc checkin.ServiceInterface, donationSvc donation.ServiceInterface, apiKeySvc apikey.ServiceInterface, auditSvc audit.ServiceInterface, newsSvc news.ServiceInterface, alertSvc alert.ServiceInterface, devOIDC *devoidc.Provider) (*gin.Engine, error) {
 	router := gin.Default()
 
+	// Request IDs for responses and audit rows
+	router.Use(middleware.RequestID())
+
 	// CORS middleware
 	router.Use(cors.New(middleware.GetCORSConfig(&cfg.CORS)))
 
@@ -39,13 +51,32 @@ func NewRouter(cfg *config.Config, authSvc auth.ServiceInterface, userSvc user.S
 	})
 	router.Use(sessions.Sessions("auth-session", store))
 
-	// Create JWT manager for token-based authentication
-	jwtManager := auth.NewJWTManager(cfg.Auth.JWT.Secret, cfg.Auth.JWT.Duration)
+	// Live user status, roles and permissions for JWTAuth (cached in Redis)
+	router.Use(middleware.Principals(rbacSvc))
+
+	// Service account authentication via X-API-Key for JWTAuth
+	router.Use(middleware.APIKeys(apiKeySvc))
+
+	// Permission lookups for RequirePermission (loaded lazily, once per request)
+	router.Use(middleware.Permissions(rbacSvc))
 
 	// Register route groups
 	health.RegisterHealthRoutes(router)
-	auth.RegisterAuthRoutes(router, authSvc, userSvc, jwtManager)
-	user.RegisterUserRoutes(router, jwtManager)
+	auth.RegisterAuthRoutes(router, authProviders, userSvc, jwtManager, refreshTokens, jwtManager)
+	user.RegisterUserRoutes(router, userSvc, jwtManager)
+	station.RegisterStationRoutes(router, stationSvc, jwtManager)
+	checkin.RegisterCheckinRoutes(router, checkinSvc, jwtManager)
+	donation.RegisterDonationRoutes(router, donationSvc, jwtManager)
+	apikey.RegisterAPIKeyRoutes(router, apiKeySvc, jwtManager)
+	rbac.RegisterRBACRoutes(router, rbacSvc, jwtManager)
+	audit.RegisterAuditRoutes(router, auditSvc, jwtManager)
+	news.RegisterNewsRoutes(router, newsSvc, jwtManager)
+	alert.RegisterAlertRoutes(router, alertSvc, jwtManager)
+
+	// Development identity provider, only when DEV_OIDC_ENABLED=true
+	if devOIDC != nil {
+		devoidc.RegisterDevOIDCRoutes(router, devOIDC)
+	}
 
 	return router, nil
 }
@@ -0,0 +1,282 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	ErrNoCheckpointSecret = errors.New("AUDIT_CHECKPOINT_SECRET is not set")
+	ErrChainBroken        = errors.New("audit log hash chain is broken")
+	ErrNothingToPrune     = errors.New("no audit log entries to prune")
+)
+
+// chainBatchSize is how many links are read per query while walking the chain.
+const chainBatchSize = 1000
+
+// Kinds of chain element a BrokenLink can point at.
+const (
+	LinkKindEntry      = "entry"
+	LinkKindCheckpoint = "checkpoint"
+)
+
+// BrokenLink is the first chain element that fails verification.
+type BrokenLink struct {
+	Kind   string `json:"kind"`
+	ID     int32  `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// VerifyReport is the outcome of walking the chain, checkpoints first.
+type VerifyReport struct {
+	Valid              bool        `json:"valid"`
+	CheckpointsChecked int         `json:"checkpoints_checked"`
+	EntriesChecked     int         `json:"entries_checked"`
+	FirstBroken        *BrokenLink `json:"first_broken,omitempty"`
+}
+
+// Checkpoint is a signed archive of a pruned chain segment.
+type Checkpoint struct {
+	ID         int32              `json:"id"`
+	FirstID    int32              `json:"first_id"`
+	LastID     int32              `json:"last_id"`
+	EntryCount int32              `json:"entry_count"`
+	PrevHash   pgtype.Text        `json:"prev_hash"`
+	LastHash   string             `json:"last_hash"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+// segment summarizes a run of chain entries that verified.
+type segment struct {
+	firstID  int32
+	lastID   int32
+	count    int
+	lastHash pgtype.Text
+}
+
+// Retention returns how old entries must be before Prune archives them by default.
+func (s *Service) Retention() time.Duration {
+	return s.retention
+}
+
+// VerifyChain checks every checkpoint's signature, link to the one before it and
+// archived entries, then every remaining entry's hash and link, and reports the
+// first break.
+// Deleting the newest entries cannot be detected, since nothing links to them yet.
+func (s *Service) VerifyChain(ctx context.Context) (*VerifyReport, error) {
+	// One snapshot, so that a concurrent prune cannot look like a break
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	q := s.queries.WithTx(tx)
+
+	checkpoints, err := q.ListAuditCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(checkpoints) > 0 && len(s.checkpointKey) == 0 {
+		return nil, ErrNoCheckpointSecret
+	}
+
+	report := &VerifyReport{Valid: true}
+	prev := pgtype.Text{}
+	for _, checkpoint := range checkpoints {
+		var reason string
+		switch {
+		case checkpoint.PrevHash != prev:
+			reason = "prev_hash does not match the previous checkpoint's last_hash"
+		case !hmac.Equal([]byte(checkpoint.Signature), []byte(s.sign(checkpoint.FirstID, checkpoint.LastID, checkpoint.EntryCount, checkpoint.PrevHash, checkpoint.LastHash))):
+			reason = "signature is invalid"
+		default:
+			if reason, err = checkArchive(ctx, q, checkpoint); err != nil {
+				return nil, err
+			}
+		}
+		if reason != "" {
+			report.Valid = false
+			report.FirstBroken = &BrokenLink{Kind: LinkKindCheckpoint, ID: checkpoint.ID, Reason: reason}
+			return report, nil
+		}
+		report.CheckpointsChecked++
+		prev = pgtype.Text{String: checkpoint.LastHash, Valid: true}
+	}
+
+	seg, broken, err := walkChain(ctx, q, prev, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	report.EntriesChecked = seg.count
+	if broken != nil {
+		report.Valid = false
+		report.FirstBroken = broken
+	}
+	return report, nil
+}
+
+// Prune archives the entries changed before cutoff into a signed checkpoint and
+// deletes them. The segment is verified first; a broken chain is never archived.
+func (s *Service) Prune(ctx context.Context, cutoff time.Time) (*Checkpoint, error) {
+	if len(s.checkpointKey) == 0 {
+		return nil, ErrNoCheckpointSecret
+	}
+
+	var checkpoint *Checkpoint
+	err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		if err := q.LockAuditChain(ctx); err != nil {
+			return err
+		}
+
+		lastID, err := q.GetAuditLogPruneBoundary(ctx, pgtype.Timestamptz{Time: cutoff, Valid: true})
+		if err != nil {
+			return err
+		}
+		if lastID <= 0 {
+			return ErrNothingToPrune
+		}
+
+		prev := pgtype.Text{}
+		checkpoints, err := q.ListAuditCheckpoints(ctx)
+		if err != nil {
+			return err
+		}
+		if len(checkpoints) > 0 {
+			prev = pgtype.Text{String: checkpoints[len(checkpoints)-1].LastHash, Valid: true}
+		}
+
+		seg, broken, err := walkChain(ctx, q, prev, lastID)
+		if err != nil {
+			return err
+		}
+		if broken != nil {
+			return fmt.Errorf("%w at %s %d: %s", ErrChainBroken, broken.Kind, broken.ID, broken.Reason)
+		}
+		if seg.count == 0 {
+			return ErrNothingToPrune
+		}
+
+		row, err := q.CreateAuditCheckpoint(ctx, db.CreateAuditCheckpointParams{
+			FirstID:    seg.firstID,
+			LastID:     seg.lastID,
+			EntryCount: int32(seg.count),
+			PrevHash:   prev,
+			LastHash:   seg.lastHash.String,
+			Signature:  s.sign(seg.firstID, seg.lastID, int32(seg.count), prev, seg.lastHash.String),
+		})
+		if err != nil {
+			return err
+		}
+
+		deleted, err := q.DeleteAuditLogsThrough(ctx, seg.lastID)
+		if err != nil {
+			return err
+		}
+		if deleted != int64(seg.count) {
+			return fmt.Errorf("archived %d audit log entries but deleted %d", seg.count, deleted)
+		}
+
+		checkpoint = &Checkpoint{
+			ID:         row.ID,
+			FirstID:    row.FirstID,
+			LastID:     row.LastID,
+			EntryCount: row.EntryCount,
+			PrevHash:   row.PrevHash,
+			LastHash:   row.LastHash,
+			CreatedAt:  row.CreatedAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// walkChain checks the entries up to throughID in ID order, the first of which
+// must link to prev. It stops at the first broken link.
+func walkChain(ctx context.Context, q *db.Queries, prev pgtype.Text, throughID int32) (segment, *BrokenLink, error) {
+	seg := segment{lastHash: prev}
+	afterID := int32(0)
+	for {
+		links, err := q.ListAuditChainLinks(ctx, db.ListAuditChainLinksParams{
+			AfterID:   afterID,
+			ThroughID: throughID,
+			BatchSize: chainBatchSize,
+		})
+		if err != nil {
+			return seg, nil, err
+		}
+
+		for _, link := range links {
+			switch {
+			case link.PrevHash != seg.lastHash:
+				return seg, &BrokenLink{Kind: LinkKindEntry, ID: link.ID, Reason: "prev_hash does not match the previous entry's row_hash"}, nil
+			case link.RowHash != link.ComputedHash:
+				return seg, &BrokenLink{Kind: LinkKindEntry, ID: link.ID, Reason: "row_hash does not match the entry's contents"}, nil
+			}
+			if seg.count == 0 {
+				seg.firstID = link.ID
+			}
+			seg.lastID = link.ID
+			seg.lastHash = pgtype.Text{String: link.RowHash, Valid: true}
+			seg.count++
+			afterID = link.ID
+		}
+
+		if len(links) < chainBatchSize {
+			return seg, nil, nil
+		}
+	}
+}
+
+// checkArchive re-hashes a checkpoint's archived entries and checks that they
+// chain from its prev_hash to its signed last_hash and match its ID range and
+// size. It returns why they do not, or "" if they do.
+func checkArchive(ctx context.Context, q *db.Queries, checkpoint db.ListAuditCheckpointsRow) (string, error) {
+	links, err := q.ListAuditCheckpointLinks(ctx, checkpoint.ID)
+	if err != nil {
+		return "", err
+	}
+
+	prev := checkpoint.PrevHash
+	for _, link := range links {
+		switch {
+		case link.PrevHash != prev:
+			return fmt.Sprintf("archived entry %d: prev_hash does not match the previous entry's row_hash", link.ID), nil
+		case link.RowHash != link.ComputedHash:
+			return fmt.Sprintf("archived entry %d: row_hash does not match the entry's contents", link.ID), nil
+		}
+		prev = pgtype.Text{String: link.RowHash, Valid: true}
+	}
+
+	switch {
+	case len(links) == 0 || len(links) != int(checkpoint.EntryCount):
+		return "archived entries do not match entry_count", nil
+	case links[0].ID != checkpoint.FirstID || links[len(links)-1].ID != checkpoint.LastID:
+		return "archived entries do not match first_id and last_id", nil
+	case prev.String != checkpoint.LastHash:
+		return "archived entries do not chain to last_hash", nil
+	}
+	return "", nil
+}
+
+// sign returns the HMAC-SHA256 of a checkpoint's boundaries. Because every entry
+// hash covers the one before it, last_hash commits to the whole archived segment,
+// which checkArchive re-hashes.
+func (s *Service) sign(firstID, lastID, entryCount int32, prevHash pgtype.Text, lastHash string) string {
+	mac := hmac.New(sha256.New, s.checkpointKey)
+	fmt.Fprintf(mac, "hkers-audit-checkpoint|%d|%d|%d|%s|%s", firstID, lastID, entryCount, prevHash.String, lastHash)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// Handler handles audit trail HTTP requests.
+type Handler struct {
+	auditService ServiceInterface
+}
+
+// NewHandler creates a new audit Handler instance.
+func NewHandler(auditService ServiceInterface) HandlerInterface {
+	return &Handler{
+		auditService: auditService,
+	}
+}
+
+// ListEntries returns a page of audit log entries, newest first. Every filter is
+// optional and they combine: table, record_id, action, changed_by, request_id,
+// and from/to (RFC 3339) bounding changed_at.
+// GET /api/v1/admin/audit
+func (h *Handler) ListEntries(ctx *gin.Context) {
+	filter, ok := parseFilter(ctx)
+	if !ok {
+		return
+	}
+	limit, offset, ok := response.ParsePagination(ctx, defaultPageLimit, maxPageLimit)
+	if !ok {
+		return
+	}
+
+	entries, total, err := h.auditService.ListEntries(ctx.Request.Context(), filter, limit, offset)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to list audit log entries")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"entries": entries,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// GetEntry returns a single audit log entry.
+// GET /api/v1/admin/audit/:id
+func (h *Handler) GetEntry(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid id")
+		return
+	}
+
+	entry, err := h.auditService.GetEntry(ctx.Request.Context(), int32(id))
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to get audit log entry")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, entry)
+}
+
+// VerifyChain walks the audit log hash chain and reports the first broken link, if any.
+// GET /api/v1/admin/audit/verify
+func (h *Handler) VerifyChain(ctx *gin.Context) {
+	report, err := h.auditService.VerifyChain(ctx.Request.Context())
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to verify audit log")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, report)
+}
+
+// parseFilter reads the audit filters from the query string, writing a 400 response if one is invalid.
+func parseFilter(ctx *gin.Context) (Filter, bool) {
+	filter := Filter{
+		Table:     ctx.Query("table"),
+		RecordID:  ctx.Query("record_id"),
+		Action:    strings.ToUpper(ctx.Query("action")),
+		RequestID: ctx.Query("request_id"),
+	}
+
+	if raw := ctx.Query("changed_by"); raw != "" {
+		userID, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || userID <= 0 {
+			response.Error(ctx, http.StatusBadRequest, "changed_by must be a user ID")
+			return Filter{}, false
+		}
+		changedBy := int32(userID)
+		filter.ChangedBy = &changedBy
+	}
+
+	var ok bool
+	if filter.From, ok = parseTime(ctx, "from"); !ok {
+		return Filter{}, false
+	}
+	if filter.To, ok = parseTime(ctx, "to"); !ok {
+		return Filter{}, false
+	}
+
+	return filter, true
+}
+
+// parseTime reads an optional RFC 3339 query parameter, writing a 400 response if it is invalid.
+func parseTime(ctx *gin.Context, name string) (*time.Time, bool) {
+	raw := ctx.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		response.Error(ctx, http.StatusBadRequest, name+" must be an RFC 3339 timestamp")
+		return nil, false
+	}
+	return &t, true
+}
+
+// writeServiceError maps service errors to HTTP responses.
+func writeServiceError(ctx *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrEntryNotFound):
+		response.Error(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrUnknownTable), errors.Is(err, ErrInvalidRange):
+		response.Error(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNoCheckpointSecret):
+		response.Error(ctx, http.StatusServiceUnavailable, "Audit checkpoints cannot be verified: "+err.Error())
+	default:
+		response.Error(ctx, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceInterface defines the interface for audit services
+type ServiceInterface interface {
+	ListEntries(ctx context.Context, filter Filter, limit, offset int32) ([]Entry, int64, error)
+	GetEntry(ctx context.Context, id int32) (*Entry, error)
+	VerifyChain(ctx context.Context) (*VerifyReport, error)
+	Prune(ctx context.Context, cutoff time.Time) (*Checkpoint, error)
+}
+
+// HandlerInterface defines the interface for audit HTTP handlers
+type HandlerInterface interface {
+	ListEntries(ctx *gin.Context)
+	GetEntry(ctx *gin.Context)
+	VerifyChain(ctx *gin.Context)
+}
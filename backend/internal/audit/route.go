@@ -0,0 +1,24 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// RegisterAuditRoutes registers the audit trail query routes on the given router.
+func RegisterAuditRoutes(router *gin.Engine, auditSvc ServiceInterface, jwtManager response.JWTManager) {
+	h := NewHandler(auditSvc)
+
+	// Admin audit routes - require JWT authentication
+	admin := router.Group("/api/v1/admin/audit")
+	admin.Use(middleware.JWTAuth(jwtManager))
+	admin.Use(middleware.RequirePermission(db.AppPermissionReadAuditLogs))
+	{
+		admin.GET("", h.ListEntries)
+		admin.GET("/verify", h.VerifyChain)
+		admin.GET("/:id", h.GetEntry)
+	}
+}
@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hkers-backend/internal/config"
+	"hkers-backend/internal/core/dbtx"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	ErrEntryNotFound = errors.New("audit log entry not found")
+	ErrUnknownTable  = errors.New("table is not audited")
+	ErrInvalidRange  = errors.New("from must not be after to")
+)
+
+// AuditedTables lists the tables whose changes the audit_changes trigger records.
+var AuditedTables = []string{
+	"roles",
+	"role_permissions",
+	"users",
+	"user_roles",
+	"supply_stations",
+	"supply_needs",
+	"donations",
+	"checkins",
+	"news",
+	"alerts",
+}
+
+// Filter narrows an audit log query. Zero values match every entry.
+type Filter struct {
+	Table     string
+	RecordID  string
+	Action    string
+	ChangedBy *int32
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+}
+
+// Entry is an audit log row with its row snapshots as JSON.
+type Entry struct {
+	ID        int32              `json:"id"`
+	Table     string             `json:"table"`
+	RecordID  pgtype.Text        `json:"record_id"`
+	Action    string             `json:"action"`
+	OldData   json.RawMessage    `json:"old_data"`
+	NewData   json.RawMessage    `json:"new_data"`
+	ChangedBy pgtype.Int4        `json:"changed_by"`
+	ChangedAt pgtype.Timestamptz `json:"changed_at"`
+	RequestID pgtype.Text        `json:"request_id"`
+	PrevHash  pgtype.Text        `json:"prev_hash"`
+	RowHash   string             `json:"row_hash"`
+}
+
+// Service queries, verifies and prunes the audit trail.
+type Service struct {
+	pool          *pgxpool.Pool
+	queries       *db.Queries
+	runner        *dbtx.Runner
+	checkpointKey []byte
+	retention     time.Duration
+}
+
+// NewService creates a new audit service instance. Checkpoints are signed with
+// cfg.CheckpointSecret; without it the chain can be verified only until the first prune.
+func NewService(pool *pgxpool.Pool, cfg *config.AuditConfig) *Service {
+	return &Service{
+		pool:          pool,
+		queries:       db.New(pool),
+		runner:        dbtx.New(pool),
+		checkpointKey: []byte(cfg.CheckpointSecret),
+		retention:     cfg.Retention,
+	}
+}
+
+// ListEntries returns a page of entries matching the filter, newest first,
+// together with the total number of matching entries.
+func (s *Service) ListEntries(ctx context.Context, filter Filter, limit, offset int32) ([]Entry, int64, error) {
+	if filter.Table != "" && !isAuditedTable(filter.Table) {
+		return nil, 0, ErrUnknownTable
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, 0, ErrInvalidRange
+	}
+
+	params := db.CountAuditLogsFilteredParams{
+		TableName:   optionalText(filter.Table),
+		RecordID:    optionalText(filter.RecordID),
+		Action:      optionalText(filter.Action),
+		RequestID:   optionalText(filter.RequestID),
+		ChangedFrom: optionalTimestamp(filter.From),
+		ChangedTo:   optionalTimestamp(filter.To),
+	}
+	if filter.ChangedBy != nil {
+		params.ChangedBy = pgtype.Int4{Int32: *filter.ChangedBy, Valid: true}
+	}
+
+	rows, err := s.queries.ListAuditLogsFiltered(ctx, db.ListAuditLogsFilteredParams{
+		TableName:   params.TableName,
+		RecordID:    params.RecordID,
+		Action:      params.Action,
+		ChangedBy:   params.ChangedBy,
+		RequestID:   params.RequestID,
+		ChangedFrom: params.ChangedFrom,
+		ChangedTo:   params.ChangedTo,
+		RowLimit:    limit,
+		RowOffset:   offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountAuditLogsFiltered(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, toEntry(row))
+	}
+	return entries, total, nil
+}
+
+// GetEntry returns a single audit log entry.
+func (s *Service) GetEntry(ctx context.Context, id int32) (*Entry, error) {
+	row, err := s.queries.GetAuditLogByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, err
+	}
+	entry := toEntry(row)
+	return &entry, nil
+}
+
+func toEntry(row db.AuditLog) Entry {
+	return Entry{
+		ID:        row.ID,
+		Table:     row.TableName,
+		RecordID:  row.RecordID,
+		Action:    row.Action,
+		OldData:   json.RawMessage(row.OldData),
+		NewData:   json.RawMessage(row.NewData),
+		ChangedBy: row.ChangedBy,
+		ChangedAt: row.ChangedAt,
+		RequestID: row.RequestID,
+		PrevHash:  row.PrevHash,
+		RowHash:   row.RowHash,
+	}
+}
+
+func isAuditedTable(table string) bool {
+	for _, audited := range AuditedTables {
+		if audited == table {
+			return true
+		}
+	}
+	return false
+}
+
+func optionalText(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
+func optionalTimestamp(value *time.Time) pgtype.Timestamptz {
+	if value == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *value, Valid: true}
+}
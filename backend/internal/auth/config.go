@@ -7,4 +7,4 @@ package auth
 // Example:
 //   cfg, _ := config.Load()
 //   jwtConfig := cfg.Auth.JWT
-//   oidcConfig := cfg.Auth.OIDC
+//   oidcProviders := cfg.Auth.OIDCProviders
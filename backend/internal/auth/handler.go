@@ -2,73 +2,86 @@ package auth
 
 import (
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 
 	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
 	db "hkers-backend/internal/sqlc/generated"
 	"hkers-backend/internal/user"
 )
 
 // Handler handles authentication-related HTTP requests.
 type Handler struct {
-	authService ServiceInterface
-	userService user.ServiceInterface
-	jwtManager  response.JWTManager
+	providers     ProviderRegistry
+	userService   user.ServiceInterface
+	jwtManager    response.JWTManager
+	refreshTokens RefreshTokenManagerInterface
+	jwks          JWKSProvider
 }
 
 // NewHandler creates a new auth Handler instance.
-func NewHandler(authService ServiceInterface, userService user.ServiceInterface, jwtManager response.JWTManager) HandlerInterface {
+func NewHandler(providers ProviderRegistry, userService user.ServiceInterface, jwtManager response.JWTManager, refreshTokens RefreshTokenManagerInterface, jwks JWKSProvider) HandlerInterface {
 	return &Handler{
-		authService: authService,
-		userService: userService,
-		jwtManager:  jwtManager,
+		providers:     providers,
+		userService:   userService,
+		jwtManager:    jwtManager,
+		refreshTokens: refreshTokens,
+		jwks:          jwks,
 	}
 }
 
-// Login initiates the OAuth2 login flow.
+// Providers lists the identity providers users can sign in with.
+// GET /auth/providers
+func (h *Handler) Providers(ctx *gin.Context) {
+	response.Success(ctx, http.StatusOK, gin.H{
+		"providers": h.providers.Names(),
+	})
+}
+
+// Login initiates the OAuth2 login flow with the named provider, or the default one.
 // GET /auth/login
+// GET /auth/login/:provider
 func (h *Handler) Login(ctx *gin.Context) {
-	if h.authService == nil {
-		response.Error(ctx, http.StatusServiceUnavailable, "OIDC authentication is not configured. Please configure OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET, and OIDC_REDIRECT_URL environment variables.")
+	provider, ok := h.resolveProvider(ctx, ctx.Param("provider"))
+	if !ok {
 		return
 	}
 
-	state, err := h.authService.GenerateState()
+	state, err := provider.GenerateState()
 	if err != nil {
 		response.Error(ctx, http.StatusInternalServerError, "Failed to generate state")
 		return
 	}
 
-	codeVerifier, codeChallenge, err := h.authService.GeneratePKCE()
+	codeVerifier, codeChallenge, err := provider.GeneratePKCE()
 	if err != nil {
 		response.Error(ctx, http.StatusInternalServerError, "Failed to generate PKCE verifier")
 		return
 	}
 
-	// Save state in session for CSRF protection
+	// Save state in session for CSRF protection, with the provider the callback must match
 	session := sessions.Default(ctx)
 	session.Set("state", state)
 	session.Set("code_verifier", codeVerifier)
+	session.Set("oidc_provider", provider.Name())
 	if err := session.Save(); err != nil {
 		response.Error(ctx, http.StatusInternalServerError, "Failed to save session")
 		return
 	}
 
 	// Redirect to OIDC authorization URL
-	ctx.Redirect(http.StatusTemporaryRedirect, h.authService.GetAuthURLWithPKCE(state, codeChallenge))
+	ctx.Redirect(http.StatusTemporaryRedirect, provider.GetAuthURLWithPKCE(state, codeChallenge))
 }
 
-// Callback handles the OAuth2 callback from the OIDC provider.
+// Callback handles the OAuth2 callback from the provider the login was started with.
 // GET /auth/callback
+// GET /auth/callback/:provider
 func (h *Handler) Callback(ctx *gin.Context) {
-	if h.authService == nil {
-		response.Error(ctx, http.StatusServiceUnavailable, "OIDC authentication is not configured. Please configure OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET, and OIDC_REDIRECT_URL environment variables.")
-		return
-	}
-
 	session := sessions.Default(ctx)
 
 	// Verify state parameter to prevent CSRF
@@ -77,6 +90,16 @@ func (h *Handler) Callback(ctx *gin.Context) {
 		return
 	}
 
+	providerName, _ := session.Get("oidc_provider").(string)
+	if name := ctx.Param("provider"); name != "" && name != providerName {
+		response.Error(ctx, http.StatusBadRequest, "Login was started with a different provider")
+		return
+	}
+	provider, ok := h.resolveProvider(ctx, providerName)
+	if !ok {
+		return
+	}
+
 	verifier, ok := session.Get("code_verifier").(string)
 	if !ok || verifier == "" {
 		response.Error(ctx, http.StatusBadRequest, "Missing PKCE verifier")
@@ -84,39 +107,36 @@ func (h *Handler) Callback(ctx *gin.Context) {
 	}
 
 	// Exchange authorization code for tokens
-	token, err := h.authService.ExchangeCodeWithPKCE(ctx.Request.Context(), ctx.Query("code"), verifier)
+	token, err := provider.ExchangeCodeWithPKCE(ctx.Request.Context(), ctx.Query("code"), verifier)
 	if err != nil {
 		response.Error(ctx, http.StatusUnauthorized, "Failed to exchange authorization code")
 		return
 	}
 
 	// Verify the ID token
-	idToken, _, verifyErr := h.authService.VerifyIDToken(ctx.Request.Context(), token)
+	idToken, _, verifyErr := provider.VerifyIDToken(ctx.Request.Context(), token)
 	if verifyErr != nil {
 		response.Error(ctx, http.StatusInternalServerError, "Failed to verify ID token")
 		return
 	}
 
-	// Extract user profile from claims
-	profile, profileErr := h.authService.ExtractClaims(idToken)
-	if profileErr != nil {
+	// Map the provider's claims to a user identity, with the subject namespaced by issuer
+	identity, identityErr := provider.ExtractIdentity(idToken)
+	if identityErr != nil {
 		response.Error(ctx, http.StatusInternalServerError, "Failed to extract claims")
 		return
 	}
 
-	// Get subject identifier (unique user ID from the OIDC provider)
-	oidcSub, ok := profile["sub"].(string)
-	if !ok || oidcSub == "" {
-		response.Error(ctx, http.StatusInternalServerError, "Invalid OIDC token: missing sub claim")
-		return
-	}
-
 	// Check if user is allowed to login (must exist in database and be active)
 	var dbUser *db.User
 	if h.userService != nil {
 		var validateErr error
-		dbUser, validateErr = h.userService.ValidateOIDCLogin(ctx.Request.Context(), oidcSub)
+		dbUser, validateErr = h.userService.ValidateOIDCLogin(ctx.Request.Context(), identity.Subject)
 		if validateErr != nil {
+			if errors.Is(validateErr, user.ErrUserRejected) {
+				response.Error(ctx, http.StatusForbidden, "Your account registration was not approved. Please contact an administrator.")
+				return
+			}
 			if errors.Is(validateErr, user.ErrUserNotActive) {
 				// User exists but is not activated - pending approval
 				response.Error(ctx, http.StatusForbidden, "Your account is pending approval. Please contact an administrator.")
@@ -125,16 +145,11 @@ func (h *Handler) Callback(ctx *gin.Context) {
 			if errors.Is(validateErr, user.ErrUserNotAllowed) {
 				// User doesn't exist in our system
 				// Option 1: Auto-create as inactive (requires admin approval)
-				email, _ := profile["email"].(string)
-				nickname, _ := profile["nickname"].(string)
-				if nickname == "" {
-					nickname, _ = profile["name"].(string)
-				}
-				if nickname == "" {
-					nickname = oidcSub // fallback to sub as username
+				_, isNew, createErr := h.userService.GetOrCreateOIDCUser(ctx.Request.Context(), identity.Subject, identity.Username, identity.Email)
+				if errors.Is(createErr, user.ErrUsernameTaken) {
+					response.Error(ctx, http.StatusConflict, "Could not choose a unique username for your account. Please contact an administrator.")
+					return
 				}
-
-				_, isNew, createErr := h.userService.GetOrCreateOIDCUser(ctx.Request.Context(), oidcSub, nickname, email)
 				if createErr != nil {
 					response.Error(ctx, http.StatusInternalServerError, "Failed to register user")
 					return
@@ -158,6 +173,7 @@ func (h *Handler) Callback(ctx *gin.Context) {
 
 	// Generate JWT token for the authenticated user
 	jwtToken, jwtErr := h.jwtManager.GenerateToken(
+		ctx.Request.Context(),
 		dbUser.ID,
 		dbUser.Email.String,
 		dbUser.OidcSub,
@@ -169,39 +185,69 @@ func (h *Handler) Callback(ctx *gin.Context) {
 		return
 	}
 
-	// Clear temporary OIDC session data (state, verifier)
+	// Start a refresh token family for this login
+	refreshToken, refreshErr := h.refreshTokens.Issue(ctx.Request.Context(), dbUser.ID)
+	if refreshErr != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to generate refresh token")
+		return
+	}
+
+	// Clear temporary OIDC session data (state, verifier, provider)
 	session.Delete("state")
 	session.Delete("code_verifier")
+	session.Delete("oidc_provider")
 	if saveErr := session.Save(); saveErr != nil {
 		response.Error(ctx, http.StatusInternalServerError, "Failed to clear session")
 		return
 	}
 
-	// Return JWT token and user info in response
-	response.Success(ctx, http.StatusOK, gin.H{
-		"access_token": jwtToken,
-		"token_type":   "Bearer",
-		"expires_in":   86400 * 7, // 7 days in seconds
-		"user": gin.H{
-			"id":           dbUser.ID,
-			"email":        dbUser.Email.String,
-			"username":     dbUser.Username,
-			"oidc_sub":     dbUser.OidcSub,
-			"is_active":    dbUser.IsActive,
-			"trust_points": dbUser.TrustPoints,
-			"created_at":   dbUser.CreatedAt,
-		},
-	})
+	// Return token pair and user info in response
+	body := h.tokenResponse(jwtToken, refreshToken)
+	body["user"] = gin.H{
+		"id":           dbUser.ID,
+		"email":        dbUser.Email.String,
+		"username":     dbUser.Username,
+		"oidc_sub":     dbUser.OidcSub,
+		"is_active":    dbUser.IsActive,
+		"trust_points": dbUser.TrustPoints,
+		"created_at":   dbUser.CreatedAt,
+	}
+	response.Success(ctx, http.StatusOK, body)
 }
 
-// Logout handles user logout.
+// Logout revokes the presented access token server-side, along with the
+// refresh token family of the session if its refresh token is supplied.
+// Refresh tokens belonging to another user are ignored.
 // POST /auth/logout
+// Note: JWT middleware has already validated the token and stored its claims
 func (h *Handler) Logout(ctx *gin.Context) {
-	// For JWT-based auth, logout is handled client-side (delete token)
-	// But we can still provide OIDC provider logout URL if needed
+	claims, ok := middleware.GetClaimsFromContext(ctx)
+	if !ok {
+		response.Error(ctx, http.StatusUnauthorized, "Missing token claims")
+		return
+	}
+
+	// The body is optional; clients that only hold an access token send none
+	var req RefreshRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
 
-	// If OIDC is not configured, just return success
-	if h.authService == nil {
+	if err := h.jwtManager.RevokeToken(ctx.Request.Context(), claims); err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to revoke token")
+		return
+	}
+	if req.RefreshToken != "" {
+		if err := h.refreshTokens.Revoke(ctx.Request.Context(), claims.UserID, req.RefreshToken); err != nil {
+			response.Error(ctx, http.StatusInternalServerError, "Failed to revoke refresh token")
+			return
+		}
+	}
+
+	// If the user's provider is no longer configured, just return success
+	provider, ok := h.providers.ForSubject(claims.OIDCSub)
+	if !ok {
 		response.Success(ctx, http.StatusOK, gin.H{
 			"message": "Logged out successfully",
 		})
@@ -209,7 +255,7 @@ func (h *Handler) Logout(ctx *gin.Context) {
 	}
 
 	// Build return URL (prefer configured post-logout redirect)
-	returnToURL := h.authService.PostLogoutRedirect()
+	returnToURL := provider.PostLogoutRedirect()
 	if returnToURL == "" {
 		scheme := "http"
 		if ctx.Request.TLS != nil {
@@ -220,7 +266,7 @@ func (h *Handler) Logout(ctx *gin.Context) {
 
 	// Get provider end-session URL (if configured)
 	// Note: We can't get id_token from session anymore, so OIDC logout might be limited
-	logoutURL, ok, err := h.authService.GetEndSessionURL(returnToURL, "")
+	logoutURL, ok, err := provider.GetEndSessionURL(returnToURL, "")
 	if err != nil {
 		response.Error(ctx, http.StatusInternalServerError, "Failed to build logout URL")
 		return
@@ -238,35 +284,93 @@ func (h *Handler) Logout(ctx *gin.Context) {
 	}
 }
 
-// RefreshToken handles JWT token refresh.
+// RefreshRequest is the request body for refreshing tokens.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new refresh token.
+// The presented refresh token is single-use; presenting it again revokes the whole session.
 // POST /auth/refresh
 func (h *Handler) RefreshToken(ctx *gin.Context) {
-	// Get token from Authorization header
-	authHeader := ctx.GetHeader("Authorization")
-	if authHeader == "" {
-		response.Error(ctx, http.StatusUnauthorized, "Authorization header required")
+	var req RefreshRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		response.Error(ctx, http.StatusBadRequest, "refresh_token is required")
 		return
 	}
 
-	// Extract token from "Bearer <token>" format
-	const bearerPrefix = "Bearer "
-	if len(authHeader) < len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
-		response.Error(ctx, http.StatusUnauthorized, "Invalid authorization header format")
+	refreshToken, err := h.refreshTokens.Rotate(ctx.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+			response.Error(ctx, http.StatusUnauthorized, "Invalid or expired refresh token")
+			return
+		}
+		response.Error(ctx, http.StatusInternalServerError, "Failed to refresh token")
 		return
 	}
-	oldToken := authHeader[len(bearerPrefix):]
 
-	// Refresh the token
-	newToken, err := h.jwtManager.RefreshToken(oldToken)
+	// Re-check the account so deactivated users cannot keep refreshing
+	dbUser, err := h.userService.GetUserByID(ctx.Request.Context(), refreshToken.UserID)
+	if err != nil || !dbUser.IsActive.Bool {
+		if revokeErr := h.refreshTokens.Revoke(ctx.Request.Context(), refreshToken.UserID, refreshToken.Token); revokeErr != nil {
+			response.Error(ctx, http.StatusInternalServerError, "Failed to revoke refresh token")
+			return
+		}
+		response.Error(ctx, http.StatusUnauthorized, "User account is not active")
+		return
+	}
+
+	accessToken, err := h.jwtManager.GenerateToken(
+		ctx.Request.Context(),
+		dbUser.ID,
+		dbUser.Email.String,
+		dbUser.OidcSub,
+		dbUser.Username,
+		dbUser.IsActive.Bool,
+	)
 	if err != nil {
-		response.Error(ctx, http.StatusUnauthorized, "Failed to refresh token")
+		response.Error(ctx, http.StatusInternalServerError, "Failed to generate access token")
 		return
 	}
 
-	// Return new token
-	response.Success(ctx, http.StatusOK, gin.H{
-		"access_token": newToken,
-		"token_type":   "Bearer",
-		"expires_in":   86400 * 7, // 7 days in seconds
-	})
+	response.Success(ctx, http.StatusOK, h.tokenResponse(accessToken, refreshToken))
+}
+
+// JWKS publishes the public keys that verify access tokens, so partner services
+// can validate tokens without being able to mint them. Empty when signing with HS256.
+// GET /.well-known/jwks.json
+func (h *Handler) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.jwks.JWKS())
+}
+
+// resolveProvider looks up the named provider, or the default one when name is empty.
+// It writes the error response and returns false if no such provider is configured.
+func (h *Handler) resolveProvider(ctx *gin.Context, name string) (ServiceInterface, bool) {
+	var provider ServiceInterface
+	var ok bool
+	if name == "" {
+		provider, ok = h.providers.Default()
+		if !ok {
+			response.Error(ctx, http.StatusServiceUnavailable, "OIDC authentication is not configured. Please configure OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET, and OIDC_REDIRECT_URL environment variables, or OIDC_PROVIDERS.")
+		}
+		return provider, ok
+	}
+
+	provider, ok = h.providers.Get(name)
+	if !ok {
+		response.Error(ctx, http.StatusNotFound, "Unknown identity provider")
+	}
+	return provider, ok
+}
+
+// tokenResponse builds the token pair payload, with lifetimes taken from the configured durations.
+func (h *Handler) tokenResponse(accessToken string, refreshToken *RefreshToken) gin.H {
+	return gin.H{
+		"access_token":       accessToken,
+		"token_type":         "Bearer",
+		"expires_in":         int64(h.jwtManager.TokenDuration().Seconds()),
+		"refresh_token":      refreshToken.Token,
+		"refresh_expires_in": int64(time.Until(refreshToken.ExpiresAt).Seconds()),
+	}
 }
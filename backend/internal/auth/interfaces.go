@@ -10,20 +10,43 @@ import (
 
 // ServiceInterface defines the interface for authentication services
 type ServiceInterface interface {
+	Name() string
 	GenerateState() (string, error)
 	GeneratePKCE() (string, string, error)
 	GetAuthURLWithPKCE(state, codeChallenge string) string
 	ExchangeCodeWithPKCE(ctx context.Context, code, verifier string) (*oauth2.Token, error)
 	VerifyIDToken(ctx context.Context, token *oauth2.Token) (*oidc.IDToken, string, error)
-	ExtractClaims(token *oidc.IDToken) (map[string]interface{}, error)
+	ExtractIdentity(token *oidc.IDToken) (*Identity, error)
 	PostLogoutRedirect() string
 	GetEndSessionURL(returnTo, idToken string) (string, bool, error)
 }
 
+// ProviderRegistry looks up the configured OIDC providers
+type ProviderRegistry interface {
+	Get(name string) (ServiceInterface, bool)
+	Default() (ServiceInterface, bool)
+	ForSubject(oidcSub string) (ServiceInterface, bool)
+	Names() []string
+}
+
+// RefreshTokenManagerInterface defines the interface for refresh token issuance and rotation
+type RefreshTokenManagerInterface interface {
+	Issue(ctx context.Context, userID int32) (*RefreshToken, error)
+	Rotate(ctx context.Context, token string) (*RefreshToken, error)
+	Revoke(ctx context.Context, userID int32, token string) error
+}
+
+// JWKSProvider exposes the public keys that verify access tokens
+type JWKSProvider interface {
+	JWKS() JWKS
+}
+
 // HandlerInterface defines the interface for authentication HTTP handlers
 type HandlerInterface interface {
+	Providers(ctx *gin.Context)
 	Login(ctx *gin.Context)
 	Callback(ctx *gin.Context)
 	Logout(ctx *gin.Context)
 	RefreshToken(ctx *gin.Context)
+	JWKS(ctx *gin.Context)
 }
@@ -1,11 +1,17 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
 
 	"hkers-backend/internal/core/response"
 )
@@ -15,58 +21,126 @@ var (
 	ErrInvalidToken = errors.New("invalid token")
 	// ErrExpiredToken is returned when the JWT token has expired
 	ErrExpiredToken = errors.New("token has expired")
+	// ErrRevokedToken is returned when the JWT token has been revoked
+	ErrRevokedToken = errors.New("token has been revoked")
+)
+
+// Redis key prefixes for token revocation state.
+const (
+	// revokedTokenKeyPrefix + jti marks a single token as revoked until it can no longer be used.
+	revokedTokenKeyPrefix = "auth:revoked:"
+	// tokenGenerationKeyPrefix + user ID holds the user's current token generation.
+	tokenGenerationKeyPrefix = "auth:token_gen:"
 )
 
 // JWTClaims is an alias to the core JWTClaims for backward compatibility
 type JWTClaims = response.JWTClaims
 
-// JWTManager handles JWT token generation and validation
+// JWTManager handles JWT token generation, validation and revocation.
+// Tokens are signed with the key set's active key and verified against any key
+// in the set. Revocation state lives in Redis; with a nil client tokens cannot be revoked.
 type JWTManager struct {
-	secretKey     string
+	keys          *KeySet
 	tokenDuration time.Duration
+	redis         *redis.Client
 }
 
 // NewJWTManager creates a new JWT manager
-func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
+func NewJWTManager(keys *KeySet, tokenDuration time.Duration, redisClient *redis.Client) *JWTManager {
 	return &JWTManager{
-		secretKey:     secretKey,
+		keys:          keys,
 		tokenDuration: tokenDuration,
+		redis:         redisClient,
 	}
 }
 
-// GenerateToken creates a new JWT token for a user
-func (m *JWTManager) GenerateToken(userID int32, email, oidcSub, username string, isActive bool) (string, error) {
+// GenerateToken creates a new JWT token for a user, stamped with a unique ID
+// and the user's current token generation.
+func (m *JWTManager) GenerateToken(ctx context.Context, userID int32, email, oidcSub, username string, isActive bool) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	generation, err := m.currentGeneration(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := JWTClaims{
-		UserID:   userID,
-		Email:    email,
-		OIDCSub:  oidcSub,
-		Username: username,
-		IsActive: isActive,
+		UserID:     userID,
+		Email:      email,
+		OIDCSub:    oidcSub,
+		Username:   username,
+		IsActive:   isActive,
+		Generation: generation,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenDuration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.tokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.secretKey))
+	return m.keys.sign(claims)
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func (m *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(
-		tokenString,
-		&JWTClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			// Verify signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(m.secretKey), nil
-		},
-	)
+// ValidateToken validates a JWT token and returns the claims.
+// Revoked tokens, and tokens issued before the user's current generation, are rejected.
+func (m *JWTManager) ValidateToken(ctx context.Context, tokenString string) (*JWTClaims, error) {
+	claims, err := m.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user account is still active
+	if !claims.IsActive {
+		return nil, errors.New("user account is not active")
+	}
+
+	if err := m.checkRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// JWKS returns the public verification keys, for partner services that verify tokens themselves.
+func (m *JWTManager) JWKS() JWKS {
+	return m.keys.JWKS()
+}
+
+// TokenDuration returns the lifetime of issued access tokens.
+func (m *JWTManager) TokenDuration() time.Duration {
+	return m.tokenDuration
+}
+
+// RevokeToken denylists a single token until it expires.
+func (m *JWTManager) RevokeToken(ctx context.Context, claims *JWTClaims) error {
+	if m.redis == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
 
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return m.redis.Set(ctx, revokedTokenKeyPrefix+claims.ID, "1", ttl).Err()
+}
+
+// RevokeUserTokens revokes every token issued to the user so far by bumping
+// their token generation.
+func (m *JWTManager) RevokeUserTokens(ctx context.Context, userID int32) error {
+	if m.redis == nil {
+		return nil
+	}
+	return m.redis.Incr(ctx, tokenGenerationKey(userID)).Err()
+}
+
+// parseToken verifies a token's signature and standard claims.
+func (m *JWTManager) parseToken(tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, m.keys.keyFunc)
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrExpiredToken
@@ -78,39 +152,66 @@ func (m *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
 	if !ok || !token.Valid {
 		return nil, ErrInvalidToken
 	}
-
-	// Check if user account is still active
-	if !claims.IsActive {
-		return nil, errors.New("user account is not active")
-	}
-
 	return claims, nil
 }
 
-// RefreshToken generates a new token with extended expiration
-func (m *JWTManager) RefreshToken(tokenString string) (string, error) {
-	// First validate the existing token
-	claims, err := m.ValidateToken(tokenString)
+// checkRevoked rejects tokens that are denylisted or predate the user's
+// current generation. Both keys are read in a single round trip. Tokens
+// without an ID predate revocation support and are rejected outright.
+// Redis is the only record of revocations, so if it cannot be read the check
+// fails closed with response.ErrAuthUnavailable rather than accepting tokens
+// that may have been revoked.
+func (m *JWTManager) checkRevoked(ctx context.Context, claims *JWTClaims) error {
+	if m.redis == nil {
+		return nil
+	}
+	if claims.ID == "" {
+		return ErrRevokedToken
+	}
+
+	values, err := m.redis.MGet(ctx, revokedTokenKeyPrefix+claims.ID, tokenGenerationKey(claims.UserID)).Result()
 	if err != nil {
-		// Allow refresh even if expired, but not if invalid
-		if !errors.Is(err, ErrExpiredToken) {
-			return "", err
+		log.Printf("auth: failed to check token revocation: %v", err)
+		return fmt.Errorf("%w: %v", response.ErrAuthUnavailable, err)
+	}
+
+	if values[0] != nil {
+		return ErrRevokedToken
+	}
+	if raw, ok := values[1].(string); ok {
+		generation, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
 		}
-		// For expired tokens, parse without validation to get claims
-		token, parseErr := jwt.ParseWithClaims(
-			tokenString,
-			&JWTClaims{},
-			func(token *jwt.Token) (interface{}, error) {
-				return []byte(m.secretKey), nil
-			},
-			jwt.WithoutClaimsValidation(),
-		)
-		if parseErr != nil {
-			return "", parseErr
+		if claims.Generation < generation {
+			return ErrRevokedToken
 		}
-		claims, _ = token.Claims.(*JWTClaims)
+	}
+	return nil
+}
+
+// currentGeneration returns the user's token generation (0 if never bumped).
+func (m *JWTManager) currentGeneration(ctx context.Context, userID int32) (int64, error) {
+	if m.redis == nil {
+		return 0, nil
 	}
 
-	// Generate new token with same user data but new expiration
-	return m.GenerateToken(claims.UserID, claims.Email, claims.OIDCSub, claims.Username, claims.IsActive)
+	generation, err := m.redis.Get(ctx, tokenGenerationKey(userID)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+	return generation, nil
+}
+
+func tokenGenerationKey(userID int32) string {
+	return tokenGenerationKeyPrefix + strconv.FormatInt(int64(userID), 10)
+}
+
+// newTokenID returns a random 128-bit token identifier for the jti claim.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"hkers-backend/internal/config"
+)
+
+// Supported values of JWT_ALGORITHM.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// ErrUnknownKey is returned when a token names a key that is not in the key set
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// signingKey is a key in the key set. HMAC keys hold the shared secret in both
+// fields; asymmetric keys hold the private key (active key only) and its public key.
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// KeySet holds the active signing key and every key accepted for verification,
+// indexed by kid. Retired keys stay in the set for a grace period after rotation
+// so tokens they signed remain valid until they expire.
+type KeySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMACKeySet creates a key set that signs and verifies with a shared secret.
+func NewHMACKeySet(kid, secret string) (*KeySet, error) {
+	if secret == "" {
+		return nil, errors.New("JWT_SECRET is required for HS256")
+	}
+
+	key := &signingKey{
+		id:        kid,
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+	return &KeySet{active: key, keys: map[string]*signingKey{kid: key}}, nil
+}
+
+// LoadKeySet builds the key set described by the JWT configuration.
+func LoadKeySet(cfg *config.JWTConfig) (*KeySet, error) {
+	var method jwt.SigningMethod
+	switch strings.ToUpper(cfg.Algorithm) {
+	case "", strings.ToUpper(AlgorithmHS256):
+		if len(cfg.VerificationKeys) > 0 {
+			return nil, errors.New("JWT_VERIFICATION_KEYS requires an asymmetric JWT_ALGORITHM")
+		}
+		return NewHMACKeySet(cfg.SigningKeyID, cfg.Secret)
+	case strings.ToUpper(AlgorithmRS256):
+		method = jwt.SigningMethodRS256
+	case strings.ToUpper(AlgorithmEdDSA):
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q", cfg.Algorithm)
+	}
+
+	if cfg.SigningKeyID == "" {
+		return nil, errors.New("JWT_SIGNING_KEY_ID is required for asymmetric signing")
+	}
+
+	pemData := []byte(cfg.SigningKey)
+	if len(pemData) == 0 {
+		if cfg.SigningKeyFile == "" {
+			return nil, errors.New("JWT_SIGNING_KEY or JWT_SIGNING_KEY_FILE is required for asymmetric signing")
+		}
+		var err error
+		pemData, err = os.ReadFile(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read signing key: %w", err)
+		}
+	}
+
+	private, public, err := parsePEMKey(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key: %w", err)
+	}
+	if private == nil {
+		return nil, errors.New("signing key must be a private key")
+	}
+	if keyMethod(public) != method {
+		return nil, fmt.Errorf("signing key does not match JWT_ALGORITHM %s", cfg.Algorithm)
+	}
+
+	active := &signingKey{id: cfg.SigningKeyID, method: method, signKey: private, verifyKey: public}
+	keys := map[string]*signingKey{active.id: active}
+
+	for _, file := range cfg.VerificationKeys {
+		if _, exists := keys[file.ID]; exists || file.ID == "" {
+			return nil, fmt.Errorf("verification key %q: kid must be non-empty and unique", file.ID)
+		}
+
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("read verification key %q: %w", file.ID, err)
+		}
+		_, public, err := parsePEMKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse verification key %q: %w", file.ID, err)
+		}
+		keys[file.ID] = &signingKey{id: file.ID, method: keyMethod(public), verifyKey: public}
+	}
+
+	return &KeySet{active: active, keys: keys}, nil
+}
+
+// JWKS returns the public keys in the set. HMAC secrets are never published,
+// so an HS256 key set has an empty JWKS.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range s.keys {
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "RSA",
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Kid: key.id,
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "OKP",
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Kid: key.id,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
+
+// sign signs claims with the active key, naming it in the kid header.
+func (s *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.method, claims)
+	if s.active.id != "" {
+		token.Header["kid"] = s.active.id
+	}
+	return token.SignedString(s.active.signKey)
+}
+
+// keyFunc selects the verification key named by the token's kid header.
+// Tokens without a kid are checked against the active key. The token's alg
+// must match the key's, so a public key can never be used as an HMAC secret.
+func (s *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := s.active
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		key, ok = s.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// parsePEMKey parses an RSA or Ed25519 key in PKCS#8, PKCS#1 or PKIX form.
+// For private keys both halves are returned; for public keys private is nil.
+func parsePEMKey(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM block found")
+	}
+
+	if private, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := private.(crypto.Signer)
+		if !ok || keyMethod(signer.Public()) == nil {
+			return nil, nil, errors.New("key must be RSA or Ed25519")
+		}
+		return signer, signer.Public(), nil
+	}
+	if private, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return private, private.Public(), nil
+	}
+	if public, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		if keyMethod(public) == nil {
+			return nil, nil, errors.New("key must be RSA or Ed25519")
+		}
+		return nil, public, nil
+	}
+	if public, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return nil, public, nil
+	}
+	return nil, nil, errors.New("unsupported key format")
+}
+
+// keyMethod returns the signing method for a public key, or nil if unsupported.
+func keyMethod(public crypto.PublicKey) jwt.SigningMethod {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA
+	default:
+		return nil
+	}
+}
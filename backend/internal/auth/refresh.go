@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hkers-backend/internal/core/dbtx"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or revoked
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+	// errConcurrentRotation aborts a rotation whose token was used by another request meanwhile
+	errConcurrentRotation = errors.New("refresh token rotated concurrently")
+)
+
+// RefreshToken is a freshly issued opaque refresh token. Token is only ever
+// available at issue time; the database keeps its hash.
+type RefreshToken struct {
+	Token     string
+	UserID    int32
+	ExpiresAt time.Time
+}
+
+// RefreshTokenManager issues and rotates opaque refresh tokens stored in Postgres.
+// Each login starts a token family. Rotating a token marks it used and issues the
+// next token in the family; presenting a used token again revokes the whole family,
+// since either the legitimate client or an attacker is holding a stolen copy.
+type RefreshTokenManager struct {
+	queries       *db.Queries
+	runner        *dbtx.Runner
+	jwtManager    *JWTManager
+	tokenDuration time.Duration
+}
+
+// NewRefreshTokenManager creates a new refresh token manager. Refresh tokens issued
+// before the user's current JWT generation are rejected, so revoking a user's access
+// tokens also revokes their refresh tokens.
+func NewRefreshTokenManager(pool *pgxpool.Pool, jwtManager *JWTManager, tokenDuration time.Duration) *RefreshTokenManager {
+	return &RefreshTokenManager{
+		queries:       db.New(pool),
+		runner:        dbtx.New(pool),
+		jwtManager:    jwtManager,
+		tokenDuration: tokenDuration,
+	}
+}
+
+// TokenDuration returns the lifetime of issued refresh tokens.
+func (m *RefreshTokenManager) TokenDuration() time.Duration {
+	return m.tokenDuration
+}
+
+// Issue starts a new token family for the user and returns its first token.
+func (m *RefreshTokenManager) Issue(ctx context.Context, userID int32) (*RefreshToken, error) {
+	familyID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	return dbtx.Write(ctx, m.runner, func(q *db.Queries) (*RefreshToken, error) {
+		return m.issue(ctx, q, userID, familyID)
+	})
+}
+
+// Rotate exchanges a refresh token for the next one in its family.
+// The presented token can never be used again.
+func (m *RefreshTokenManager) Rotate(ctx context.Context, token string) (*RefreshToken, error) {
+	stored, err := m.queries.GetRefreshTokenByHash(ctx, hashRefreshToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt.Valid {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt.Valid {
+		return nil, m.revokeReusedFamily(ctx, stored)
+	}
+	if !stored.ExpiresAt.Valid || time.Now().After(stored.ExpiresAt.Time) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	generation, err := m.jwtManager.currentGeneration(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Generation < generation {
+		if err := m.revokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	next, err := dbtx.Write(ctx, m.runner, func(q *db.Queries) (*RefreshToken, error) {
+		marked, err := q.MarkRefreshTokenUsed(ctx, stored.ID)
+		if err != nil {
+			return nil, err
+		}
+		if marked == 0 {
+			return nil, errConcurrentRotation
+		}
+		return m.issue(ctx, q, stored.UserID, stored.FamilyID)
+	})
+	if errors.Is(err, errConcurrentRotation) {
+		// A concurrent request rotated or revoked the token first
+		return nil, m.revokeReusedFamily(ctx, stored)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// Revoke revokes the family of the given refresh token, ending that login session.
+// Unknown tokens, and tokens belonging to a user other than userID, are ignored,
+// so a caller cannot end someone else's session with a token it obtained.
+func (m *RefreshTokenManager) Revoke(ctx context.Context, userID int32, token string) error {
+	stored, err := m.queries.GetRefreshTokenByHash(ctx, hashRefreshToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if stored.UserID != userID {
+		return nil
+	}
+	return m.revokeFamily(ctx, stored.FamilyID)
+}
+
+// issue stores a new token in the given family, stamped with the user's current generation.
+func (m *RefreshTokenManager) issue(ctx context.Context, q *db.Queries, userID int32, familyID string) (*RefreshToken, error) {
+	token, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	generation, err := m.jwtManager.currentGeneration(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(m.tokenDuration)
+	if _, err := q.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		UserID:     userID,
+		FamilyID:   familyID,
+		TokenHash:  hashRefreshToken(token),
+		Generation: generation,
+		ExpiresAt:  pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	}); err != nil {
+		return nil, err
+	}
+
+	return &RefreshToken{Token: token, UserID: userID, ExpiresAt: expiresAt}, nil
+}
+
+// revokeReusedFamily revokes a family after one of its rotated tokens was presented again.
+func (m *RefreshTokenManager) revokeReusedFamily(ctx context.Context, stored db.RefreshToken) error {
+	log.Printf("auth: refresh token reuse detected for user %d, revoking family %s", stored.UserID, stored.FamilyID)
+	if err := m.revokeFamily(ctx, stored.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func (m *RefreshTokenManager) revokeFamily(ctx context.Context, familyID string) error {
+	return m.runner.InTx(ctx, func(q *db.Queries) error {
+		return q.RevokeRefreshTokenFamily(ctx, familyID)
+	})
+}
+
+// newRefreshToken returns a random 256-bit token, URL-safe base64 encoded.
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
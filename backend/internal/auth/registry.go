@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"hkers-backend/internal/config"
+)
+
+// Registry holds the configured OIDC providers, keyed by name.
+// The first configured provider is the default used by /auth/login.
+type Registry struct {
+	providers map[string]*Service
+	names     []string
+}
+
+// NewRegistry initializes a service for every configured provider.
+// With no providers configured the registry is empty and logins are disabled.
+func NewRegistry(cfgs []config.OIDCConfig) (*Registry, error) {
+	registry := &Registry{providers: make(map[string]*Service, len(cfgs))}
+
+	for i := range cfgs {
+		cfg := &cfgs[i]
+		if err := registry.checkUnique(cfg.Name, cfg.Issuer); err != nil {
+			return nil, err
+		}
+
+		log.Printf("Initializing OIDC provider %s with issuer: %s", cfg.Name, cfg.Issuer)
+		service, err := NewService(cfg)
+		if err != nil {
+			return nil, err
+		}
+		registry.add(service)
+	}
+	return registry, nil
+}
+
+// Register adds an already initialized provider, such as the development provider.
+func (r *Registry) Register(service *Service) error {
+	if err := r.checkUnique(service.Name(), service.Issuer()); err != nil {
+		return err
+	}
+	r.add(service)
+	return nil
+}
+
+// Get returns the provider with the given name.
+func (r *Registry) Get(name string) (ServiceInterface, bool) {
+	service, ok := r.providers[name]
+	if !ok {
+		return nil, false
+	}
+	return service, true
+}
+
+// Default returns the first configured provider.
+func (r *Registry) Default() (ServiceInterface, bool) {
+	if len(r.names) == 0 {
+		return nil, false
+	}
+	return r.Get(r.names[0])
+}
+
+// ForSubject returns the provider that issued a namespaced users.oidc_sub value.
+func (r *Registry) ForSubject(oidcSub string) (ServiceInterface, bool) {
+	for _, name := range r.names {
+		service := r.providers[name]
+		if strings.HasPrefix(oidcSub, NamespacedSubject(service.Issuer(), "")) {
+			return service, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the provider names in configuration order.
+func (r *Registry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// checkUnique rejects a provider whose name or issuer is already registered,
+// since either would make logins or namespaced subjects ambiguous.
+func (r *Registry) checkUnique(name, issuer string) error {
+	if _, exists := r.providers[name]; exists || name == "" {
+		return fmt.Errorf("OIDC provider %q: name must be non-empty and unique", name)
+	}
+	issuer = strings.TrimSuffix(issuer, "/")
+	for _, other := range r.providers {
+		if strings.TrimSuffix(other.Issuer(), "/") == issuer {
+			return fmt.Errorf("OIDC providers %q and %q share issuer %s", other.Name(), name, issuer)
+		}
+	}
+	return nil
+}
+
+func (r *Registry) add(service *Service) {
+	r.providers[service.Name()] = service
+	r.names = append(r.names, service.Name())
+}
@@ -4,19 +4,26 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
 	"hkers-backend/internal/user"
 )
 
 // RegisterAuthRoutes registers auth routes on the given router.
-func RegisterAuthRoutes(router *gin.Engine, authSvc ServiceInterface, userSvc user.ServiceInterface, jwtManager response.JWTManager) {
-	h := NewHandler(authSvc, userSvc, jwtManager)
+func RegisterAuthRoutes(router *gin.Engine, providers ProviderRegistry, userSvc user.ServiceInterface, jwtManager response.JWTManager, refreshTokens RefreshTokenManagerInterface, jwks JWKSProvider) {
+	h := NewHandler(providers, userSvc, jwtManager, refreshTokens, jwks)
 
 	// Auth routes under /auth
 	auth := router.Group("/auth")
 	{
-		auth.GET("/login", h.Login)           // Initiates OIDC flow
-		auth.GET("/callback", h.Callback)     // Returns JWT token
-		auth.POST("/logout", h.Logout)        // Client-side logout with optional OIDC logout URL
-		auth.POST("/refresh", h.RefreshToken) // Refresh JWT token
+		auth.GET("/providers", h.Providers)                            // Lists configured identity providers
+		auth.GET("/login", h.Login)                                    // Initiates OIDC flow with the default provider
+		auth.GET("/login/:provider", h.Login)                          // Initiates OIDC flow with the named provider
+		auth.GET("/callback", h.Callback)                              // Returns access and refresh tokens
+		auth.GET("/callback/:provider", h.Callback)                    // Same, for providers registered with a per-provider redirect URL
+		auth.POST("/logout", middleware.JWTAuth(jwtManager), h.Logout) // Revokes the tokens, returns optional OIDC logout URL
+		auth.POST("/refresh", h.RefreshToken)                          // Rotates the refresh token, returns a new token pair
 	}
+
+	// Public verification keys for partner services (RFC 7517)
+	router.GET("/.well-known/jwks.json", h.JWKS)
 }
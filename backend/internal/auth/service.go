@@ -6,7 +6,10 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
@@ -15,8 +18,19 @@ import (
 	"hkers-backend/internal/config"
 )
 
+// subjectSeparator joins the issuer and the provider's sub claim in users.oidc_sub.
+const subjectSeparator = "|"
+
+// Identity is the user identity asserted by a verified ID token.
+type Identity struct {
+	Subject  string // Issuer-namespaced subject, stored in users.oidc_sub
+	Username string
+	Email    string
+}
+
 // Service handles authentication logic with a generic OIDC provider.
 type Service struct {
+	name          string
 	provider      *oidc.Provider
 	config        oauth2.Config
 	issuer        string
@@ -24,23 +38,37 @@ type Service struct {
 	endSessionURL string
 	postLogoutURL string
 	oidcCfg       config.OIDCConfig
+	client        *http.Client
+}
+
+// NamespacedSubject scopes an OIDC sub claim to its issuer, so the same sub
+// from two providers can never map to the same user.
+func NamespacedSubject(issuer, sub string) string {
+	return strings.TrimSuffix(issuer, "/") + subjectSeparator + sub
 }
 
 // NewService creates a new OIDC authentication service instance.
 func NewService(cfg *config.OIDCConfig) (*Service, error) {
+	return NewServiceWithClient(cfg, nil)
+}
+
+// NewServiceWithClient creates an OIDC authentication service that talks to the
+// provider through client, e.g. to reach the development provider before the server
+// is listening. A nil client uses the default HTTP client.
+func NewServiceWithClient(cfg *config.OIDCConfig, client *http.Client) (*Service, error) {
 	// Validate required configuration
 	required := []struct {
 		value string
-		err   string
+		field string
 	}{
-		{cfg.Issuer, "OIDC issuer is required but not configured. Set OIDC_ISSUER environment variable"},
-		{cfg.ClientID, "OIDC client ID is required but not configured. Set OIDC_CLIENT_ID environment variable"},
-		{cfg.ClientSecret, "OIDC client secret is required but not configured. Set OIDC_CLIENT_SECRET environment variable"},
-		{cfg.RedirectURL, "OIDC redirect URL is required but not configured. Set OIDC_REDIRECT_URL environment variable"},
+		{cfg.Issuer, "issuer"},
+		{cfg.ClientID, "client ID"},
+		{cfg.ClientSecret, "client secret"},
+		{cfg.RedirectURL, "redirect URL"},
 	}
 	for _, item := range required {
 		if item.value == "" {
-			return nil, errors.New(item.err)
+			return nil, fmt.Errorf("OIDC %s is required but not configured for provider %q", item.field, cfg.Name)
 		}
 	}
 
@@ -49,10 +77,13 @@ func NewService(cfg *config.OIDCConfig) (*Service, error) {
 	// Create context with timeout to prevent hanging
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	if client != nil {
+		ctx = oidc.ClientContext(ctx, client)
+	}
 
 	provider, err := oidc.NewProvider(ctx, issuerURL)
 	if err != nil {
-		return nil, errors.New("failed to initialize OIDC provider: " + err.Error() + " (issuer URL: " + issuerURL + "). Check that OIDC_ISSUER is correct and accessible.")
+		return nil, errors.New("failed to initialize OIDC provider: " + err.Error() + " (issuer URL: " + issuerURL + "). Check that the issuer of provider " + cfg.Name + " is correct and accessible.")
 	}
 
 	scopes := cfg.Scopes
@@ -69,6 +100,7 @@ func NewService(cfg *config.OIDCConfig) (*Service, error) {
 	}
 
 	return &Service{
+		name:          cfg.Name,
 		provider:      provider,
 		config:        oauthConfig,
 		issuer:        cfg.Issuer,
@@ -76,9 +108,20 @@ func NewService(cfg *config.OIDCConfig) (*Service, error) {
 		endSessionURL: cfg.EndSessionURL,
 		postLogoutURL: cfg.PostLogoutRedirectURL,
 		oidcCfg:       *cfg,
+		client:        client,
 	}, nil
 }
 
+// Name returns the provider's registry name.
+func (s *Service) Name() string {
+	return s.name
+}
+
+// Issuer returns the provider's issuer URL.
+func (s *Service) Issuer() string {
+	return s.issuer
+}
+
 // GenerateState creates a random state string for CSRF protection.
 func (s *Service) GenerateState() (string, error) {
 	b := make([]byte, 32)
@@ -118,6 +161,9 @@ func (s *Service) GetAuthURLWithPKCE(state, codeChallenge string) string {
 
 // ExchangeCodeWithPKCE exchanges a code using the provided PKCE verifier.
 func (s *Service) ExchangeCodeWithPKCE(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	if s.client != nil {
+		ctx = oidc.ClientContext(ctx, s.client)
+	}
 	return s.config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
 }
 
@@ -174,3 +220,31 @@ func (s *Service) ExtractClaims(idToken *oidc.IDToken) (map[string]interface{},
 	}
 	return claims, nil
 }
+
+// ExtractIdentity maps a verified ID token to a user identity using the
+// provider's configured username and email claims. The username is empty when
+// none of the configured claims is set; the user service then derives one from
+// the namespaced subject, so that equal sub claims at different issuers do not
+// collide.
+func (s *Service) ExtractIdentity(idToken *oidc.IDToken) (*Identity, error) {
+	if idToken.Subject == "" {
+		return nil, errors.New("ID token has no sub claim")
+	}
+
+	claims, err := s.ExtractClaims(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{Subject: NamespacedSubject(idToken.Issuer, idToken.Subject)}
+	for _, claim := range s.oidcCfg.UsernameClaims {
+		if username, _ := claims[claim].(string); username != "" {
+			identity.Username = username
+			break
+		}
+	}
+	if s.oidcCfg.EmailClaim != "" {
+		identity.Email, _ = claims[s.oidcCfg.EmailClaim].(string)
+	}
+	return identity, nil
+}
@@ -0,0 +1,74 @@
+package checkin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+)
+
+// Handler handles check-in HTTP requests.
+type Handler struct {
+	checkinService ServiceInterface
+}
+
+// NewHandler creates a new check-in Handler instance.
+func NewHandler(checkinService ServiceInterface) HandlerInterface {
+	return &Handler{
+		checkinService: checkinService,
+	}
+}
+
+// createCheckinRequest is the body accepted when checking in at a station.
+type createCheckinRequest struct {
+	Latitude  *float64 `json:"latitude" binding:"required,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" binding:"required,gte=-180,lte=180"`
+	Notes     *string  `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// CreateCheckin records the authenticated volunteer's check-in at a station.
+// POST /api/v1/stations/:id/checkins
+func (h *Handler) CreateCheckin(ctx *gin.Context) {
+	stationID, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || stationID <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid id")
+		return
+	}
+
+	var req createCheckinRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		response.Error(ctx, http.StatusUnauthorized, "Missing user in token")
+		return
+	}
+
+	result, err := h.checkinService.CreateCheckin(ctx.Request.Context(), userID, int32(stationID), CheckinInput{
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+		Notes:     req.Notes,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrStationNotFound):
+			response.Error(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrTooFarFromStation):
+			response.Error(ctx, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, ErrAlreadyCheckedIn):
+			response.Error(ctx, http.StatusConflict, err.Error())
+		default:
+			response.Error(ctx, http.StatusInternalServerError, "Failed to create check-in")
+		}
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, result)
+}
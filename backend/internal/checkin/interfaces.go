@@ -0,0 +1,22 @@
+package checkin
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceInterface defines the interface for check-in services
+type ServiceInterface interface {
+	CreateCheckin(ctx context.Context, userID, stationID int32, input CheckinInput) (*CheckinResult, error)
+}
+
+// HandlerInterface defines the interface for check-in HTTP handlers
+type HandlerInterface interface {
+	CreateCheckin(ctx *gin.Context)
+}
+
+// TileInvalidator discards cached map tiles after a station's verification state changes.
+type TileInvalidator interface {
+	InvalidateTiles(ctx context.Context) error
+}
@@ -0,0 +1,21 @@
+package checkin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// RegisterCheckinRoutes registers check-in routes on the given router.
+func RegisterCheckinRoutes(router *gin.Engine, checkinSvc ServiceInterface, jwtManager response.JWTManager) {
+	h := NewHandler(checkinSvc)
+
+	// Check-ins are nested under their station - require JWT authentication
+	stations := router.Group("/api/v1/stations")
+	stations.Use(middleware.JWTAuth(jwtManager))
+	{
+		stations.POST("/:id/checkins", middleware.RequirePermission(db.AppPermissionCreateCheckins), h.CreateCheckin)
+	}
+}
@@ -0,0 +1,141 @@
+package checkin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/pgerr"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	ErrStationNotFound   = errors.New("station not found")
+	ErrTooFarFromStation = errors.New("check-in location is too far from the station")
+	ErrAlreadyCheckedIn  = errors.New("you have already checked in at this station")
+)
+
+// CheckinInput is the volunteer-reported position and optional notes for a check-in.
+type CheckinInput struct {
+	Latitude  float64
+	Longitude float64
+	Notes     *string
+}
+
+// Checkin is the API representation of a recorded check-in.
+type Checkin struct {
+	ID             int32              `json:"id"`
+	UserID         pgtype.Int4        `json:"user_id"`
+	StationID      pgtype.Int4        `json:"station_id"`
+	Latitude       float64            `json:"latitude"`
+	Longitude      float64            `json:"longitude"`
+	DistanceMeters float64            `json:"distance_meters"`
+	CheckinTime    pgtype.Timestamptz `json:"checkin_time"`
+	Notes          pgtype.Text        `json:"notes"`
+}
+
+// CheckinResult is a new check-in together with the station's resulting verification state.
+type CheckinResult struct {
+	Checkin               Checkin `json:"checkin"`
+	VerificationCount     int32   `json:"verification_count"`
+	VerificationThreshold int32   `json:"verification_threshold"`
+	IsVerified            bool    `json:"is_verified"`
+}
+
+// Service handles volunteer check-in business logic.
+type Service struct {
+	queries           *db.Queries
+	runner            *dbtx.Runner
+	maxDistanceMeters float64
+	tiles             TileInvalidator
+}
+
+// NewService creates a new check-in service instance.
+// Check-ins farther than maxDistanceMeters from the station are rejected;
+// tiles, if non-nil, is invalidated after every accepted check-in.
+func NewService(pool *pgxpool.Pool, maxDistanceMeters float64, tiles TileInvalidator) *Service {
+	return &Service{
+		queries:           db.New(pool),
+		runner:            dbtx.New(pool),
+		maxDistanceMeters: maxDistanceMeters,
+		tiles:             tiles,
+	}
+}
+
+// CreateCheckin records a volunteer's on-scene check-in at a station.
+// The database trigger increments the station's verification count, which in
+// turn verifies the station once its threshold is reached.
+func (s *Service) CreateCheckin(ctx context.Context, userID, stationID int32, input CheckinInput) (*CheckinResult, error) {
+	distance, err := s.queries.GetDistanceToStation(ctx, db.GetDistanceToStationParams{
+		Lng:       input.Longitude,
+		Lat:       input.Latitude,
+		StationID: stationID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStationNotFound
+		}
+		return nil, err
+	}
+	if distance > s.maxDistanceMeters {
+		return nil, fmt.Errorf("%w: %.0f m away, at most %.0f m allowed", ErrTooFarFromStation, distance, s.maxDistanceMeters)
+	}
+
+	notes := pgtype.Text{}
+	if input.Notes != nil {
+		notes = pgtype.Text{String: *input.Notes, Valid: true}
+	}
+
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.Checkin, error) {
+		return q.CreateCheckin(ctx, db.CreateCheckinParams{
+			UserID:        pgtype.Int4{Int32: userID, Valid: true},
+			StationID:     pgtype.Int4{Int32: stationID, Valid: true},
+			StMakepoint:   input.Longitude,
+			StMakepoint_2: input.Latitude,
+			Notes:         notes,
+		})
+	})
+	if err != nil {
+		switch {
+		case pgerr.IsUniqueViolation(err):
+			return nil, ErrAlreadyCheckedIn
+		case pgerr.IsForeignKeyViolation(err):
+			// The station was deleted between the distance check and the insert
+			return nil, ErrStationNotFound
+		}
+		return nil, err
+	}
+
+	station, err := s.queries.GetStationByID(ctx, stationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.tiles != nil {
+		if err := s.tiles.InvalidateTiles(ctx); err != nil {
+			log.Printf("checkin: failed to invalidate tile cache: %v", err)
+		}
+	}
+
+	return &CheckinResult{
+		Checkin: Checkin{
+			ID:             row.ID,
+			UserID:         row.UserID,
+			StationID:      row.StationID,
+			Latitude:       input.Latitude,
+			Longitude:      input.Longitude,
+			DistanceMeters: distance,
+			CheckinTime:    row.CheckinTime,
+			Notes:          row.Notes,
+		},
+		VerificationCount:     station.VerificationCount.Int32,
+		VerificationThreshold: station.VerificationThreshold,
+		IsVerified:            station.IsVerified.Bool,
+	}, nil
+}
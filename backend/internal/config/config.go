@@ -16,6 +16,11 @@ type Config struct {
 	Database DatabaseConfig
 	Redis    RedisConfig
 	Auth     AuthConfig
+	RBAC     RBACConfig
+	Station  StationConfig
+	Checkin  CheckinConfig
+	Audit    AuditConfig
+	News     NewsConfig
 	CORS     CORSConfig
 }
 
@@ -72,18 +77,35 @@ func (r *RedisConfig) GetAddr() string {
 
 // AuthConfig holds authentication-related configuration.
 type AuthConfig struct {
-	JWT  JWTConfig
-	OIDC OIDCConfig
+	JWT           JWTConfig
+	OIDCProviders []OIDCConfig // Identity providers; the first is used by /auth/login
+	DevOIDC       DevOIDCConfig
 }
 
 // JWTConfig holds JWT token configuration.
 type JWTConfig struct {
-	Secret   string
-	Duration time.Duration
+	Secret          string
+	Duration        time.Duration // Access token lifetime
+	RefreshDuration time.Duration // Refresh token lifetime
+
+	// Algorithm is HS256 (signed with Secret), RS256 or EdDSA (signed with SigningKey).
+	Algorithm        string
+	SigningKeyID     string // kid of the active signing key, published in the JWKS
+	SigningKey       string // PEM private key; takes precedence over SigningKeyFile
+	SigningKeyFile   string // Path to a PEM private key
+	VerificationKeys []JWTKeyFile
 }
 
-// OIDCConfig holds OpenID Connect configuration.
+// JWTKeyFile is a retired key that is still accepted when verifying tokens,
+// so that tokens signed before a key rotation stay valid until they expire.
+type JWTKeyFile struct {
+	ID   string // kid
+	Path string // PEM public (or private) key
+}
+
+// OIDCConfig holds OpenID Connect configuration for one identity provider.
 type OIDCConfig struct {
+	Name                  string // Selects the provider in /auth/login/{name}
 	Issuer                string
 	ClientID              string
 	ClientSecret          string
@@ -91,6 +113,70 @@ type OIDCConfig struct {
 	Scopes                []string
 	EndSessionURL         string
 	PostLogoutRedirectURL string
+	UsernameClaims        []string // ID token claims tried in order for the username
+	EmailClaim            string   // ID token claim holding the email address
+}
+
+// DevOIDCConfig holds configuration for the built-in development identity provider.
+// It is registered as provider "dev" and refuses to start with GIN_MODE=release.
+type DevOIDCConfig struct {
+	Enabled     bool
+	Issuer      string // Public URL of the /dev/oidc routes, as reached by the browser
+	RedirectURL string // Callback URL for provider "dev"
+	Users       []DevOIDCUser
+}
+
+// DevOIDCUser is a seeded identity offered on the development authorize page.
+type DevOIDCUser struct {
+	Subject  string
+	Username string
+	Email    string
+	Role     string // Role granted when the user is seeded; empty for none
+}
+
+// RBACConfig holds role-based access control configuration.
+type RBACConfig struct {
+	PermissionCacheTTL time.Duration // How long user status, roles and permissions are cached in Redis (0 disables)
+}
+
+// StationConfig holds supply station configuration.
+type StationConfig struct {
+	TileCacheTTL   time.Duration // How long rendered vector tiles are cached in Redis (0 disables)
+	NeedStaleAfter time.Duration // Needs not confirmed within this window are flagged as stale
+}
+
+// CheckinConfig holds volunteer check-in configuration.
+type CheckinConfig struct {
+	MaxDistanceMeters float64 // Check-ins farther than this from the station are rejected
+}
+
+// AuditConfig holds audit trail configuration.
+type AuditConfig struct {
+	CheckpointSecret string        // HMAC key signing the checkpoints that archive pruned audit log segments
+	Retention        time.Duration // Entries older than this are archived by "audit prune"
+}
+
+// NewsConfig holds news feed ingestion configuration.
+type NewsConfig struct {
+	Feeds        []NewsFeedConfig
+	MaxBackoff   time.Duration // Upper bound on the delay before retrying a failing feed
+	FetchTimeout time.Duration // Timeout of a single feed request
+	UserAgent    string
+}
+
+// News feed formats.
+const (
+	NewsFeedFormatFeed = "feed" // RSS or Atom entries become news items
+	NewsFeedFormatCAP  = "cap"  // A CAP alert, or an RSS/Atom index linking to CAP alerts
+)
+
+// NewsFeedConfig is a feed whose entries are ingested into news.
+type NewsFeedConfig struct {
+	Name     string
+	URL      string
+	Source   string        // Stored as news.source; defaults to the feed name
+	Format   string        // NewsFeedFormatFeed or NewsFeedFormatCAP
+	Interval time.Duration // Time between polls while the feed is healthy
 }
 
 // CORSConfig holds CORS-related configuration.
@@ -123,6 +209,11 @@ func Load() (*Config, error) {
 		Database: loadDatabaseConfig(),
 		Redis:    loadRedisConfig(),
 		Auth:     loadAuthConfig(),
+		RBAC:     loadRBACConfig(),
+		Station:  loadStationConfig(),
+		Checkin:  loadCheckinConfig(),
+		Audit:    loadAuditConfig(),
+		News:     loadNewsConfig(),
 		CORS:     loadCORSConfig(),
 	}
 
@@ -178,37 +269,244 @@ func loadRedisConfig() RedisConfig {
 // loadAuthConfig loads authentication configuration from environment variables.
 func loadAuthConfig() AuthConfig {
 	// JWT Config
+	// Required for HS256; never shared with the session cookie secret
 	jwtSecret := getEnv("JWT_SECRET", "")
-	jwtDurationStr := getEnv("JWT_DURATION", "168h") // Default 7 days
+	jwtDurationStr := getEnv("JWT_DURATION", "15m") // Default 15 minutes
 	jwtDuration, err := time.ParseDuration(jwtDurationStr)
 	if err != nil {
-		jwtDuration = 7 * 24 * time.Hour // fallback to 7 days
+		jwtDuration = 15 * time.Minute // fallback to 15 minutes
 	}
-
-	// OIDC Config
-	oidcScopes := strings.TrimSpace(getEnv("OIDC_SCOPES", ""))
-	if oidcScopes == "" {
-		oidcScopes = "openid,profile,email"
+	jwtRefreshDurationStr := getEnv("JWT_REFRESH_DURATION", "720h") // Default 30 days
+	jwtRefreshDuration, err := time.ParseDuration(jwtRefreshDurationStr)
+	if err != nil {
+		jwtRefreshDuration = 30 * 24 * time.Hour // fallback to 30 days
 	}
-	rawScopes := strings.Split(oidcScopes, ",")
-	for i := range rawScopes {
-		rawScopes[i] = strings.TrimSpace(rawScopes[i])
+
+	// Allow single-line PEM in env files by accepting literal "\n"
+	signingKey := strings.ReplaceAll(getEnv("JWT_SIGNING_KEY", ""), `\n`, "\n")
+
+	// Verification keys: comma-separated "kid=path" pairs
+	var verificationKeys []JWTKeyFile
+	for _, entry := range strings.Split(getEnv("JWT_VERIFICATION_KEYS", ""), ",") {
+		kid, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		verificationKeys = append(verificationKeys, JWTKeyFile{
+			ID:   strings.TrimSpace(kid),
+			Path: strings.TrimSpace(path),
+		})
 	}
 
 	return AuthConfig{
 		JWT: JWTConfig{
-			Secret:   jwtSecret,
-			Duration: jwtDuration,
-		},
-		OIDC: OIDCConfig{
-			Issuer:                strings.TrimSpace(getEnv("OIDC_ISSUER", "")),
-			ClientID:              strings.TrimSpace(getEnv("OIDC_CLIENT_ID", "")),
-			ClientSecret:          strings.TrimSpace(getEnv("OIDC_CLIENT_SECRET", "")),
-			RedirectURL:           strings.TrimSpace(getEnv("OIDC_REDIRECT_URL", "")),
-			Scopes:                rawScopes,
-			EndSessionURL:         strings.TrimSpace(getEnv("OIDC_END_SESSION_URL", "")),
-			PostLogoutRedirectURL: strings.TrimSpace(getEnv("OIDC_POST_LOGOUT_REDIRECT_URL", "")),
+			Secret:           jwtSecret,
+			Duration:         jwtDuration,
+			RefreshDuration:  jwtRefreshDuration,
+			Algorithm:        strings.TrimSpace(getEnv("JWT_ALGORITHM", "HS256")),
+			SigningKeyID:     strings.TrimSpace(getEnv("JWT_SIGNING_KEY_ID", "")),
+			SigningKey:       signingKey,
+			SigningKeyFile:   strings.TrimSpace(getEnv("JWT_SIGNING_KEY_FILE", "")),
+			VerificationKeys: verificationKeys,
 		},
+		OIDCProviders: loadOIDCProviders(),
+		DevOIDC:       loadDevOIDCConfig(),
+	}
+}
+
+// loadOIDCProviders loads the identity providers named in OIDC_PROVIDERS, each
+// configured with OIDC_<NAME>_* variables. Without OIDC_PROVIDERS, the plain
+// OIDC_* variables configure a single provider named "default".
+func loadOIDCProviders() []OIDCConfig {
+	names := strings.TrimSpace(getEnv("OIDC_PROVIDERS", ""))
+	if names == "" {
+		if strings.TrimSpace(getEnv("OIDC_ISSUER", "")) == "" {
+			return nil
+		}
+		return []OIDCConfig{loadOIDCConfig("default", "OIDC_")}
+	}
+
+	var providers []OIDCConfig
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		providers = append(providers, loadOIDCConfig(name, prefix))
+	}
+	return providers
+}
+
+// loadOIDCConfig loads one identity provider from variables starting with prefix.
+func loadOIDCConfig(name, prefix string) OIDCConfig {
+	return OIDCConfig{
+		Name:                  name,
+		Issuer:                strings.TrimSpace(getEnv(prefix+"ISSUER", "")),
+		ClientID:              strings.TrimSpace(getEnv(prefix+"CLIENT_ID", "")),
+		ClientSecret:          strings.TrimSpace(getEnv(prefix+"CLIENT_SECRET", "")),
+		RedirectURL:           strings.TrimSpace(getEnv(prefix+"REDIRECT_URL", "")),
+		Scopes:                splitList(getEnv(prefix+"SCOPES", ""), "openid,profile,email"),
+		EndSessionURL:         strings.TrimSpace(getEnv(prefix+"END_SESSION_URL", "")),
+		PostLogoutRedirectURL: strings.TrimSpace(getEnv(prefix+"POST_LOGOUT_REDIRECT_URL", "")),
+		UsernameClaims:        splitList(getEnv(prefix+"USERNAME_CLAIM", ""), "nickname,name"),
+		EmailClaim:            strings.TrimSpace(getEnv(prefix+"EMAIL_CLAIM", "email")),
+	}
+}
+
+// loadDevOIDCConfig loads the development identity provider from DEV_OIDC_*
+// variables. DEV_OIDC_USERS lists "sub:username:email:role" entries.
+func loadDevOIDCConfig() DevOIDCConfig {
+	baseURL := "http://localhost:" + getEnv("SERVER_PORT", "3000")
+
+	var users []DevOIDCUser
+	for _, entry := range splitList(getEnv("DEV_OIDC_USERS", ""), "volunteer:volunteer:volunteer@example.org:user,manager:manager:manager@example.org:manager,admin:admin:admin@example.org:admin") {
+		fields := strings.Split(entry, ":")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		for len(fields) < 4 {
+			fields = append(fields, "")
+		}
+		users = append(users, DevOIDCUser{
+			Subject:  strings.TrimSpace(fields[0]),
+			Username: strings.TrimSpace(fields[1]),
+			Email:    strings.TrimSpace(fields[2]),
+			Role:     strings.TrimSpace(fields[3]),
+		})
+	}
+
+	return DevOIDCConfig{
+		Enabled:     getEnv("DEV_OIDC_ENABLED", "false") == "true",
+		Issuer:      strings.TrimSuffix(strings.TrimSpace(getEnv("DEV_OIDC_ISSUER", baseURL+"/dev/oidc")), "/"),
+		RedirectURL: strings.TrimSpace(getEnv("DEV_OIDC_REDIRECT_URL", baseURL+"/auth/callback/dev")),
+		Users:       users,
+	}
+}
+
+// splitList splits a comma-separated value, using fallback when it is empty.
+func splitList(value, fallback string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = fallback
+	}
+	items := strings.Split(value, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// loadRBACConfig loads RBAC configuration from environment variables.
+func loadRBACConfig() RBACConfig {
+	cacheTTL, err := time.ParseDuration(getEnv("RBAC_PERMISSION_CACHE_TTL", "30s"))
+	if err != nil || cacheTTL < 0 {
+		cacheTTL = 30 * time.Second
+	}
+
+	return RBACConfig{
+		PermissionCacheTTL: cacheTTL,
+	}
+}
+
+// loadStationConfig loads supply station configuration from environment variables.
+func loadStationConfig() StationConfig {
+	tileCacheTTL, err := time.ParseDuration(getEnv("STATION_TILE_CACHE_TTL", "10m"))
+	if err != nil || tileCacheTTL < 0 {
+		tileCacheTTL = 10 * time.Minute
+	}
+
+	needStaleAfter, err := time.ParseDuration(getEnv("STATION_NEED_STALE_AFTER", "24h"))
+	if err != nil || needStaleAfter <= 0 {
+		needStaleAfter = 24 * time.Hour
+	}
+
+	return StationConfig{
+		TileCacheTTL:   tileCacheTTL,
+		NeedStaleAfter: needStaleAfter,
+	}
+}
+
+// loadCheckinConfig loads check-in configuration from environment variables.
+func loadCheckinConfig() CheckinConfig {
+	maxDistance, err := strconv.ParseFloat(getEnv("CHECKIN_MAX_DISTANCE_METERS", "200"), 64)
+	if err != nil || maxDistance <= 0 {
+		maxDistance = 200
+	}
+
+	return CheckinConfig{
+		MaxDistanceMeters: maxDistance,
+	}
+}
+
+// loadAuditConfig loads audit trail configuration from environment variables.
+func loadAuditConfig() AuditConfig {
+	retention, err := time.ParseDuration(getEnv("AUDIT_RETENTION", "2160h"))
+	if err != nil || retention <= 0 {
+		retention = 90 * 24 * time.Hour
+	}
+
+	return AuditConfig{
+		CheckpointSecret: getEnv("AUDIT_CHECKPOINT_SECRET", ""),
+		Retention:        retention,
+	}
+}
+
+// loadNewsConfig loads the feeds named in NEWS_FEEDS, each configured with
+// NEWS_FEED_<NAME>_* variables, and the settings shared by all feeds.
+func loadNewsConfig() NewsConfig {
+	defaultInterval, err := time.ParseDuration(getEnv("NEWS_FEED_INTERVAL", "15m"))
+	if err != nil || defaultInterval < time.Minute {
+		defaultInterval = 15 * time.Minute
+	}
+
+	maxBackoff, err := time.ParseDuration(getEnv("NEWS_FEED_MAX_BACKOFF", "6h"))
+	if err != nil || maxBackoff <= 0 {
+		maxBackoff = 6 * time.Hour
+	}
+
+	fetchTimeout, err := time.ParseDuration(getEnv("NEWS_FEED_TIMEOUT", "30s"))
+	if err != nil || fetchTimeout <= 0 {
+		fetchTimeout = 30 * time.Second
+	}
+
+	var feeds []NewsFeedConfig
+	for _, name := range strings.Split(getEnv("NEWS_FEEDS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "NEWS_FEED_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+		feedURL := strings.TrimSpace(getEnv(prefix+"URL", ""))
+		if feedURL == "" {
+			log.Printf("News feed %q has no %sURL, skipping it", name, prefix)
+			continue
+		}
+		format := strings.ToLower(strings.TrimSpace(getEnv(prefix+"FORMAT", NewsFeedFormatFeed)))
+		if format != NewsFeedFormatFeed && format != NewsFeedFormatCAP {
+			log.Printf("News feed %q has unknown %sFORMAT %q, skipping it", name, prefix, format)
+			continue
+		}
+		interval, err := time.ParseDuration(getEnv(prefix+"INTERVAL", ""))
+		if err != nil || interval < time.Minute {
+			interval = defaultInterval
+		}
+
+		feeds = append(feeds, NewsFeedConfig{
+			Name:     name,
+			URL:      feedURL,
+			Source:   strings.TrimSpace(getEnv(prefix+"SOURCE", name)),
+			Format:   format,
+			Interval: interval,
+		})
+	}
+
+	return NewsConfig{
+		Feeds:        feeds,
+		MaxBackoff:   maxBackoff,
+		FetchTimeout: fetchTimeout,
+		UserAgent:    strings.TrimSpace(getEnv("NEWS_FEED_USER_AGENT", "hkers-news-ingester/1.0")),
 	}
 }
 
@@ -244,7 +542,7 @@ func loadCORSConfig() CORSConfig {
 	}
 
 	// Expose headers
-	exposeStr := getEnv("CORS_EXPOSE_HEADERS", "Content-Length")
+	exposeStr := getEnv("CORS_EXPOSE_HEADERS", "Content-Length,X-Request-ID")
 	exposeHeaders := strings.Split(exposeStr, ",")
 	for i := range exposeHeaders {
 		exposeHeaders[i] = strings.TrimSpace(exposeHeaders[i])
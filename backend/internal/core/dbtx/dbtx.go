@@ -0,0 +1,116 @@
+package dbtx
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hkers-backend/internal/core/pgerr"
+	"hkers-backend/internal/core/response"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+type contextKey int
+
+const (
+	principalKey contextKey = iota
+	requestIDKey
+)
+
+// WithPrincipal returns a context carrying the authenticated principal,
+// whose user is recorded as the author of changes made with it.
+func WithPrincipal(ctx context.Context, principal *response.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext returns the principal stored by WithPrincipal.
+func PrincipalFromContext(ctx context.Context) (*response.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*response.Principal)
+	return principal, ok && principal != nil
+}
+
+// WithRequestID returns a context carrying the ID of the request being served.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// Runner wraps db.New(pool) so that writes run in transactions attributed to
+// the principal and request in the context. Audit triggers read the acting
+// user from app.current_user_id and the request from app.request_id.
+type Runner struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+// New creates a runner for the given pool.
+func New(pool *pgxpool.Pool) *Runner {
+	return &Runner{
+		pool:    pool,
+		queries: db.New(pool),
+	}
+}
+
+// deadlockRetries is how many times InTx reruns a transaction that Postgres
+// aborted to break a deadlock. Audited writes all take the audit chain lock (see
+// chain_audit_log in schema.sql) while holding row locks, so two of them can
+// deadlock when they touch the same rows in different orders.
+const deadlockRetries = 3
+
+// InTx runs fn in a transaction, after setting app.current_user_id and
+// app.request_id for that transaction only (as SET LOCAL would). The transaction
+// commits if fn returns nil and rolls back otherwise. If Postgres aborts it to
+// break a deadlock, it is retried from the start, so fn may run more than once
+// and must not have effects outside the transaction.
+func (r *Runner) InTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	for attempt := 0; ; attempt++ {
+		err := r.inTx(ctx, fn)
+		if attempt == deadlockRetries || !pgerr.IsDeadlock(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+func (r *Runner) inTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	if err := q.SetAuditContext(ctx, auditContext(ctx)); err != nil {
+		return err
+	}
+	if err := fn(q); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Write runs a single write query through InTx and returns its result.
+func Write[T any](ctx context.Context, r *Runner, fn func(q *db.Queries) (T, error)) (T, error) {
+	var result T
+	err := r.InTx(ctx, func(q *db.Queries) error {
+		var err error
+		result, err = fn(q)
+		return err
+	})
+	return result, err
+}
+
+// auditContext builds the transaction settings from ctx. Changes made without
+// a user (API keys, background jobs) are attributed to no one.
+func auditContext(ctx context.Context) db.SetAuditContextParams {
+	params := db.SetAuditContextParams{RequestID: RequestIDFromContext(ctx)}
+	if principal, ok := PrincipalFromContext(ctx); ok && principal.UserID > 0 {
+		params.UserID = strconv.FormatInt(int64(principal.UserID), 10)
+	}
+	return params
+}
@@ -0,0 +1,89 @@
+package geo
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// EWKB geometry type flags used by PostGIS.
+const (
+	ewkbSRIDFlag = 0x20000000
+	ewkbTypeMask = 0x0fffffff
+	wkbPoint     = 1
+)
+
+// ErrInvalidPoint is returned when a value cannot be decoded as a PostGIS point.
+var ErrInvalidPoint = errors.New("invalid point geometry")
+
+// Point is a WGS84 coordinate pair.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// DecodePoint decodes a PostGIS GEOGRAPHY(POINT) value as returned by pgx.
+// sqlc maps geography columns to interface{}, which pgx fills with the
+// hex-encoded EWKB text representation (or raw bytes in binary format).
+func DecodePoint(value interface{}) (Point, error) {
+	var raw []byte
+	switch v := value.(type) {
+	case string:
+		b, err := hex.DecodeString(v)
+		if err != nil {
+			return Point{}, fmt.Errorf("%w: %v", ErrInvalidPoint, err)
+		}
+		raw = b
+	case []byte:
+		// Binary EWKB, or hex text delivered as bytes
+		if b, err := hex.DecodeString(string(v)); err == nil {
+			raw = b
+		} else {
+			raw = v
+		}
+	case nil:
+		return Point{}, fmt.Errorf("%w: value is NULL", ErrInvalidPoint)
+	default:
+		return Point{}, fmt.Errorf("%w: unsupported type %T", ErrInvalidPoint, value)
+	}
+
+	return decodeEWKBPoint(raw)
+}
+
+// decodeEWKBPoint parses an (E)WKB point, ignoring any SRID, Z or M values.
+func decodeEWKBPoint(raw []byte) (Point, error) {
+	if len(raw) < 5 {
+		return Point{}, ErrInvalidPoint
+	}
+
+	var order binary.ByteOrder
+	switch raw[0] {
+	case 0:
+		order = binary.BigEndian
+	case 1:
+		order = binary.LittleEndian
+	default:
+		return Point{}, ErrInvalidPoint
+	}
+
+	geomType := order.Uint32(raw[1:5])
+	if geomType&ewkbTypeMask != wkbPoint {
+		return Point{}, fmt.Errorf("%w: geometry type %d is not a point", ErrInvalidPoint, geomType&ewkbTypeMask)
+	}
+
+	offset := 5
+	if geomType&ewkbSRIDFlag != 0 {
+		offset += 4
+	}
+
+	// X (longitude) and Y (latitude) always come first; Z/M follow and are ignored
+	if len(raw) < offset+16 {
+		return Point{}, ErrInvalidPoint
+	}
+	x := math.Float64frombits(order.Uint64(raw[offset : offset+8]))
+	y := math.Float64frombits(order.Uint64(raw[offset+8 : offset+16]))
+
+	return Point{Latitude: y, Longitude: x}, nil
+}
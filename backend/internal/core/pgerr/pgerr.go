@@ -0,0 +1,49 @@
+package pgerr
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres SQLSTATE codes the services react to.
+const (
+	codeUniqueViolation     = "23505"
+	codeForeignKeyViolation = "23503"
+	codeInvalidTextInput    = "22P02"
+	codeDeadlockDetected    = "40P01"
+)
+
+// IsUniqueViolation reports whether err was caused by a unique constraint violation.
+func IsUniqueViolation(err error) bool {
+	return hasCode(err, codeUniqueViolation)
+}
+
+// IsUniqueViolationOn reports whether err was caused by a violation of the named
+// unique constraint, e.g. "users_email_key".
+func IsUniqueViolationOn(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == codeUniqueViolation && pgErr.ConstraintName == constraint
+}
+
+// IsForeignKeyViolation reports whether err was caused by a foreign key violation.
+func IsForeignKeyViolation(err error) bool {
+	return hasCode(err, codeForeignKeyViolation)
+}
+
+// IsInvalidTextInput reports whether err was caused by a value Postgres could not
+// parse for its column type, such as an unknown enum label.
+func IsInvalidTextInput(err error) bool {
+	return hasCode(err, codeInvalidTextInput)
+}
+
+// IsDeadlock reports whether err was caused by Postgres aborting the transaction
+// to break a deadlock. The transaction can be retried.
+func IsDeadlock(err error) bool {
+	return hasCode(err, codeDeadlockDetected)
+}
+
+func hasCode(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
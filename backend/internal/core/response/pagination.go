@@ -0,0 +1,30 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParsePagination reads the limit and offset query parameters. limit defaults
+// to defaultLimit and is capped at maxLimit; offset defaults to 0. On invalid
+// values it writes a 400 response and reports false.
+func ParsePagination(ctx *gin.Context, defaultLimit, maxLimit int) (int32, int32, bool) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 {
+		Error(ctx, http.StatusBadRequest, "limit must be a positive integer")
+		return 0, 0, false
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		Error(ctx, http.StatusBadRequest, "offset must be a non-negative integer")
+		return 0, 0, false
+	}
+
+	return int32(limit), int32(offset), true
+}
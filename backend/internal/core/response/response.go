@@ -1,15 +1,23 @@
 package response
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+
+	db "hkers-backend/internal/sqlc/generated"
 )
 
 // JWTManager defines the interface for JWT token management
 type JWTManager interface {
-	GenerateToken(userID int32, email, oidcSub, username string, isActive bool) (string, error)
-	ValidateToken(tokenString string) (*JWTClaims, error)
-	RefreshToken(oldToken string) (string, error)
+	GenerateToken(ctx context.Context, userID int32, email, oidcSub, username string, isActive bool) (string, error)
+	ValidateToken(ctx context.Context, tokenString string) (*JWTClaims, error)
+	RevokeToken(ctx context.Context, claims *JWTClaims) error
+	RevokeUserTokens(ctx context.Context, userID int32) error
+	TokenDuration() time.Duration
 }
 
 // JWTClaims represents the claims in our JWT token
@@ -19,9 +27,40 @@ type JWTClaims struct {
 	OIDCSub  string `json:"oidc_sub"`  // OIDC subject identifier
 	Username string `json:"username"`  // Username
 	IsActive bool   `json:"is_active"` // Account active status
+	// Generation is the user's token generation at issue time; bumping the
+	// stored generation revokes every token issued before it.
+	Generation int64 `json:"gen"`
 	jwt.RegisteredClaims
 }
 
+var (
+	// ErrPrincipalNotFound is returned when loading the principal of a user that no longer exists
+	ErrPrincipalNotFound = errors.New("principal not found")
+	// ErrInvalidAPIKey is returned when an API key is unknown, malformed or revoked
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrRateLimited is returned when an API key has exceeded its request rate limit
+	ErrRateLimited = errors.New("rate limit exceeded")
+	// ErrAuthUnavailable is returned when a token's revocation state cannot be
+	// checked, e.g. during a Redis outage. It is not a statement about the token.
+	ErrAuthUnavailable = errors.New("authentication temporarily unavailable")
+)
+
+// Principal is the authenticated caller as currently stored in the database.
+// Unlike JWTClaims it is loaded on every request, so deactivation and role
+// changes take effect without waiting for the token to expire.
+// Service accounts authenticated by API key have APIKeyID set and no UserID.
+type Principal struct {
+	UserID         int32              `json:"id"`
+	APIKeyID       int32              `json:"api_key_id,omitempty"`
+	Email          string             `json:"email"`
+	Username       string             `json:"username"`
+	OIDCSub        string             `json:"oidc_sub"`
+	IsActive       bool               `json:"is_active"`
+	ApprovalStatus string             `json:"approval_status"`
+	Roles          []db.AppRole       `json:"roles"`
+	Permissions    []db.AppPermission `json:"permissions"`
+}
+
 // Response represents a standard API response envelope.
 type Response struct {
 	Success bool        `json:"success"`
@@ -0,0 +1,170 @@
+package devoidc
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/config"
+)
+
+// authorizePage lists the seeded users; choosing one signs in as them.
+var authorizePage = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>HKERS development sign-in</title></head>
+<body>
+<h1>Development sign-in</h1>
+<p>This provider is for local development only. Choose a user to sign in as.</p>
+<form method="post" action="authorize">
+<input type="hidden" name="client_id" value="{{.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.RedirectURI}}">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="hidden" name="nonce" value="{{.Nonce}}">
+<input type="hidden" name="code_challenge" value="{{.CodeChallenge}}">
+{{range .Users}}<p><button type="submit" name="sub" value="{{.Subject}}">{{.Username}}{{if .Email}} &lt;{{.Email}}&gt;{{end}}{{if .Role}} ({{.Role}}){{end}}</button></p>
+{{end}}</form>
+</body>
+</html>
+`))
+
+// Handler handles the development OIDC provider's HTTP requests.
+type Handler struct {
+	provider *Provider
+}
+
+// NewHandler creates a new development OIDC Handler instance.
+func NewHandler(provider *Provider) HandlerInterface {
+	return &Handler{provider: provider}
+}
+
+// Discovery serves the OpenID Provider Metadata.
+// GET /dev/oidc/.well-known/openid-configuration
+func (h *Handler) Discovery(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.provider.Discovery())
+}
+
+// JWKS serves the key that verifies ID tokens.
+// GET /dev/oidc/jwks
+func (h *Handler) JWKS(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.provider.JWKS())
+}
+
+// Authorize renders the page for choosing a seeded user.
+// GET /dev/oidc/authorize
+func (h *Handler) Authorize(ctx *gin.Context) {
+	clientID := ctx.Query("client_id")
+	redirectURI := ctx.Query("redirect_uri")
+	if err := h.provider.ValidateClient(clientID, redirectURI); err != nil {
+		ctx.String(http.StatusBadRequest, "Unknown client_id or redirect_uri")
+		return
+	}
+	if ctx.Query("response_type") != "code" {
+		ctx.String(http.StatusBadRequest, "Only response_type=code is supported")
+		return
+	}
+	if method := ctx.Query("code_challenge_method"); ctx.Query("code_challenge") != "" && method != "S256" {
+		ctx.String(http.StatusBadRequest, "Only code_challenge_method=S256 is supported")
+		return
+	}
+
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	_ = authorizePage.Execute(ctx.Writer, struct {
+		ClientID      string
+		RedirectURI   string
+		State         string
+		Nonce         string
+		CodeChallenge string
+		Users         []config.DevOIDCUser
+	}{
+		ClientID:      clientID,
+		RedirectURI:   redirectURI,
+		State:         ctx.Query("state"),
+		Nonce:         ctx.Query("nonce"),
+		CodeChallenge: ctx.Query("code_challenge"),
+		Users:         h.provider.Users(),
+	})
+}
+
+// Approve signs in as the chosen user and redirects back to the client with a code.
+// POST /dev/oidc/authorize
+func (h *Handler) Approve(ctx *gin.Context) {
+	redirectURI := ctx.PostForm("redirect_uri")
+	if err := h.provider.ValidateClient(ctx.PostForm("client_id"), redirectURI); err != nil {
+		ctx.String(http.StatusBadRequest, "Unknown client_id or redirect_uri")
+		return
+	}
+
+	code, err := h.provider.IssueCode(ctx.PostForm("sub"), redirectURI, ctx.PostForm("code_challenge"), ctx.PostForm("nonce"))
+	if err != nil {
+		if errors.Is(err, ErrUnknownUser) {
+			ctx.String(http.StatusBadRequest, "Unknown user")
+			return
+		}
+		ctx.String(http.StatusInternalServerError, "Failed to issue authorization code")
+		return
+	}
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "Invalid redirect_uri")
+		return
+	}
+	query := target.Query()
+	query.Set("code", code)
+	if state := ctx.PostForm("state"); state != "" {
+		query.Set("state", state)
+	}
+	target.RawQuery = query.Encode()
+
+	ctx.Redirect(http.StatusFound, target.String())
+}
+
+// Token exchanges an authorization code for an ID token. Errors use the
+// OAuth 2.0 error format (RFC 6749 section 5.2) expected by clients.
+// POST /dev/oidc/token
+func (h *Handler) Token(ctx *gin.Context) {
+	clientID, clientSecret, ok := ctx.Request.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = ctx.PostForm("client_id"), ctx.PostForm("client_secret")
+	}
+	if err := h.provider.AuthenticateClient(clientID, clientSecret); err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		return
+	}
+
+	if ctx.PostForm("grant_type") != "authorization_code" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+
+	idToken, err := h.provider.RedeemCode(ctx.PostForm("code"), ctx.PostForm("redirect_uri"), ctx.PostForm("code_verifier"))
+	if err != nil {
+		if errors.Is(err, ErrInvalidGrant) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	accessToken, err := randomString(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int64(idTokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
@@ -0,0 +1,21 @@
+package devoidc
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrincipalInvalidator drops cached user principals after seeded users change
+type PrincipalInvalidator interface {
+	InvalidatePrincipal(ctx context.Context, userID int32) error
+}
+
+// HandlerInterface defines the interface for development OIDC provider HTTP handlers
+type HandlerInterface interface {
+	Discovery(ctx *gin.Context)
+	JWKS(ctx *gin.Context)
+	Authorize(ctx *gin.Context)
+	Approve(ctx *gin.Context)
+	Token(ctx *gin.Context)
+}
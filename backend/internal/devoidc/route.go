@@ -0,0 +1,19 @@
+package devoidc
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDevOIDCRoutes registers the development identity provider under /dev/oidc.
+func RegisterDevOIDCRoutes(router gin.IRouter, provider *Provider) {
+	h := NewHandler(provider)
+
+	dev := router.Group(MountPath)
+	{
+		dev.GET("/.well-known/openid-configuration", h.Discovery) // Provider metadata
+		dev.GET("/jwks", h.JWKS)                                  // ID token verification key
+		dev.GET("/authorize", h.Authorize)                        // Page to pick a seeded user
+		dev.POST("/authorize", h.Approve)                         // Redirects back with an authorization code
+		dev.POST("/token", h.Token)                               // Exchanges the code for an ID token
+	}
+}
@@ -0,0 +1,395 @@
+package devoidc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hkers-backend/internal/auth"
+	"hkers-backend/internal/config"
+	"hkers-backend/internal/core/dbtx"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	ErrReleaseMode   = errors.New("the development OIDC provider cannot run with GIN_MODE=release")
+	ErrInvalidClient = errors.New("invalid client credentials")
+	ErrInvalidGrant  = errors.New("invalid or expired authorization code")
+	ErrUnknownUser   = errors.New("unknown development user")
+)
+
+const (
+	// ProviderName is the registry name of the development provider, as in /auth/login/dev.
+	ProviderName = "dev"
+
+	// MountPath is where the provider's routes are served on the main router.
+	MountPath = "/dev/oidc"
+
+	clientID     = "hkers-dev"
+	codeLifetime = time.Minute
+	idTokenTTL   = 5 * time.Minute
+)
+
+// authCode is an issued, not yet redeemed authorization code.
+type authCode struct {
+	user          config.DevOIDCUser
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	expiresAt     time.Time
+}
+
+// Provider is an in-process OpenID Connect provider for development and tests.
+// It signs in any of its seeded users without a password, so it must never run
+// in production; NewProvider refuses to start in release mode.
+type Provider struct {
+	issuer       string
+	clientSecret string
+	redirectURL  string
+	users        []config.DevOIDCUser
+	keyID        string
+	privateKey   ed25519.PrivateKey
+	server       *http.Server
+	loopback     *url.URL // Where server listens, e.g. http://127.0.0.1:41234
+
+	mu    sync.Mutex
+	codes map[string]authCode
+}
+
+// NewProvider creates the development provider with a fresh signing key and client secret.
+func NewProvider(cfg *config.DevOIDCConfig) (*Provider, error) {
+	if gin.Mode() == gin.ReleaseMode {
+		return nil, ErrReleaseMode
+	}
+	if _, err := url.Parse(cfg.Issuer); err != nil || cfg.Issuer == "" {
+		return nil, fmt.Errorf("invalid DEV_OIDC_ISSUER %q", cfg.Issuer)
+	}
+	if len(cfg.Users) == 0 {
+		return nil, errors.New("DEV_OIDC_USERS must list at least one user")
+	}
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := randomString(8)
+	if err != nil {
+		return nil, err
+	}
+	clientSecret, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{
+		issuer:       strings.TrimSuffix(cfg.Issuer, "/"),
+		clientSecret: clientSecret,
+		redirectURL:  cfg.RedirectURL,
+		users:        cfg.Users,
+		keyID:        "dev-" + keyID,
+		privateKey:   privateKey,
+		codes:        make(map[string]authCode),
+	}
+
+	// A private engine on a loopback listener serves the provider's own requests
+	// (see HTTPClient), so they do not depend on the issuer URL being reachable
+	engine := gin.New()
+	RegisterDevOIDCRoutes(engine, p)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen for development OIDC requests: %w", err)
+	}
+	p.loopback = &url.URL{Scheme: "http", Host: listener.Addr().String()}
+	p.server = &http.Server{Handler: engine, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := p.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("development OIDC provider: %v", err)
+		}
+	}()
+
+	return p, nil
+}
+
+// Close stops the loopback listener. It does nothing on a nil provider, so
+// callers can close the provider whether or not it is enabled.
+func (p *Provider) Close() error {
+	if p == nil {
+		return nil
+	}
+	return p.server.Close()
+}
+
+// Issuer returns the provider's public issuer URL.
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// Users returns the identities offered on the authorize page.
+func (p *Provider) Users() []config.DevOIDCUser {
+	return p.users
+}
+
+// OIDCConfig returns the client configuration for registering the provider with auth.
+func (p *Provider) OIDCConfig() config.OIDCConfig {
+	return config.OIDCConfig{
+		Name:           ProviderName,
+		Issuer:         p.issuer,
+		ClientID:       clientID,
+		ClientSecret:   p.clientSecret,
+		RedirectURL:    p.redirectURL,
+		Scopes:         []string{"openid", "profile", "email"},
+		UsernameClaims: []string{"preferred_username"},
+		EmailClaim:     "email",
+	}
+}
+
+// HTTPClient returns a client that sends requests for the issuer to the
+// provider's loopback listener, so discovery, JWKS and token calls work before
+// (and without) the main server listening. Other requests go to the network as usual.
+func (p *Provider) HTTPClient() *http.Client {
+	issuerURL, _ := url.Parse(p.issuer)
+	return &http.Client{Transport: &loopbackTransport{issuer: issuerURL, loopback: p.loopback}}
+}
+
+// Discovery returns the OpenID Provider Metadata document.
+func (p *Provider) Discovery() gin.H {
+	return gin.H{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{jwt.SigningMethodEdDSA.Alg()},
+		"scopes_supported":                      []string{"openid", "profile", "email"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported":                      []string{"sub", "name", "preferred_username", "email", "email_verified", "nonce"},
+	}
+}
+
+// JWKS returns the public key that verifies issued ID tokens.
+func (p *Provider) JWKS() auth.JWKS {
+	return auth.JWKS{Keys: []auth.JWK{{
+		Kty: "OKP",
+		Use: "sig",
+		Alg: jwt.SigningMethodEdDSA.Alg(),
+		Kid: p.keyID,
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(p.privateKey.Public().(ed25519.PublicKey)),
+	}}}
+}
+
+// ValidateClient checks an authorization request's client and redirect URI.
+func (p *Provider) ValidateClient(id, redirectURI string) error {
+	if id != clientID || redirectURI != p.redirectURL {
+		return ErrInvalidClient
+	}
+	return nil
+}
+
+// AuthenticateClient checks the client credentials presented to the token endpoint.
+func (p *Provider) AuthenticateClient(id, secret string) error {
+	if id != clientID || subtle.ConstantTimeCompare([]byte(secret), []byte(p.clientSecret)) != 1 {
+		return ErrInvalidClient
+	}
+	return nil
+}
+
+// IssueCode signs in the user with the given subject and returns a single-use authorization code.
+func (p *Provider) IssueCode(subject, redirectURI, codeChallenge, nonce string) (string, error) {
+	user, ok := p.user(subject)
+	if !ok {
+		return "", ErrUnknownUser
+	}
+
+	code, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pruneCodes()
+	p.codes[code] = authCode{
+		user:          user,
+		redirectURI:   redirectURI,
+		codeChallenge: codeChallenge,
+		nonce:         nonce,
+		expiresAt:     time.Now().Add(codeLifetime),
+	}
+	return code, nil
+}
+
+// RedeemCode exchanges an authorization code for a signed ID token.
+// The code is consumed even if the PKCE verifier or redirect URI is wrong.
+func (p *Provider) RedeemCode(code, redirectURI, codeVerifier string) (string, error) {
+	p.mu.Lock()
+	issued, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	if !ok || time.Now().After(issued.expiresAt) || issued.redirectURI != redirectURI {
+		return "", ErrInvalidGrant
+	}
+	if issued.codeChallenge != "" {
+		sum := sha256.Sum256([]byte(codeVerifier))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != issued.codeChallenge {
+			return "", ErrInvalidGrant
+		}
+	}
+
+	return p.signIDToken(issued.user, issued.nonce)
+}
+
+// SeedUsers makes sure every development user exists, is active and holds its
+// configured role, so that logging in as them yields a JWT straight away.
+func (p *Provider) SeedUsers(ctx context.Context, pool *pgxpool.Pool, principals PrincipalInvalidator) error {
+	runner := dbtx.New(pool)
+
+	for _, devUser := range p.users {
+		var userID int32
+		err := runner.InTx(ctx, func(q *db.Queries) error {
+			oidcSub := auth.NamespacedSubject(p.issuer, devUser.Subject)
+			user, err := q.GetUserByOIDCSub(ctx, oidcSub)
+			if errors.Is(err, pgx.ErrNoRows) {
+				user, err = q.CreateUserFromOIDC(ctx, db.CreateUserFromOIDCParams{
+					OidcSub:  oidcSub,
+					Username: devUser.Username,
+					Email:    pgtype.Text{String: devUser.Email, Valid: devUser.Email != ""},
+				})
+			}
+			if err != nil {
+				return fmt.Errorf("seed development user %q: %w", devUser.Subject, err)
+			}
+			userID = user.ID
+
+			if !user.IsActive.Bool {
+				if _, err := q.ActivateUser(ctx, user.ID); err != nil {
+					return fmt.Errorf("activate development user %q: %w", devUser.Subject, err)
+				}
+			}
+
+			if devUser.Role != "" {
+				role, err := q.GetRoleByName(ctx, db.AppRole(devUser.Role))
+				if err != nil {
+					return fmt.Errorf("development user %q: role %q: %w", devUser.Subject, devUser.Role, err)
+				}
+				// ErrNoRows means the role was already assigned
+				if _, err := q.AssignRoleToUser(ctx, db.AssignRoleToUserParams{
+					UserID: user.ID,
+					RoleID: role.ID,
+				}); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+					return fmt.Errorf("assign role to development user %q: %w", devUser.Subject, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := principals.InvalidatePrincipal(ctx, userID); err != nil {
+			log.Printf("devoidc: failed to invalidate principal cache for user %d: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+// signIDToken issues an ID token for the user, valid for idTokenTTL.
+func (p *Provider) signIDToken(user config.DevOIDCUser, nonce string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                p.issuer,
+		"sub":                user.Subject,
+		"aud":                clientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(idTokenTTL).Unix(),
+		"name":               user.Username,
+		"preferred_username": user.Username,
+	}
+	if user.Email != "" {
+		claims["email"] = user.Email
+		claims["email_verified"] = true
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = p.keyID
+	return token.SignedString(p.privateKey)
+}
+
+func (p *Provider) user(subject string) (config.DevOIDCUser, bool) {
+	for _, user := range p.users {
+		if user.Subject == subject {
+			return user, true
+		}
+	}
+	return config.DevOIDCUser{}, false
+}
+
+// pruneCodes drops expired codes. The caller must hold p.mu.
+func (p *Provider) pruneCodes() {
+	now := time.Now()
+	for code, issued := range p.codes {
+		if now.After(issued.expiresAt) {
+			delete(p.codes, code)
+		}
+	}
+}
+
+// loopbackTransport routes requests under the issuer URL to the provider's
+// loopback listener, rewriting the issuer path to MountPath.
+type loopbackTransport struct {
+	issuer   *url.URL
+	loopback *url.URL
+}
+
+func (t *loopbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.issuer == nil || req.URL.Host != t.issuer.Host || !strings.HasPrefix(req.URL.Path, t.issuer.Path) {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+
+	inner := req.Clone(req.Context())
+	inner.URL.Scheme = t.loopback.Scheme
+	inner.URL.Host = t.loopback.Host
+	inner.URL.Path = MountPath + strings.TrimPrefix(req.URL.Path, t.issuer.Path)
+	inner.URL.RawPath = ""
+	inner.Host = ""
+
+	resp, err := http.DefaultTransport.RoundTrip(inner)
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = req
+	return resp, nil
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,73 @@
+package donation
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Delivery codes are eight characters drawn from codeAlphabet, written as
+// "XXXX-XXXX". The first seven characters are random and the last is a check
+// character, so most typos are caught before the database is queried.
+const (
+	// codeAlphabet omits characters that are easily confused when read aloud
+	// or handwritten: 0/O, 1/I/L.
+	codeAlphabet  = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+	codeRandomLen = 7
+	codeGroupSize = 4
+	codeSeparator = "-"
+)
+
+var ErrInvalidDeliveryCode = errors.New("invalid delivery code")
+
+var codeAlphabetSize = big.NewInt(int64(len(codeAlphabet)))
+
+// newDeliveryCode returns a random, checksummed delivery code in display form.
+func newDeliveryCode() (string, error) {
+	raw := make([]byte, codeRandomLen, codeRandomLen+1)
+	for i := range raw {
+		n, err := rand.Int(rand.Reader, codeAlphabetSize)
+		if err != nil {
+			return "", err
+		}
+		raw[i] = codeAlphabet[n.Int64()]
+	}
+	raw = append(raw, checkCharacter(raw))
+	return formatDeliveryCode(string(raw)), nil
+}
+
+// NormalizeDeliveryCode canonicalises user input (case, spaces, separators) and
+// verifies the check character. It returns the code in its stored display form.
+func NormalizeDeliveryCode(input string) (string, error) {
+	raw := strings.ToUpper(input)
+	raw = strings.NewReplacer(" ", "", "-", "", "_", "").Replace(raw)
+	if len(raw) != codeRandomLen+1 {
+		return "", ErrInvalidDeliveryCode
+	}
+	for i := 0; i < len(raw); i++ {
+		if strings.IndexByte(codeAlphabet, raw[i]) < 0 {
+			return "", ErrInvalidDeliveryCode
+		}
+	}
+	if checkCharacter([]byte(raw[:codeRandomLen])) != raw[codeRandomLen] {
+		return "", ErrInvalidDeliveryCode
+	}
+	return formatDeliveryCode(raw), nil
+}
+
+// checkCharacter computes a position-weighted checksum of the seven random
+// characters modulo the (prime) alphabet size. It detects every single-character
+// substitution and every transposition of two different adjacent characters,
+// including the seventh character and the check character.
+func checkCharacter(raw []byte) byte {
+	sum := 0
+	for i, c := range raw {
+		sum += (i + 1) * strings.IndexByte(codeAlphabet, c)
+	}
+	return codeAlphabet[sum%len(codeAlphabet)]
+}
+
+func formatDeliveryCode(raw string) string {
+	return raw[:codeGroupSize] + codeSeparator + raw[codeGroupSize:]
+}
@@ -0,0 +1,226 @@
+package donation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+)
+
+// Handler handles donation HTTP requests.
+type Handler struct {
+	donationService ServiceInterface
+}
+
+// NewHandler creates a new donation Handler instance.
+func NewHandler(donationService ServiceInterface) HandlerInterface {
+	return &Handler{
+		donationService: donationService,
+	}
+}
+
+// createDonationRequest is the body accepted when registering a donation.
+// Supplies must be a non-empty JSON object or array, e.g. {"water": 100}.
+type createDonationRequest struct {
+	StationID         int32           `json:"station_id" binding:"required,gt=0"`
+	Supplies          json.RawMessage `json:"supplies" binding:"required"`
+	EstimatedDelivery *time.Time      `json:"estimated_delivery"`
+}
+
+// updateStatusRequest is the body accepted when changing a donation's status.
+type updateStatusRequest struct {
+	Status    string   `json:"status" binding:"required"`
+	Note      *string  `json:"note" binding:"omitempty,max=2000"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+}
+
+// CreateDonation registers a donation from the authenticated user.
+// POST /api/v1/donations
+func (h *Handler) CreateDonation(ctx *gin.Context) {
+	var req createDonationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if !isNonEmptyContainer(req.Supplies) {
+		response.Error(ctx, http.StatusBadRequest, "supplies must be a non-empty JSON object or array")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		response.Error(ctx, http.StatusUnauthorized, "Missing user in token")
+		return
+	}
+
+	donation, err := h.donationService.CreateDonation(ctx.Request.Context(), userID, CreateDonationInput{
+		StationID:         req.StationID,
+		Supplies:          req.Supplies,
+		EstimatedDelivery: req.EstimatedDelivery,
+	})
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to create donation")
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, donation)
+}
+
+// GetDonationByCode returns the donation with the given delivery code.
+// GET /api/v1/donations/by-code/:code
+func (h *Handler) GetDonationByCode(ctx *gin.Context) {
+	donation, err := h.donationService.GetDonationByCode(ctx.Request.Context(), ctx.Param("code"))
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to get donation")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, donation)
+}
+
+// GetDonationQRCode renders a donation's delivery code as a QR image.
+// GET /api/v1/donations/by-code/:code/qr?format=png|svg&size=
+func (h *Handler) GetDonationQRCode(ctx *gin.Context) {
+	format := strings.ToLower(ctx.DefaultQuery("format", QRFormatPNG))
+	if format != QRFormatPNG && format != QRFormatSVG {
+		response.Error(ctx, http.StatusBadRequest, "format must be png or svg")
+		return
+	}
+
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", strconv.Itoa(DefaultQRSize)))
+	if err != nil || size <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "size must be a positive integer")
+		return
+	}
+	if size > MaxQRSize {
+		size = MaxQRSize
+	}
+
+	donation, err := h.donationService.GetDonationByCode(ctx.Request.Context(), ctx.Param("code"))
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to get donation")
+		return
+	}
+
+	image, contentType, err := RenderQRCode(donation.DeliveryCode, format, size)
+	if err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to render QR code")
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, image)
+}
+
+// UpdateStatus moves a donation to a new status if the lifecycle allows it.
+// PATCH /api/v1/donations/:id/status
+func (h *Handler) UpdateStatus(ctx *gin.Context) {
+	donationID, ok := parseIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req updateStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	status, err := ParseStatus(req.Status)
+	if err != nil {
+		response.Error(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		response.Error(ctx, http.StatusUnauthorized, "Missing user in token")
+		return
+	}
+
+	donation, err := h.donationService.UpdateStatus(ctx.Request.Context(), donationID, userID, StatusChangeInput{
+		Status:    status,
+		Note:      req.Note,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	})
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to update donation status")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, donation)
+}
+
+// GetStatusHistory returns a donation with its full status history.
+// GET /api/v1/donations/:id/history
+func (h *Handler) GetStatusHistory(ctx *gin.Context) {
+	donationID, ok := parseIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	donation, history, err := h.donationService.GetStatusHistory(ctx.Request.Context(), donationID)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to get donation history")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"donation": donation,
+		"history":  history,
+	})
+}
+
+// writeServiceError maps donation service errors to HTTP responses.
+func writeServiceError(ctx *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrInvalidDeliveryCode), errors.Is(err, ErrInvalidStatus), errors.Is(err, ErrInvalidLocation):
+		response.Error(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrDonationNotFound), errors.Is(err, ErrStationNotFound):
+		response.Error(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrStatusConflict):
+		response.Error(ctx, http.StatusConflict, err.Error())
+	default:
+		response.Error(ctx, http.StatusInternalServerError, fallback)
+	}
+}
+
+// parseIDParam parses the :id path parameter, writing a 400 response on failure.
+func parseIDParam(ctx *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid id")
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// isNonEmptyContainer reports whether raw is a JSON object or array with at least one element.
+func isNonEmptyContainer(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+
+	var container interface{}
+	if err := json.Unmarshal(trimmed, &container); err != nil {
+		return false
+	}
+	switch v := container.(type) {
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	default:
+		return false
+	}
+}
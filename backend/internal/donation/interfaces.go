@@ -0,0 +1,24 @@
+package donation
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceInterface defines the interface for donation services
+type ServiceInterface interface {
+	CreateDonation(ctx context.Context, donorID int32, input CreateDonationInput) (*Donation, error)
+	GetDonationByCode(ctx context.Context, code string) (*Donation, error)
+	UpdateStatus(ctx context.Context, donationID, actorID int32, input StatusChangeInput) (*Donation, error)
+	GetStatusHistory(ctx context.Context, donationID int32) (*Donation, []StatusHistoryEntry, error)
+}
+
+// HandlerInterface defines the interface for donation HTTP handlers
+type HandlerInterface interface {
+	CreateDonation(ctx *gin.Context)
+	GetDonationByCode(ctx *gin.Context)
+	GetDonationQRCode(ctx *gin.Context)
+	UpdateStatus(ctx *gin.Context)
+	GetStatusHistory(ctx *gin.Context)
+}
@@ -0,0 +1,58 @@
+package donation
+
+import (
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QR output formats supported by RenderQRCode.
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+
+	DefaultQRSize = 256
+	MaxQRSize     = 1024
+)
+
+// RenderQRCode encodes a delivery code as a QR image in the requested format,
+// returning the image bytes and their content type.
+func RenderQRCode(code, format string, size int) ([]byte, string, error) {
+	qr, err := qrcode.New(code, qrcode.Medium)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case QRFormatPNG:
+		png, err := qr.PNG(size)
+		if err != nil {
+			return nil, "", err
+		}
+		return png, "image/png", nil
+	case QRFormatSVG:
+		return renderSVG(qr.Bitmap(), size), "image/svg+xml", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported QR format %q", format)
+	}
+}
+
+// renderSVG draws a QR bitmap (quiet zone included) as a single SVG path.
+func renderSVG(bitmap [][]bool, size int) []byte {
+	modules := len(bitmap)
+
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="%s"/></svg>`,
+		size, size, modules, modules, path.String())
+	return []byte(svg)
+}
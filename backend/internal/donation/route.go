@@ -0,0 +1,25 @@
+package donation
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// RegisterDonationRoutes registers donation routes on the given router.
+func RegisterDonationRoutes(router *gin.Engine, donationSvc ServiceInterface, jwtManager response.JWTManager) {
+	h := NewHandler(donationSvc)
+
+	// Donation routes - require JWT authentication
+	donations := router.Group("/api/v1/donations")
+	donations.Use(middleware.JWTAuth(jwtManager))
+	{
+		donations.POST("", middleware.RequirePermission(db.AppPermissionCreateDonations), h.CreateDonation)
+		donations.GET("/by-code/:code", middleware.RequirePermission(db.AppPermissionReadDonations), h.GetDonationByCode)
+		donations.GET("/by-code/:code/qr", middleware.RequirePermission(db.AppPermissionReadDonations), h.GetDonationQRCode)
+		donations.PATCH("/:id/status", middleware.RequirePermission(db.AppPermissionUpdateDonations), h.UpdateStatus)
+		donations.GET("/:id/history", middleware.RequirePermission(db.AppPermissionReadDonations), h.GetStatusHistory)
+	}
+}
@@ -0,0 +1,281 @@
+package donation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/geo"
+	"hkers-backend/internal/core/pgerr"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// maxCodeAttempts bounds how often a colliding delivery code is regenerated.
+const maxCodeAttempts = 5
+
+var (
+	ErrDonationNotFound = errors.New("donation not found")
+	ErrStationNotFound  = errors.New("station not found")
+	ErrCodeExhausted    = errors.New("could not allocate a unique delivery code")
+	ErrInvalidLocation  = errors.New("latitude and longitude must be given together")
+)
+
+// Donation is the API representation of a donation with its supplies as raw JSON.
+type Donation struct {
+	ID                int32               `json:"id"`
+	DonorID           pgtype.Int4         `json:"donor_id"`
+	StationID         pgtype.Int4         `json:"station_id"`
+	Supplies          json.RawMessage     `json:"supplies"`
+	DeliveryCode      string              `json:"delivery_code"`
+	Status            db.DonationStatus   `json:"status"`
+	NextStatuses      []db.DonationStatus `json:"next_statuses"`
+	EstimatedDelivery pgtype.Timestamptz  `json:"estimated_delivery"`
+	CreatedAt         pgtype.Timestamptz  `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz  `json:"updated_at"`
+}
+
+// StatusHistoryEntry is one recorded status change of a donation.
+// FromStatus is nil for the entry written when the donation was registered.
+type StatusHistoryEntry struct {
+	ID         int32              `json:"id"`
+	FromStatus *db.DonationStatus `json:"from_status"`
+	ToStatus   db.DonationStatus  `json:"to_status"`
+	ChangedBy  pgtype.Int4        `json:"changed_by"`
+	Latitude   *float64           `json:"latitude"`
+	Longitude  *float64           `json:"longitude"`
+	Note       pgtype.Text        `json:"note"`
+	ChangedAt  pgtype.Timestamptz `json:"changed_at"`
+}
+
+// CreateDonationInput holds the fields a donor supplies when registering a donation.
+type CreateDonationInput struct {
+	StationID         int32
+	Supplies          json.RawMessage
+	EstimatedDelivery *time.Time
+}
+
+// StatusChangeInput describes a requested status change. The location is
+// optional, but latitude and longitude must be supplied together.
+type StatusChangeInput struct {
+	Status    db.DonationStatus
+	Note      *string
+	Latitude  *float64
+	Longitude *float64
+}
+
+// Service handles donation business logic.
+type Service struct {
+	queries *db.Queries
+	runner  *dbtx.Runner
+}
+
+// NewService creates a new donation service instance.
+func NewService(pool *pgxpool.Pool) *Service {
+	return &Service{
+		queries: db.New(pool),
+		runner:  dbtx.New(pool),
+	}
+}
+
+// CreateDonation registers a pending donation to a station and assigns it a
+// fresh delivery code, regenerating the code if it collides with an existing one.
+func (s *Service) CreateDonation(ctx context.Context, donorID int32, input CreateDonationInput) (*Donation, error) {
+	if _, err := s.queries.GetStationByID(ctx, input.StationID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStationNotFound
+		}
+		return nil, err
+	}
+
+	estimated := pgtype.Timestamptz{}
+	if input.EstimatedDelivery != nil {
+		estimated = pgtype.Timestamptz{Time: *input.EstimatedDelivery, Valid: true}
+	}
+
+	params := db.CreateDonationParams{
+		DonorID:           pgtype.Int4{Int32: donorID, Valid: donorID > 0},
+		StationID:         pgtype.Int4{Int32: input.StationID, Valid: true},
+		Supplies:          input.Supplies,
+		Status:            db.DonationStatusPending,
+		EstimatedDelivery: estimated,
+	}
+
+	for attempt := 0; attempt < maxCodeAttempts; attempt++ {
+		code, err := newDeliveryCode()
+		if err != nil {
+			return nil, err
+		}
+		params.DeliveryCode = code
+
+		row, err := s.insertDonation(ctx, params)
+		switch {
+		case err == nil:
+			return toDonation(row), nil
+		case pgerr.IsUniqueViolation(err):
+			continue
+		case pgerr.IsForeignKeyViolation(err):
+			return nil, ErrStationNotFound
+		default:
+			return nil, err
+		}
+	}
+	return nil, ErrCodeExhausted
+}
+
+// insertDonation creates the donation and its initial history entry in one
+// transaction. Each attempt uses its own transaction because a unique
+// violation aborts the transaction it happens in.
+func (s *Service) insertDonation(ctx context.Context, params db.CreateDonationParams) (db.Donation, error) {
+	return dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.Donation, error) {
+		row, err := q.CreateDonation(ctx, params)
+		if err != nil {
+			return db.Donation{}, err
+		}
+
+		if _, err := q.CreateDonationStatusHistory(ctx, db.CreateDonationStatusHistoryParams{
+			DonationID: row.ID,
+			ToStatus:   row.Status,
+			ChangedBy:  params.DonorID,
+		}); err != nil {
+			return db.Donation{}, err
+		}
+		return row, nil
+	})
+}
+
+// GetDonationByCode looks up a donation by its delivery code. The code is
+// normalized first, so lowercase input and missing separators are accepted.
+func (s *Service) GetDonationByCode(ctx context.Context, code string) (*Donation, error) {
+	normalized, err := NormalizeDeliveryCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := s.queries.GetDonationByDeliveryCode(ctx, normalized)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDonationNotFound
+		}
+		return nil, err
+	}
+	return toDonation(row), nil
+}
+
+// UpdateStatus moves a donation along its lifecycle and records the change,
+// with the acting user, optional location and note, in the status history.
+func (s *Service) UpdateStatus(ctx context.Context, donationID, actorID int32, input StatusChangeInput) (*Donation, error) {
+	if (input.Latitude == nil) != (input.Longitude == nil) {
+		return nil, ErrInvalidLocation
+	}
+
+	updated, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.Donation, error) {
+		current, err := q.GetDonationByID(ctx, donationID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.Donation{}, ErrDonationNotFound
+			}
+			return db.Donation{}, err
+		}
+		if !CanTransition(current.Status, input.Status) {
+			return db.Donation{}, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current.Status, input.Status)
+		}
+
+		updated, err := q.TransitionDonationStatus(ctx, db.TransitionDonationStatusParams{
+			ToStatus:   input.Status,
+			ID:         donationID,
+			FromStatus: current.Status,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.Donation{}, ErrStatusConflict
+			}
+			return db.Donation{}, err
+		}
+
+		history := db.CreateDonationStatusHistoryParams{
+			DonationID: donationID,
+			FromStatus: db.NullDonationStatus{DonationStatus: current.Status, Valid: true},
+			ToStatus:   input.Status,
+			ChangedBy:  pgtype.Int4{Int32: actorID, Valid: actorID > 0},
+		}
+		if input.Latitude != nil {
+			history.Lat = pgtype.Float8{Float64: *input.Latitude, Valid: true}
+			history.Lng = pgtype.Float8{Float64: *input.Longitude, Valid: true}
+		}
+		if input.Note != nil {
+			history.Note = pgtype.Text{String: *input.Note, Valid: true}
+		}
+		if _, err := q.CreateDonationStatusHistory(ctx, history); err != nil {
+			return db.Donation{}, err
+		}
+		return updated, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDonation(updated), nil
+}
+
+// GetStatusHistory returns a donation and its status changes, oldest first.
+func (s *Service) GetStatusHistory(ctx context.Context, donationID int32) (*Donation, []StatusHistoryEntry, error) {
+	row, err := s.queries.GetDonationByID(ctx, donationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrDonationNotFound
+		}
+		return nil, nil, err
+	}
+
+	rows, err := s.queries.ListDonationStatusHistory(ctx, donationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([]StatusHistoryEntry, 0, len(rows))
+	for _, h := range rows {
+		entry := StatusHistoryEntry{
+			ID:        h.ID,
+			ToStatus:  h.ToStatus,
+			ChangedBy: h.ChangedBy,
+			Note:      h.Note,
+			ChangedAt: h.ChangedAt,
+		}
+		if h.FromStatus.Valid {
+			from := h.FromStatus.DonationStatus
+			entry.FromStatus = &from
+		}
+		if h.Location != nil {
+			point, err := geo.DecodePoint(h.Location)
+			if err != nil {
+				return nil, nil, err
+			}
+			entry.Latitude = &point.Latitude
+			entry.Longitude = &point.Longitude
+		}
+		entries = append(entries, entry)
+	}
+	return toDonation(row), entries, nil
+}
+
+// toDonation converts a sqlc row into the API representation.
+func toDonation(row db.Donation) *Donation {
+	return &Donation{
+		ID:                row.ID,
+		DonorID:           row.DonorID,
+		StationID:         row.StationID,
+		Supplies:          json.RawMessage(row.Supplies),
+		DeliveryCode:      row.DeliveryCode,
+		Status:            row.Status,
+		NextStatuses:      NextStatuses(row.Status),
+		EstimatedDelivery: row.EstimatedDelivery,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+	}
+}
@@ -0,0 +1,63 @@
+package donation
+
+import (
+	"errors"
+	"strings"
+
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	ErrInvalidStatus     = errors.New("status must be one of pending, in_transit, delivered, partially_delivered, cancelled, rejected")
+	ErrInvalidTransition = errors.New("status transition is not allowed")
+	ErrStatusConflict    = errors.New("donation status changed concurrently, reload and retry")
+)
+
+// allowedTransitions is the donation lifecycle. Statuses without an entry are terminal.
+var allowedTransitions = map[db.DonationStatus][]db.DonationStatus{
+	db.DonationStatusPending: {
+		db.DonationStatusInTransit,
+		db.DonationStatusCancelled,
+		db.DonationStatusRejected,
+	},
+	db.DonationStatusInTransit: {
+		db.DonationStatusDelivered,
+		db.DonationStatusPartiallyDelivered,
+		db.DonationStatusCancelled,
+		db.DonationStatusRejected,
+	},
+	db.DonationStatusPartiallyDelivered: {
+		db.DonationStatusDelivered,
+	},
+}
+
+// ParseStatus validates a client-supplied donation status (case-insensitive).
+func ParseStatus(raw string) (db.DonationStatus, error) {
+	status := db.DonationStatus(strings.ToLower(strings.TrimSpace(raw)))
+	switch status {
+	case db.DonationStatusPending, db.DonationStatusInTransit, db.DonationStatusDelivered,
+		db.DonationStatusPartiallyDelivered, db.DonationStatusCancelled, db.DonationStatusRejected:
+		return status, nil
+	default:
+		return "", ErrInvalidStatus
+	}
+}
+
+// CanTransition reports whether a donation may move from one status to another.
+func CanTransition(from, to db.DonationStatus) bool {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextStatuses lists the statuses reachable from the given one.
+func NextStatuses(from db.DonationStatus) []db.DonationStatus {
+	next := allowedTransitions[from]
+	if next == nil {
+		return []db.DonationStatus{}
+	}
+	return next
+}
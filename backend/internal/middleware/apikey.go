@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+)
+
+// APIKeyHeader carries a service account API key in place of a bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyAuthenticatorKey is the context key used by the API key middleware.
+const apiKeyAuthenticatorKey = "api_key_authenticator"
+
+// APIKeyAuthenticator resolves the service account behind an API key.
+// It returns response.ErrInvalidAPIKey for unknown or revoked keys and
+// response.ErrRateLimited when the key has used up its rate limit.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*response.Principal, error)
+}
+
+// APIKeys makes the given authenticator available to JWTAuth for each request.
+// Without it, requests carrying an X-API-Key header are rejected.
+func APIKeys(authenticator APIKeyAuthenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(apiKeyAuthenticatorKey, authenticator)
+		ctx.Next()
+	}
+}
+
+// authenticateAPIKey resolves the principal for an API key, setting Retry-After
+// when the key is rate limited.
+func authenticateAPIKey(ctx *gin.Context, key string) (*response.Principal, *permissionError) {
+	value, exists := ctx.Get(apiKeyAuthenticatorKey)
+	authenticator, ok := value.(APIKeyAuthenticator)
+	if !exists || !ok {
+		return nil, &permissionError{http.StatusUnauthorized, "API key authentication is not enabled"}
+	}
+
+	principal, err := authenticator.AuthenticateAPIKey(ctx.Request.Context(), key)
+	if err != nil {
+		switch {
+		case errors.Is(err, response.ErrInvalidAPIKey):
+			return nil, &permissionError{http.StatusUnauthorized, "Invalid API key"}
+		case errors.Is(err, response.ErrRateLimited):
+			// Rate limit windows are aligned to the minute
+			ctx.Header("Retry-After", strconv.Itoa(60-time.Now().Second()))
+			return nil, &permissionError{http.StatusTooManyRequests, "API key rate limit exceeded"}
+		default:
+			return nil, &permissionError{http.StatusInternalServerError, "Failed to authenticate API key"}
+		}
+	}
+	return principal, nil
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -9,9 +10,25 @@ import (
 	"hkers-backend/internal/core/response"
 )
 
-// JWTAuth is a middleware that validates JWT tokens from Authorization header
+// JWTAuth is a middleware that validates JWT tokens from Authorization header.
+// Service accounts may instead present an API key in the X-API-Key header.
 func JWTAuth(jwtManager response.JWTManager) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		// Service accounts authenticate with an API key instead of a bearer token
+		if apiKey := ctx.GetHeader(APIKeyHeader); apiKey != "" {
+			principal, authErr := authenticateAPIKey(ctx, apiKey)
+			if authErr != nil {
+				ctx.AbortWithStatusJSON(authErr.status, gin.H{
+					"success": false,
+					"error":   authErr.message,
+				})
+				return
+			}
+			setPrincipal(ctx, principal)
+			ctx.Next()
+			return
+		}
+
 		// Get Authorization header
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
@@ -41,8 +58,16 @@ func JWTAuth(jwtManager response.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// Validate token
-		claims, err := jwtManager.ValidateToken(tokenString)
+		// Validate token (signature, expiry and revocation)
+		claims, err := jwtManager.ValidateToken(ctx.Request.Context(), tokenString)
+		if errors.Is(err, response.ErrAuthUnavailable) {
+			// Revocation could not be checked; the token itself may be fine
+			ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"error":   "Authentication temporarily unavailable",
+			})
+			return
+		}
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"success": false,
@@ -51,43 +76,58 @@ func JWTAuth(jwtManager response.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// Store claims in context for use in handlers
-		ctx.Set("user_id", claims.UserID)
-		ctx.Set("email", claims.Email)
-		ctx.Set("username", claims.Username)
-		ctx.Set("oidc_sub", claims.OIDCSub)
-		ctx.Set("is_active", claims.IsActive)
+		// Load the live user so deactivation and role changes apply immediately
+		principal, loadErr := loadPrincipal(ctx, claims)
+		if loadErr != nil {
+			ctx.AbortWithStatusJSON(loadErr.status, gin.H{
+				"success": false,
+				"error":   loadErr.message,
+			})
+			return
+		}
+
+		// Store claims and principal in context for use in handlers
+		ctx.Set("claims", claims)
+		setPrincipal(ctx, principal)
 
 		ctx.Next()
 	}
 }
 
-// GetUserIDFromContext retrieves the authenticated user ID from the context
-func GetUserIDFromContext(ctx *gin.Context) (int32, bool) {
-	userID, exists := ctx.Get("user_id")
+// GetClaimsFromContext retrieves the validated token claims from the context
+func GetClaimsFromContext(ctx *gin.Context) (*response.JWTClaims, bool) {
+	claims, exists := ctx.Get("claims")
 	if !exists {
+		return nil, false
+	}
+	c, ok := claims.(*response.JWTClaims)
+	return c, ok
+}
+
+// GetUserIDFromContext retrieves the authenticated user ID from the context.
+// It reports false for service accounts, which are not users.
+func GetUserIDFromContext(ctx *gin.Context) (int32, bool) {
+	principal, ok := GetPrincipalFromContext(ctx)
+	if !ok || principal.UserID == 0 {
 		return 0, false
 	}
-	id, ok := userID.(int32)
-	return id, ok
+	return principal.UserID, true
 }
 
 // GetEmailFromContext retrieves the authenticated user email from the context
 func GetEmailFromContext(ctx *gin.Context) (string, bool) {
-	email, exists := ctx.Get("email")
-	if !exists {
+	principal, ok := GetPrincipalFromContext(ctx)
+	if !ok {
 		return "", false
 	}
-	e, ok := email.(string)
-	return e, ok
+	return principal.Email, true
 }
 
 // GetUsernameFromContext retrieves the authenticated username from the context
 func GetUsernameFromContext(ctx *gin.Context) (string, bool) {
-	username, exists := ctx.Get("username")
-	if !exists {
+	principal, ok := GetPrincipalFromContext(ctx)
+	if !ok {
 		return "", false
 	}
-	u, ok := username.(string)
-	return u, ok
+	return principal.Username, true
 }
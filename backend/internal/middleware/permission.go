@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// Context keys used by the permission middleware.
+const (
+	permissionLoaderKey = "permission_loader"
+	permissionsKey      = "permissions"
+)
+
+// PermissionLoader loads the permissions granted to a user.
+type PermissionLoader interface {
+	GetUserPermissions(ctx context.Context, userID int32) ([]db.AppPermission, error)
+}
+
+// Permissions makes the given loader available to RequirePermission for each request.
+// It is cheap to install globally: permissions are only loaded when a route asks for them.
+func Permissions(loader PermissionLoader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(permissionLoaderKey, loader)
+		ctx.Next()
+	}
+}
+
+// RequirePermission is a middleware that aborts with 403 unless the authenticated
+// user holds every listed permission. It must run after JWTAuth.
+func RequirePermission(required ...db.AppPermission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		granted, err := loadPermissions(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(err.status, gin.H{
+				"success": false,
+				"error":   err.message,
+			})
+			return
+		}
+
+		var missing []db.AppPermission
+		for _, permission := range required {
+			if _, ok := granted[permission]; !ok {
+				missing = append(missing, permission)
+			}
+		}
+
+		if len(missing) > 0 {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Insufficient permissions",
+				"details": gin.H{
+					"required": required,
+					"missing":  missing,
+				},
+			})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// HasPermission reports whether the authenticated user holds the given permission.
+// Handlers can use it for finer-grained checks than route-level RequirePermission.
+func HasPermission(ctx *gin.Context, permission db.AppPermission) bool {
+	granted, err := loadPermissions(ctx)
+	if err != nil {
+		return false
+	}
+	_, ok := granted[permission]
+	return ok
+}
+
+// permissionError carries the HTTP status for a failed permission lookup.
+type permissionError struct {
+	status  int
+	message string
+}
+
+// loadPermissions resolves the caller's permissions once per request and caches
+// the result in the gin context for subsequent checks.
+func loadPermissions(ctx *gin.Context) (map[db.AppPermission]struct{}, *permissionError) {
+	if cached, exists := ctx.Get(permissionsKey); exists {
+		if granted, ok := cached.(map[db.AppPermission]struct{}); ok {
+			return granted, nil
+		}
+	}
+
+	principal, ok := GetPrincipalFromContext(ctx)
+	if !ok {
+		return nil, &permissionError{http.StatusUnauthorized, "Authentication required"}
+	}
+
+	// Live principals already carry their permissions
+	permissions := principal.Permissions
+	if permissions == nil {
+		value, exists := ctx.Get(permissionLoaderKey)
+		loader, ok := value.(PermissionLoader)
+		if !exists || !ok {
+			return nil, &permissionError{http.StatusInternalServerError, "Permission checks are not configured"}
+		}
+
+		var err error
+		permissions, err = loader.GetUserPermissions(ctx.Request.Context(), principal.UserID)
+		if err != nil {
+			return nil, &permissionError{http.StatusInternalServerError, "Failed to load permissions"}
+		}
+	}
+
+	granted := make(map[db.AppPermission]struct{}, len(permissions))
+	for _, permission := range permissions {
+		granted[permission] = struct{}{}
+	}
+	ctx.Set(permissionsKey, granted)
+
+	return granted, nil
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/response"
+)
+
+// Context keys used by the principal middleware.
+const (
+	principalLoaderKey = "principal_loader"
+	principalKey       = "principal"
+)
+
+// PrincipalLoader loads a user's live status, roles and permissions.
+// It returns response.ErrPrincipalNotFound if the user no longer exists.
+type PrincipalLoader interface {
+	GetPrincipal(ctx context.Context, userID int32) (*response.Principal, error)
+}
+
+// Principals makes the given loader available to JWTAuth for each request.
+// Without it, JWTAuth falls back to the (possibly stale) token claims.
+func Principals(loader PrincipalLoader) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(principalLoaderKey, loader)
+		ctx.Next()
+	}
+}
+
+// GetPrincipalFromContext retrieves the authenticated principal from the context
+func GetPrincipalFromContext(ctx *gin.Context) (*response.Principal, bool) {
+	value, exists := ctx.Get(principalKey)
+	if !exists {
+		return nil, false
+	}
+	p, ok := value.(*response.Principal)
+	return p, ok
+}
+
+// setPrincipal stores the authenticated principal for handlers, and in the
+// request context so that database writes are attributed to it.
+func setPrincipal(ctx *gin.Context, principal *response.Principal) {
+	ctx.Set(principalKey, principal)
+	ctx.Request = ctx.Request.WithContext(dbtx.WithPrincipal(ctx.Request.Context(), principal))
+}
+
+// loadPrincipal resolves the caller behind validated claims. Inactive and
+// deleted users are rejected even while their token is still valid.
+func loadPrincipal(ctx *gin.Context, claims *response.JWTClaims) (*response.Principal, *permissionError) {
+	value, exists := ctx.Get(principalLoaderKey)
+	loader, ok := value.(PrincipalLoader)
+	if !exists || !ok {
+		return &response.Principal{
+			UserID:   claims.UserID,
+			Email:    claims.Email,
+			Username: claims.Username,
+			OIDCSub:  claims.OIDCSub,
+			IsActive: claims.IsActive,
+		}, nil
+	}
+
+	principal, err := loader.GetPrincipal(ctx.Request.Context(), claims.UserID)
+	if err != nil {
+		if errors.Is(err, response.ErrPrincipalNotFound) {
+			return nil, &permissionError{http.StatusUnauthorized, "User no longer exists"}
+		}
+		return nil, &permissionError{http.StatusInternalServerError, "Failed to load user"}
+	}
+	if !principal.IsActive {
+		return nil, &permissionError{http.StatusUnauthorized, "User account is not active"}
+	}
+	return principal, nil
+}
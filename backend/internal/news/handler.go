@@ -0,0 +1,246 @@
+package news
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+	maxQueryLength   = 200
+)
+
+// Handler handles news HTTP requests.
+type Handler struct {
+	newsService ServiceInterface
+}
+
+// NewHandler creates a new news Handler instance.
+func NewHandler(newsService ServiceInterface) HandlerInterface {
+	return &Handler{
+		newsService: newsService,
+	}
+}
+
+// newsRequest is the body accepted when creating or replacing a news item.
+// Without relevant_to, tags are suggested from the title and content.
+type newsRequest struct {
+	Source      string     `json:"source" binding:"required,max=255"`
+	Title       string     `json:"title" binding:"required,max=255"`
+	Content     *string    `json:"content"`
+	Url         *string    `json:"url" binding:"omitempty,max=512,url"`
+	PublishedAt *time.Time `json:"published_at"`
+	RelevantTo  *Tags      `json:"relevant_to"`
+}
+
+// ListNews returns a page of news items, newest first. With q the items are
+// full-text matches in English or Chinese, best matches first. With district
+// only items tagged with that district code are returned.
+// GET /api/v1/news?q=&source=&district=&limit=&offset=
+func (h *Handler) ListNews(ctx *gin.Context) {
+	limit, offset, ok := response.ParsePagination(ctx, defaultPageLimit, maxPageLimit)
+	if !ok {
+		return
+	}
+
+	query := ctx.Query("q")
+	if len([]rune(query)) > maxQueryLength {
+		response.Error(ctx, http.StatusBadRequest, "q must be at most "+strconv.Itoa(maxQueryLength)+" characters")
+		return
+	}
+
+	district := strings.ToUpper(strings.TrimSpace(ctx.Query("district")))
+	if district != "" && !IsDistrict(district) {
+		response.Error(ctx, http.StatusBadRequest, "Unknown district")
+		return
+	}
+
+	items, total, err := h.newsService.ListNews(ctx.Request.Context(), ListFilter{
+		Query:    query,
+		Source:   ctx.Query("source"),
+		District: district,
+	}, limit, offset)
+	if err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to list news")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"news":   items,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// GetNews returns a news item.
+// GET /api/v1/news/:id
+func (h *Handler) GetNews(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	item, err := h.newsService.GetNews(ctx.Request.Context(), id)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to get news item")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, item)
+}
+
+// CreateNews adds a news item.
+// POST /api/v1/news
+func (h *Handler) CreateNews(ctx *gin.Context) {
+	input, ok := bindNewsRequest(ctx)
+	if !ok {
+		return
+	}
+
+	item, err := h.newsService.CreateNews(ctx.Request.Context(), input)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to create news item")
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, item)
+}
+
+// UpdateNews replaces a news item.
+// PUT /api/v1/news/:id
+func (h *Handler) UpdateNews(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx)
+	if !ok {
+		return
+	}
+	input, ok := bindNewsRequest(ctx)
+	if !ok {
+		return
+	}
+
+	item, err := h.newsService.UpdateNews(ctx.Request.Context(), id, input)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to update news item")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, item)
+}
+
+// UpdateNewsTags replaces the tags of a news item. Tags set by an editor are
+// kept when the item is refreshed from its feed.
+// PUT /api/v1/news/:id/tags
+func (h *Handler) UpdateNewsTags(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx)
+	if !ok {
+		return
+	}
+	var tags Tags
+	if err := ctx.ShouldBindJSON(&tags); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	item, err := h.newsService.UpdateNewsTags(ctx.Request.Context(), id, tags)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to update news tags")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, item)
+}
+
+// ListStationNews returns a page of the news items tagged with a station or
+// its district, newest first.
+// GET /api/v1/stations/:id/news?limit=&offset=
+func (h *Handler) ListStationNews(ctx *gin.Context) {
+	stationID, ok := parseIDParam(ctx)
+	if !ok {
+		return
+	}
+	limit, offset, ok := response.ParsePagination(ctx, defaultPageLimit, maxPageLimit)
+	if !ok {
+		return
+	}
+
+	items, total, err := h.newsService.ListStationNews(ctx.Request.Context(), stationID, limit, offset)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to list station news")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"news":   items,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// DeleteNews removes a news item.
+// DELETE /api/v1/news/:id
+func (h *Handler) DeleteNews(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.newsService.DeleteNews(ctx.Request.Context(), id); err != nil {
+		writeServiceError(ctx, err, "Failed to delete news item")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"message": "News item deleted successfully",
+	})
+}
+
+// bindNewsRequest parses a news item body, writing a 400 response if it is invalid.
+func bindNewsRequest(ctx *gin.Context) (Input, bool) {
+	var req newsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return Input{}, false
+	}
+
+	return Input{
+		Source:      req.Source,
+		Title:       req.Title,
+		Content:     req.Content,
+		Url:         req.Url,
+		PublishedAt: req.PublishedAt,
+		RelevantTo:  req.RelevantTo,
+	}, true
+}
+
+func parseIDParam(ctx *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid id")
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// writeServiceError maps service errors to HTTP responses.
+func writeServiceError(ctx *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrNewsNotFound), errors.Is(err, ErrStationNotFound):
+		response.Error(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidTags):
+		response.Error(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrDuplicateNews):
+		response.Error(ctx, http.StatusConflict, err.Error())
+	default:
+		response.Error(ctx, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,29 @@
+package news
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceInterface defines the interface for news services
+type ServiceInterface interface {
+	ListNews(ctx context.Context, filter ListFilter, limit, offset int32) ([]Item, int64, error)
+	GetNews(ctx context.Context, id int32) (*Item, error)
+	CreateNews(ctx context.Context, input Input) (*Item, error)
+	UpdateNews(ctx context.Context, id int32, input Input) (*Item, error)
+	UpdateNewsTags(ctx context.Context, id int32, tags Tags) (*Item, error)
+	DeleteNews(ctx context.Context, id int32) error
+	ListStationNews(ctx context.Context, stationID int32, limit, offset int32) ([]Item, int64, error)
+}
+
+// HandlerInterface defines the interface for news HTTP handlers
+type HandlerInterface interface {
+	ListNews(ctx *gin.Context)
+	GetNews(ctx *gin.Context)
+	CreateNews(ctx *gin.Context)
+	UpdateNews(ctx *gin.Context)
+	UpdateNewsTags(ctx *gin.Context)
+	DeleteNews(ctx *gin.Context)
+	ListStationNews(ctx *gin.Context)
+}
@@ -0,0 +1,33 @@
+package news
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// RegisterNewsRoutes registers news routes on the given router.
+func RegisterNewsRoutes(router *gin.Engine, newsSvc ServiceInterface, jwtManager response.JWTManager) {
+	h := NewHandler(newsSvc)
+
+	// News routes - require JWT authentication
+	news := router.Group("/api/v1/news")
+	news.Use(middleware.JWTAuth(jwtManager))
+	{
+		news.GET("", middleware.RequirePermission(db.AppPermissionReadNews), h.ListNews)
+		news.GET("/:id", middleware.RequirePermission(db.AppPermissionReadNews), h.GetNews)
+		news.POST("", middleware.RequirePermission(db.AppPermissionCreateNews), h.CreateNews)
+		news.PUT("/:id", middleware.RequirePermission(db.AppPermissionUpdateNews), h.UpdateNews)
+		news.PUT("/:id/tags", middleware.RequirePermission(db.AppPermissionUpdateNews), h.UpdateNewsTags)
+		news.DELETE("/:id", middleware.RequirePermission(db.AppPermissionDeleteNews), h.DeleteNews)
+	}
+
+	// News tagged with a station, next to the station's own routes
+	stations := router.Group("/api/v1/stations")
+	stations.Use(middleware.JWTAuth(jwtManager))
+	{
+		stations.GET("/:id/news", middleware.RequirePermission(db.AppPermissionReadNews), h.ListStationNews)
+	}
+}
@@ -0,0 +1,419 @@
+package news
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/pgerr"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	ErrNewsNotFound    = errors.New("news item not found")
+	ErrDuplicateNews   = errors.New("a news item with the same url or content already exists")
+	ErrStationNotFound = errors.New("station not found")
+)
+
+// Item is the API representation of a news item. Rank is set only on search
+// results; higher ranks are better matches.
+type Item struct {
+	ID          int32              `json:"id"`
+	Source      string             `json:"source"`
+	Title       string             `json:"title"`
+	Content     pgtype.Text        `json:"content"`
+	Url         pgtype.Text        `json:"url"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+	FetchedAt   pgtype.Timestamptz `json:"fetched_at"`
+	RelevantTo  *Tags              `json:"relevant_to"`
+	Rank        *float32           `json:"rank,omitempty"`
+}
+
+// ListFilter narrows a news listing. With a Query the results are ranked
+// full-text matches; otherwise they are the newest items first. District is
+// a district code, as in Tags.
+type ListFilter struct {
+	Query    string
+	Source   string
+	District string
+}
+
+// Input holds the editable fields of a news item. Without RelevantTo, tags
+// are suggested from the title and content.
+type Input struct {
+	Source      string
+	Title       string
+	Content     *string
+	Url         *string
+	PublishedAt *time.Time
+	RelevantTo  *Tags
+}
+
+// Service handles news business logic.
+type Service struct {
+	queries *db.Queries
+	runner  *dbtx.Runner
+}
+
+// NewService creates a new news service instance.
+func NewService(pool *pgxpool.Pool) *Service {
+	return &Service{
+		queries: db.New(pool),
+		runner:  dbtx.New(pool),
+	}
+}
+
+// ListNews returns a page of news items matching the filter, together with
+// the total number of matching items.
+func (s *Service) ListNews(ctx context.Context, filter ListFilter, limit, offset int32) ([]Item, int64, error) {
+	var tags []byte
+	if filter.District != "" {
+		tags = Tags{Districts: []string{filter.District}}.containment()
+	}
+
+	query := strings.TrimSpace(filter.Query)
+	if query != "" {
+		return s.searchNews(ctx, query, filter.Source, tags, limit, offset)
+	}
+	if tags != nil {
+		return s.listTagged(ctx, tags, filter.Source, limit, offset)
+	}
+
+	var (
+		rows  []db.News
+		total int64
+		err   error
+	)
+	if filter.Source != "" {
+		rows, err = s.queries.ListNewsBySource(ctx, db.ListNewsBySourceParams{
+			Source: filter.Source,
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err == nil {
+			total, err = s.queries.CountNewsBySource(ctx, filter.Source)
+		}
+	} else {
+		rows, err = s.queries.ListNews(ctx, db.ListNewsParams{
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err == nil {
+			total, err = s.queries.CountNews(ctx)
+		}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return toItems(rows), total, nil
+}
+
+// ListStationNews returns a page of the news items relevant to a station,
+// newest first, together with the total number of such items. Items are
+// relevant if an editor tagged them with the station, or if they are tagged
+// with the district the station lies in (which needs district boundaries).
+func (s *Service) ListStationNews(ctx context.Context, stationID int32, limit, offset int32) ([]Item, int64, error) {
+	if _, err := s.queries.GetStationByID(ctx, stationID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, ErrStationNotFound
+		}
+		return nil, 0, err
+	}
+
+	codes, err := s.queries.ListStationDistricts(ctx, stationID)
+	if err != nil {
+		return nil, 0, err
+	}
+	// One containment filter per alternative, so that each uses the tags index
+	filters := [][]byte{Tags{StationIDs: []int32{stationID}}.containment()}
+	for _, code := range codes {
+		filters = append(filters, Tags{Districts: []string{code}}.containment())
+	}
+
+	rows, err := s.queries.ListNewsByAnyTags(ctx, db.ListNewsByAnyTagsParams{
+		Tags:      filters,
+		RowLimit:  limit,
+		RowOffset: offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.queries.CountNewsByAnyTags(ctx, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return toItems(rows), total, nil
+}
+
+// listTagged lists the items whose tags contain the given ones.
+func (s *Service) listTagged(ctx context.Context, tags []byte, source string, limit, offset int32) ([]Item, int64, error) {
+	sourceParam := pgtype.Text{String: source, Valid: source != ""}
+
+	rows, err := s.queries.ListNewsByTags(ctx, db.ListNewsByTagsParams{
+		Tags:      tags,
+		Source:    sourceParam,
+		RowLimit:  limit,
+		RowOffset: offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.queries.CountNewsByTags(ctx, db.CountNewsByTagsParams{
+		Tags:   tags,
+		Source: sourceParam,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return toItems(rows), total, nil
+}
+
+// searchNews runs a ranked full-text search over titles and content.
+func (s *Service) searchNews(ctx context.Context, query, source string, tags []byte, limit, offset int32) ([]Item, int64, error) {
+	sourceParam := pgtype.Text{String: source, Valid: source != ""}
+
+	rows, err := s.queries.SearchNews(ctx, db.SearchNewsParams{
+		Query:     query,
+		Source:    sourceParam,
+		Tags:      tags,
+		RowLimit:  limit,
+		RowOffset: offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.queries.CountSearchNews(ctx, db.CountSearchNewsParams{
+		Query:  query,
+		Source: sourceParam,
+		Tags:   tags,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]Item, 0, len(rows))
+	for _, row := range rows {
+		rank := row.Rank
+		items = append(items, Item{
+			ID:          row.ID,
+			Source:      row.Source,
+			Title:       row.Title,
+			Content:     row.Content,
+			Url:         row.Url,
+			PublishedAt: row.PublishedAt,
+			FetchedAt:   row.FetchedAt,
+			RelevantTo:  decodeTags(row.RelevantTo),
+			Rank:        &rank,
+		})
+	}
+	return items, total, nil
+}
+
+// GetNews returns a news item by ID.
+func (s *Service) GetNews(ctx context.Context, id int32) (*Item, error) {
+	row, err := s.queries.GetNewsByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNewsNotFound
+		}
+		return nil, err
+	}
+	return toItem(row), nil
+}
+
+// CreateNews adds a news item.
+func (s *Service) CreateNews(ctx context.Context, input Input) (*Item, error) {
+	tags, err := relevantTo(input)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.News, error) {
+		if err := checkStations(ctx, q, tags.StationIDs); err != nil {
+			return db.News{}, err
+		}
+		return q.CreateNews(ctx, db.CreateNewsParams{
+			Source:      input.Source,
+			Title:       input.Title,
+			Content:     optionalText(input.Content),
+			Url:         optionalText(input.Url),
+			PublishedAt: optionalTimestamp(input.PublishedAt),
+			RelevantTo:  tags.Encode(),
+			ContentHash: contentHash(input),
+		})
+	})
+	if err != nil {
+		if pgerr.IsUniqueViolation(err) {
+			return nil, ErrDuplicateNews
+		}
+		return nil, err
+	}
+	return toItem(row), nil
+}
+
+// UpdateNews replaces every editable field of a news item.
+func (s *Service) UpdateNews(ctx context.Context, id int32, input Input) (*Item, error) {
+	tags, err := relevantTo(input)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.News, error) {
+		if err := checkStations(ctx, q, tags.StationIDs); err != nil {
+			return db.News{}, err
+		}
+		return q.UpdateNews(ctx, db.UpdateNewsParams{
+			ID:          id,
+			Source:      input.Source,
+			Title:       input.Title,
+			Content:     optionalText(input.Content),
+			Url:         optionalText(input.Url),
+			PublishedAt: optionalTimestamp(input.PublishedAt),
+			RelevantTo:  tags.Encode(),
+			ContentHash: contentHash(input),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNewsNotFound
+		}
+		if pgerr.IsUniqueViolation(err) {
+			return nil, ErrDuplicateNews
+		}
+		return nil, err
+	}
+	return toItem(row), nil
+}
+
+// UpdateNewsTags replaces the tags of a news item, marking them reviewed.
+// Tagged stations must exist.
+func (s *Service) UpdateNewsTags(ctx context.Context, id int32, tags Tags) (*Item, error) {
+	if err := tags.normalize(); err != nil {
+		return nil, err
+	}
+
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.News, error) {
+		if err := checkStations(ctx, q, tags.StationIDs); err != nil {
+			return db.News{}, err
+		}
+		return q.UpdateNewsTags(ctx, db.UpdateNewsTagsParams{
+			ID:         id,
+			RelevantTo: tags.Encode(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNewsNotFound
+		}
+		return nil, err
+	}
+	return toItem(row), nil
+}
+
+// DeleteNews removes a news item.
+func (s *Service) DeleteNews(ctx context.Context, id int32) error {
+	return s.runner.InTx(ctx, func(q *db.Queries) error {
+		if _, err := q.GetNewsByID(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNewsNotFound
+			}
+			return err
+		}
+		return q.DeleteNews(ctx, id)
+	})
+}
+
+// ContentHash identifies a news item by its title and content, ignoring case and
+// whitespace differences, so the same story is stored once even under different URLs.
+func ContentHash(title, content string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(title), " ") + "\n" + strings.Join(strings.Fields(content), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func contentHash(input Input) pgtype.Text {
+	content := ""
+	if input.Content != nil {
+		content = *input.Content
+	}
+	return pgtype.Text{String: ContentHash(input.Title, content), Valid: true}
+}
+
+func toItem(row db.News) *Item {
+	return &Item{
+		ID:          row.ID,
+		Source:      row.Source,
+		Title:       row.Title,
+		Content:     row.Content,
+		Url:         row.Url,
+		PublishedAt: row.PublishedAt,
+		FetchedAt:   row.FetchedAt,
+		RelevantTo:  decodeTags(row.RelevantTo),
+	}
+}
+
+func toItems(rows []db.News) []Item {
+	items := make([]Item, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, *toItem(row))
+	}
+	return items
+}
+
+func optionalText(value *string) pgtype.Text {
+	if value == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *value, Valid: true}
+}
+
+func optionalTimestamp(value *time.Time) pgtype.Timestamptz {
+	if value == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *value, Valid: true}
+}
+
+// relevantTo returns the tags to store for input: the editor's, validated,
+// or ones suggested from its text.
+func relevantTo(input Input) (Tags, error) {
+	if input.RelevantTo == nil {
+		content := ""
+		if input.Content != nil {
+			content = *input.Content
+		}
+		return SuggestTags(input.Title, content), nil
+	}
+
+	tags := *input.RelevantTo
+	if err := tags.normalize(); err != nil {
+		return Tags{}, err
+	}
+	return tags, nil
+}
+
+// checkStations rejects tags naming stations that do not exist. It runs in
+// the write's transaction, but a station deleted later stays in the tags.
+func checkStations(ctx context.Context, q *db.Queries, stationIDs []int32) error {
+	if len(stationIDs) == 0 {
+		return nil
+	}
+	unknown, err := q.ListUnknownStationIDs(ctx, stationIDs)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: unknown station ids %v", ErrInvalidTags, unknown)
+	}
+	return nil
+}
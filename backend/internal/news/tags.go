@@ -0,0 +1,255 @@
+package news
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits on editor-supplied tags.
+const (
+	maxTagValues    = 50
+	maxTagLength    = 50
+	maxSupplyLength = 255 // supply_needs.supply_type
+)
+
+var ErrInvalidTags = errors.New("invalid relevant_to")
+
+// Tags is the structured form of news.relevant_to. Ingestion suggests districts,
+// supply types and tags from keywords; editors correct them, which marks them
+// reviewed so that refreshing the item from its feed keeps the corrections.
+// Station IDs are only set by editors, as stations have no names to match, and
+// must name existing stations; a station's news also includes its district's.
+type Tags struct {
+	StationIDs  []int32  `json:"station_ids"`
+	Districts   []string `json:"districts"`    // District codes, e.g. "KC"
+	SupplyTypes []string `json:"supply_types"` // As in supply_needs.supply_type, e.g. "water"
+	Tags        []string `json:"tags"`         // Free tags, e.g. "typhoon"
+	Reviewed    bool     `json:"reviewed"`
+}
+
+// district is one of Hong Kong's 18 districts, with the names it is matched by.
+type district struct {
+	code  string
+	names []string // The district and well-known places in it, in English and Chinese
+}
+
+// Chinese names match anywhere in the text, so short names that also occur
+// inside ordinary phrases are left out: 大圍 (擴大圍封), 火炭 (燒火炭), 上水
+// (帶上水), 中環 (其中環境), 上環 (加上環保), 北角 (東北角), and the district
+// names 北區, 南區, 東區 and 離島 (東北區, 華南區, 遠東區, 遠離島嶼).
+var districts = []district{
+	{"CW", []string{"Central and Western", "Central & Western", "Sheung Wan", "Sai Ying Pun", "Kennedy Town", "Mid-Levels", "中西區", "西營盤", "堅尼地城"}},
+	{"WC", []string{"Wan Chai", "Causeway Bay", "Happy Valley", "灣仔", "銅鑼灣", "跑馬地"}},
+	{"EA", []string{"Eastern District", "North Point", "Quarry Bay", "Shau Kei Wan", "Chai Wan", "鰂魚涌", "筲箕灣", "柴灣"}},
+	{"SO", []string{"Southern District", "Aberdeen", "Ap Lei Chau", "Stanley", "Pok Fu Lam", "香港仔", "鴨脷洲", "赤柱", "薄扶林"}},
+	{"YTM", []string{"Yau Tsim Mong", "Mong Kok", "Tsim Sha Tsui", "Yau Ma Tei", "油尖旺", "旺角", "尖沙咀", "油麻地"}},
+	{"SSP", []string{"Sham Shui Po", "Cheung Sha Wan", "Shek Kip Mei", "Mei Foo", "深水埗", "長沙灣", "石硤尾", "美孚"}},
+	{"KC", []string{"Kowloon City", "Hung Hom", "To Kwa Wan", "Ho Man Tin", "Kai Tak", "九龍城", "紅磡", "土瓜灣", "何文田", "啟德"}},
+	{"WTS", []string{"Wong Tai Sin", "Diamond Hill", "Tsz Wan Shan", "San Po Kong", "黃大仙", "鑽石山", "慈雲山", "新蒲崗"}},
+	{"KT", []string{"Kwun Tong", "Lam Tin", "Ngau Tau Kok", "Kowloon Bay", "Yau Tong", "觀塘", "藍田", "牛頭角", "九龍灣", "油塘"}},
+	{"KWT", []string{"Kwai Tsing", "Kwai Chung", "Kwai Fong", "Tsing Yi", "葵青", "葵涌", "葵芳", "青衣"}},
+	{"TW", []string{"Tsuen Wan", "Ma Wan", "Sham Tseng", "荃灣", "馬灣"}},
+	{"TM", []string{"Tuen Mun", "屯門"}},
+	{"YL", []string{"Yuen Long", "Tin Shui Wai", "Kam Tin", "元朗", "天水圍", "錦田"}},
+	{"NO", []string{"North District", "Sheung Shui", "Fanling", "Sha Tau Kok", "粉嶺", "沙頭角"}},
+	{"TP", []string{"Tai Po", "Lam Tsuen", "大埔", "林村"}},
+	{"ST", []string{"Sha Tin", "Shatin", "Tai Wai", "Ma On Shan", "Fo Tan", "沙田", "馬鞍山"}},
+	{"SK", []string{"Sai Kung", "Tseung Kwan O", "Hang Hau", "西貢", "將軍澳"}},
+	{"IS", []string{"Islands District", "Lantau", "Tung Chung", "Cheung Chau", "Lamma", "Peng Chau", "Mui Wo", "Tai O", "大嶼山", "東涌", "長洲", "南丫島", "坪洲", "梅窩", "大澳"}},
+}
+
+// supplyKeywords maps supply types to the words suggesting them.
+var supplyKeywords = map[string][]string{
+	"water":    {"drinking water", "bottled water", "食水", "飲用水", "樽裝水"},
+	"food":     {"food", "meals", "食物", "糧食", "飯盒"},
+	"medical":  {"medical", "medicine", "first aid", "藥物", "醫療", "急救"},
+	"shelter":  {"shelter", "shelters", "庇護中心", "臨時庇護"},
+	"clothing": {"clothing", "clothes", "衣物"},
+	"blankets": {"blanket", "blankets", "毛氈"},
+}
+
+// tagKeywords maps free tags to the words suggesting them.
+var tagKeywords = map[string][]string{
+	"typhoon":      {"typhoon", "tropical cyclone", "颱風", "熱帶氣旋", "風球"},
+	"rainstorm":    {"rainstorm", "black rain", "red rain", "amber rain", "暴雨"},
+	"thunderstorm": {"thunderstorm", "雷暴"},
+	"landslip":     {"landslip", "landslide", "landslides", "山泥傾瀉"},
+	"flooding":     {"flood", "floods", "flooding", "flooded", "水浸", "洪水"},
+	"heat":         {"very hot weather", "heatwave", "heat stroke", "酷熱", "中暑"},
+	"cold":         {"cold weather", "寒冷天氣"},
+	"fire":         {"fire", "wildfire", "hill fire", "火警", "山火"},
+}
+
+// IsDistrict reports whether code is a known district code.
+func IsDistrict(code string) bool {
+	return slices.ContainsFunc(districts, func(d district) bool { return d.code == code })
+}
+
+// SuggestTags suggests districts, supply types and free tags for a news item by
+// matching its text against district and place names and keywords. English
+// words match whole words, ignoring case; Chinese matches anywhere.
+func SuggestTags(title, content string) Tags {
+	text := strings.ToLower(title + "\n" + content)
+	tags := Tags{
+		StationIDs:  []int32{},
+		Districts:   []string{},
+		SupplyTypes: matchKeywords(text, supplyKeywords),
+		Tags:        matchKeywords(text, tagKeywords),
+	}
+	for _, d := range districts {
+		if slices.ContainsFunc(d.names, func(name string) bool { return containsPhrase(text, name) }) {
+			tags.Districts = append(tags.Districts, d.code)
+		}
+	}
+	slices.Sort(tags.Districts)
+	return tags
+}
+
+// Encode returns the tags as stored in news.relevant_to.
+func (t Tags) Encode() []byte {
+	encoded, _ := json.Marshal(t) // Slices of strings and ints always marshal
+	return encoded
+}
+
+// containment returns the JSON that relevant_to contains when an item is tagged
+// with at least these station IDs and districts, for @> queries.
+func (t Tags) containment() []byte {
+	filter := map[string]any{}
+	if len(t.StationIDs) > 0 {
+		filter["station_ids"] = t.StationIDs
+	}
+	if len(t.Districts) > 0 {
+		filter["districts"] = t.Districts
+	}
+	encoded, _ := json.Marshal(filter)
+	return encoded
+}
+
+// normalize validates editor-supplied tags and puts them in canonical form:
+// district codes upper-cased, supply types and tags lower-cased, duplicates
+// removed and values sorted. Editor tags are always reviewed.
+func (t *Tags) normalize() error {
+	if len(t.StationIDs) > maxTagValues || len(t.Districts) > maxTagValues ||
+		len(t.SupplyTypes) > maxTagValues || len(t.Tags) > maxTagValues {
+		return fmt.Errorf("%w: at most %d values per list", ErrInvalidTags, maxTagValues)
+	}
+
+	stationIDs := []int32{}
+	for _, id := range t.StationIDs {
+		if id <= 0 {
+			return fmt.Errorf("%w: station ids must be positive", ErrInvalidTags)
+		}
+		stationIDs = append(stationIDs, id)
+	}
+	slices.Sort(stationIDs)
+	t.StationIDs = slices.Compact(stationIDs)
+
+	var err error
+	if t.Districts, err = normalizeValues(t.Districts, strings.ToUpper, 0); err != nil {
+		return err
+	}
+	for _, code := range t.Districts {
+		if !IsDistrict(code) {
+			return fmt.Errorf("%w: unknown district %q", ErrInvalidTags, code)
+		}
+	}
+	if t.SupplyTypes, err = normalizeValues(t.SupplyTypes, strings.ToLower, maxSupplyLength); err != nil {
+		return err
+	}
+	if t.Tags, err = normalizeValues(t.Tags, strings.ToLower, maxTagLength); err != nil {
+		return err
+	}
+
+	t.Reviewed = true
+	return nil
+}
+
+func normalizeValues(values []string, fold func(string) string, maxLength int) ([]string, error) {
+	normalized := []string{}
+	for _, value := range values {
+		value = fold(strings.Join(strings.Fields(value), " "))
+		if value == "" {
+			return nil, fmt.Errorf("%w: values must not be empty", ErrInvalidTags)
+		}
+		if maxLength > 0 && utf8.RuneCountInString(value) > maxLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTags, value, maxLength)
+		}
+		normalized = append(normalized, value)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// decodeTags reads news.relevant_to. Values that are not tags, such as those
+// written before tags were structured, read as no tags.
+func decodeTags(raw []byte) *Tags {
+	if len(raw) == 0 {
+		return nil
+	}
+	var tags Tags
+	if err := json.Unmarshal(raw, &tags); err != nil {
+		return nil
+	}
+	return &tags
+}
+
+// matchKeywords returns, sorted, the keys of keywords with a word found in text.
+func matchKeywords(text string, keywords map[string][]string) []string {
+	matched := []string{}
+	for key, words := range keywords {
+		if slices.ContainsFunc(words, func(word string) bool { return containsPhrase(text, word) }) {
+			matched = append(matched, key)
+		}
+	}
+	slices.Sort(matched)
+	return matched
+}
+
+// containsPhrase reports whether lower-cased text contains phrase. Phrases
+// starting or ending with a Latin letter must not be part of a longer word, so
+// "fire" does not match "firearm".
+func containsPhrase(text, phrase string) bool {
+	phrase = strings.ToLower(phrase)
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], phrase)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(phrase)
+		if boundary(text, start, phrase, true) && boundary(text, end, phrase, false) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func boundary(text string, at int, phrase string, before bool) bool {
+	var edge, neighbour rune
+	if before {
+		edge, _ = utf8.DecodeRuneInString(phrase)
+		neighbour, _ = utf8.DecodeLastRuneInString(text[:at])
+		if at == 0 {
+			return true
+		}
+	} else {
+		edge, _ = utf8.DecodeLastRuneInString(phrase)
+		neighbour, _ = utf8.DecodeRuneInString(text[at:])
+		if at == len(text) {
+			return true
+		}
+	}
+	if !isLatin(edge) {
+		return true
+	}
+	return !isLatin(neighbour) && !unicode.IsDigit(neighbour)
+}
+
+func isLatin(r rune) bool {
+	return r < unicode.MaxLatin1 && unicode.IsLetter(r)
+}
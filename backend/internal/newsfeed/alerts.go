@@ -0,0 +1,288 @@
+package newsfeed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"hkers-backend/internal/config"
+	"hkers-backend/internal/news"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// errAlertExists rolls back the news item created for an alert that turned out
+// to be stored already.
+var errAlertExists = errors.New("alert already stored")
+
+// storeCAP stores the alerts of a CAP feed, which is either a single CAP
+// document or an RSS/Atom index whose entries link to CAP documents. Linked
+// documents already stored are not fetched again. A linked document that fails
+// does not fail the index: one that will fail again, such as a 404 or malformed
+// XML, is recorded and skipped until its index entry changes, and any other
+// failure is counted for retrying on the next poll.
+func (w *Worker) storeCAP(ctx context.Context, feed config.NewsFeedConfig, result *fetchResult) (storeCounts, error) {
+	if isCAP(result.Body) {
+		return w.storeAlertDocument(ctx, feed, result.Body, result.URL.String())
+	}
+
+	entries, err := Parse(bytes.NewReader(result.Body), result.URL)
+	if err != nil {
+		return storeCounts{}, err
+	}
+
+	var counts storeCounts
+	for _, entry := range entries {
+		if entry.URL == "" {
+			continue
+		}
+		exists, err := w.queries.AlertSourceExists(ctx, optionalText(entry.URL))
+		if err != nil {
+			return counts, err
+		}
+		if exists {
+			continue
+		}
+		entryHash := alertEntryHash(entry)
+		failed, err := w.queries.AlertSourceFailed(ctx, db.AlertSourceFailedParams{
+			SourceUrl: entry.URL,
+			EntryHash: entryHash,
+		})
+		if err != nil {
+			return counts, err
+		}
+		if failed {
+			continue
+		}
+
+		doc, err := fetch(ctx, w.client, w.cfg.UserAgent, entry.URL, "", "")
+		if err == nil {
+			var stored storeCounts
+			stored, err = w.storeAlertDocument(ctx, feed, doc.Body, entry.URL)
+			counts.added += stored.added
+		}
+		if ctx.Err() != nil {
+			return counts, ctx.Err()
+		}
+
+		switch {
+		case err == nil:
+			// Clears a failure recorded for an earlier version of the entry.
+			// Polling state is not audited, so it is written directly
+			if err := w.queries.DeleteAlertSourceFailure(ctx, entry.URL); err != nil {
+				return counts, err
+			}
+		case permanentFailure(err):
+			log.Printf("news feed %s: skipping CAP document %s until its entry changes: %v", feed.Name, entry.URL, err)
+			if err := w.queries.RecordAlertSourceFailure(ctx, db.RecordAlertSourceFailureParams{
+				SourceUrl:  entry.URL,
+				FeedName:   feed.Name,
+				EntryHash:  entryHash,
+				LastStatus: optionalStatus(fetchStatus(err)),
+				LastError:  err.Error(),
+			}); err != nil {
+				return counts, err
+			}
+		default:
+			log.Printf("news feed %s: CAP document %s failed, retrying on the next poll: %v", feed.Name, entry.URL, err)
+			counts.retry++
+		}
+	}
+	return counts, nil
+}
+
+// permanentFailure reports whether a linked CAP document failed in a way that
+// fetching it again will not fix: a client error other than a timeout or rate
+// limit, or a document that is not a valid alert.
+func permanentFailure(err error) bool {
+	if errors.Is(err, ErrInvalidAlert) {
+		return true
+	}
+	status := fetchStatus(err)
+	return status >= 400 && status < 500 &&
+		status != http.StatusRequestTimeout && status != http.StatusTooManyRequests
+}
+
+// alertEntryHash identifies the version of an index entry, so that a failed
+// document is fetched again once the entry linking it changes.
+func alertEntryHash(entry Entry) string {
+	published := ""
+	if entry.PublishedAt != nil {
+		published = entry.PublishedAt.UTC().Format(time.RFC3339)
+	}
+	sum := sha256.Sum256([]byte(entry.Title + "\x00" + entry.Content + "\x00" + published))
+	return hex.EncodeToString(sum[:])
+}
+
+func (w *Worker) storeAlertDocument(ctx context.Context, feed config.NewsFeedConfig, body []byte, sourceURL string) (storeCounts, error) {
+	alert, err := ParseCAP(bytes.NewReader(body))
+	if err != nil {
+		return storeCounts{}, err
+	}
+	added, err := w.storeAlert(ctx, feed, alert, sourceURL)
+	if err != nil || !added {
+		return storeCounts{}, err
+	}
+	return storeCounts{added: 1}, nil
+}
+
+// storeAlert stores an alert together with a news item carrying its text, and
+// ends the alerts it updates or cancels. It reports false if the alert was
+// already stored. Only actual alerts become news; tests and exercises do not.
+func (w *Worker) storeAlert(ctx context.Context, feed config.NewsFeedConfig, alert *Alert, sourceURL string) (bool, error) {
+	// The first <info> block is the primary one; the others are usually
+	// translations, and all of them are kept in infos
+	primary := AlertInfo{Event: alert.MsgType}
+	if len(alert.Infos) > 0 {
+		primary = alert.Infos[0]
+	}
+	if primary.Event == "" {
+		primary.Event = primary.Headline
+	}
+	effective := alert.Sent
+	if primary.Effective != nil {
+		effective = *primary.Effective
+	}
+
+	geocodes, err := json.Marshal(alert.Geocodes)
+	if err != nil {
+		return false, err
+	}
+	if alert.Geocodes == nil {
+		geocodes = []byte("[]")
+	}
+	infos, err := json.Marshal(alert.Infos)
+	if err != nil {
+		return false, err
+	}
+	if alert.Infos == nil {
+		infos = []byte("[]")
+	}
+
+	if alert.Skipped > 0 {
+		log.Printf("news feed %s: alert %s skipped %d malformed polygons or circles", feed.Name, alert.Identifier, alert.Skipped)
+	}
+	if !alert.Located() {
+		// Stored for the alert list, but not shown for any location
+		log.Printf("news feed %s: alert %s has an area without usable shapes or geocodes", feed.Name, alert.Identifier)
+	}
+
+	params := db.CreateAlertParams{
+		Sender:      alert.Sender,
+		Identifier:  alert.Identifier,
+		SentAt:      pgtype.Timestamptz{Time: alert.Sent, Valid: true},
+		Status:      truncate(alert.Status, 20),
+		MsgType:     truncate(alert.MsgType, 20),
+		Event:       truncate(primary.Event, maxTitleLength),
+		Headline:    optionalText(primary.Headline),
+		Description: optionalText(primary.Description),
+		Instruction: optionalText(primary.Instruction),
+		Language:    optionalText(truncate(primary.Language, 35)),
+		Categories:  primary.Categories,
+		Severity:    primary.Severity,
+		Urgency:     primary.Urgency,
+		Certainty:   primary.Certainty,
+		EffectiveAt: pgtype.Timestamptz{Time: effective, Valid: true},
+		OnsetAt:     optionalTimestamp(primary.Onset),
+		ExpiresAt:   optionalTimestamp(primary.Expires),
+		AreaDesc:    optionalText(strings.Join(alert.AreaDescs, "; ")),
+		AreaWkt:     optionalText(alert.AreaWKT()),
+		Geocodes:    geocodes,
+		Infos:       infos,
+		SourceUrl:   optionalText(sourceURL),
+		// An <area> that yielded nothing is not territory-wide
+		TerritoryWide: !alert.HasArea,
+	}
+	if params.Categories == nil {
+		params.Categories = []string{}
+	}
+
+	err = w.runner.InTx(ctx, func(q *db.Queries) error {
+		if alert.Status == "Actual" && len(alert.Infos) > 0 {
+			newsID, err := alertNews(ctx, q, feed, alert, primary)
+			if err != nil {
+				return err
+			}
+			params.NewsID = pgtype.Int4{Int32: newsID, Valid: true}
+		}
+
+		if _, err := q.CreateAlert(ctx, params); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errAlertExists
+			}
+			return err
+		}
+
+		if alert.MsgType == "Update" || alert.MsgType == "Cancel" {
+			supersede := db.SupersedeAlertsParams{}
+			for _, ref := range alert.References {
+				supersede.Senders = append(supersede.Senders, ref.Sender)
+				supersede.Identifiers = append(supersede.Identifiers, ref.Identifier)
+			}
+			if len(supersede.Senders) > 0 {
+				if _, err := q.SupersedeAlerts(ctx, supersede); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errAlertExists) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// alertNews creates the news item for an alert, or returns the existing item
+// with the same text. Alert items have no URL: feeds often point every alert
+// at the same warnings page. Tags are suggested from the text and the area
+// description, which usually names the districts affected.
+func alertNews(ctx context.Context, q *db.Queries, feed config.NewsFeedConfig, alert *Alert, info AlertInfo) (int32, error) {
+	title := info.Headline
+	if title == "" {
+		title = info.Event
+	}
+	title = truncate(strings.Join(strings.Fields(title), " "), maxTitleLength)
+
+	var paragraphs []string
+	for _, text := range []string{info.Description, info.Instruction} {
+		if text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	content := strings.Join(paragraphs, "\n\n")
+	hash := pgtype.Text{String: news.ContentHash(title, content), Valid: true}
+
+	item, err := q.IngestNews(ctx, db.IngestNewsParams{
+		Source:      truncate(feed.Source, maxSourceLength),
+		Title:       title,
+		Content:     optionalText(content),
+		PublishedAt: pgtype.Timestamptz{Time: alert.Sent, Valid: true},
+		RelevantTo:  news.SuggestTags(title, content+"\n"+strings.Join(alert.AreaDescs, "\n")).Encode(),
+		ContentHash: hash,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Same text as an earlier alert, e.g. a repeated signal bulletin
+		item, err = q.GetNewsByContentHash(ctx, hash)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return item.ID, nil
+}
+
+func optionalTimestamp(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
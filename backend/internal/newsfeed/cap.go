@@ -0,0 +1,370 @@
+package newsfeed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// circleSegments is how many sides the polygon approximating a CAP circle has.
+const circleSegments = 36
+
+const earthRadiusKm = 6371.0
+
+var ErrInvalidAlert = errors.New("invalid CAP alert")
+
+// Alert is a CAP 1.2 alert message. Texts and classification come from each
+// <info> block; the area is the union of the areas of all of them. An alert
+// without any <area> covers the sender's whole territory.
+type Alert struct {
+	Sender     string
+	Identifier string
+	Sent       time.Time
+	Status     string
+	MsgType    string
+	References []AlertReference
+	Infos      []AlertInfo
+	AreaDescs  []string
+	HasArea    bool      // Whether any <info> block has an <area>
+	Polygons   [][]point // Closed rings, including approximated circles
+	Skipped    int       // Malformed polygons and circles left out of Polygons
+	Geocodes   []Geocode
+}
+
+// AlertReference identifies an earlier alert that an Update or Cancel replaces.
+type AlertReference struct {
+	Sender     string
+	Identifier string
+}
+
+// AlertInfo is one <info> block, typically one per language.
+type AlertInfo struct {
+	Language    string            `json:"language"`
+	Categories  []string          `json:"categories"`
+	Event       string            `json:"event"`
+	Severity    db.AlertSeverity  `json:"severity"`
+	Urgency     db.AlertUrgency   `json:"urgency"`
+	Certainty   db.AlertCertainty `json:"certainty"`
+	Effective   *time.Time        `json:"effective"`
+	Onset       *time.Time        `json:"onset"`
+	Expires     *time.Time        `json:"expires"`
+	SenderName  string            `json:"sender_name,omitempty"`
+	Headline    string            `json:"headline,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Instruction string            `json:"instruction,omitempty"`
+	Web         string            `json:"web,omitempty"`
+}
+
+// Geocode is a CAP geographic code, e.g. a district code.
+type Geocode struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type point struct {
+	lat float64
+	lon float64
+}
+
+type capAlert struct {
+	Identifier string    `xml:"identifier"`
+	Sender     string    `xml:"sender"`
+	Sent       string    `xml:"sent"`
+	Status     string    `xml:"status"`
+	MsgType    string    `xml:"msgType"`
+	References string    `xml:"references"`
+	Infos      []capInfo `xml:"info"`
+}
+
+type capInfo struct {
+	Language    string    `xml:"language"`
+	Categories  []string  `xml:"category"`
+	Event       string    `xml:"event"`
+	Urgency     string    `xml:"urgency"`
+	Severity    string    `xml:"severity"`
+	Certainty   string    `xml:"certainty"`
+	Effective   string    `xml:"effective"`
+	Onset       string    `xml:"onset"`
+	Expires     string    `xml:"expires"`
+	SenderName  string    `xml:"senderName"`
+	Headline    string    `xml:"headline"`
+	Description string    `xml:"description"`
+	Instruction string    `xml:"instruction"`
+	Web         string    `xml:"web"`
+	Areas       []capArea `xml:"area"`
+}
+
+type capArea struct {
+	AreaDesc string       `xml:"areaDesc"`
+	Polygons []string     `xml:"polygon"`
+	Circles  []string     `xml:"circle"`
+	Geocodes []capGeocode `xml:"geocode"`
+}
+
+type capGeocode struct {
+	ValueName string `xml:"valueName"`
+	Value     string `xml:"value"`
+}
+
+// isCAP reports whether body is a CAP document rather than a feed.
+func isCAP(body []byte) bool {
+	decoder := newDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "alert"
+		}
+	}
+}
+
+// ParseCAP reads a CAP 1.2 (or 1.1) alert message. Malformed polygons and circles
+// are skipped rather than rejecting the alert, which is still worth showing; it
+// is then located by whatever shapes and geocodes remain (see Located).
+func ParseCAP(r io.Reader) (*Alert, error) {
+	var doc capAlert
+	if err := newDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAlert, err)
+	}
+
+	alert := &Alert{
+		Sender:     strings.TrimSpace(doc.Sender),
+		Identifier: strings.TrimSpace(doc.Identifier),
+		Status:     strings.TrimSpace(doc.Status),
+		MsgType:    strings.TrimSpace(doc.MsgType),
+	}
+	if alert.Sender == "" || alert.Identifier == "" || alert.Status == "" || alert.MsgType == "" {
+		return nil, fmt.Errorf("%w: identifier, sender, status and msgType are required", ErrInvalidAlert)
+	}
+	if len(alert.Sender) > 255 || len(alert.Identifier) > 255 {
+		return nil, fmt.Errorf("%w: sender or identifier is too long", ErrInvalidAlert)
+	}
+	sent, ok := parseDate(doc.Sent)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid sent time %q", ErrInvalidAlert, doc.Sent)
+	}
+	alert.Sent = sent
+
+	// references is a space-separated list of "sender,identifier,sent"
+	for _, ref := range strings.Fields(doc.References) {
+		parts := strings.Split(ref, ",")
+		if len(parts) >= 2 && parts[0] != "" && parts[1] != "" {
+			alert.References = append(alert.References, AlertReference{Sender: parts[0], Identifier: parts[1]})
+		}
+	}
+
+	seenDescs := map[string]bool{}
+	seenCodes := map[Geocode]bool{}
+	for _, info := range doc.Infos {
+		alert.Infos = append(alert.Infos, AlertInfo{
+			Language:    strings.TrimSpace(info.Language),
+			Categories:  trimAll(info.Categories),
+			Event:       strings.TrimSpace(info.Event),
+			Severity:    parseSeverity(info.Severity),
+			Urgency:     parseUrgency(info.Urgency),
+			Certainty:   parseCertainty(info.Certainty),
+			Effective:   optionalDate(info.Effective),
+			Onset:       optionalDate(info.Onset),
+			Expires:     optionalDate(info.Expires),
+			SenderName:  strings.TrimSpace(info.SenderName),
+			Headline:    strings.TrimSpace(info.Headline),
+			Description: strings.TrimSpace(info.Description),
+			Instruction: strings.TrimSpace(info.Instruction),
+			Web:         strings.TrimSpace(info.Web),
+		})
+
+		for _, area := range info.Areas {
+			alert.HasArea = true
+			if desc := strings.TrimSpace(area.AreaDesc); desc != "" && !seenDescs[desc] {
+				seenDescs[desc] = true
+				alert.AreaDescs = append(alert.AreaDescs, desc)
+			}
+			for _, raw := range area.Polygons {
+				if ring, ok := parsePolygon(raw); ok {
+					alert.Polygons = append(alert.Polygons, ring)
+				} else {
+					alert.Skipped++
+				}
+			}
+			for _, raw := range area.Circles {
+				if ring, ok := parseCircle(raw); ok {
+					alert.Polygons = append(alert.Polygons, ring)
+				} else {
+					alert.Skipped++
+				}
+			}
+			for _, code := range area.Geocodes {
+				geocode := Geocode{Name: strings.TrimSpace(code.ValueName), Value: strings.TrimSpace(code.Value)}
+				if geocode.Value != "" && !seenCodes[geocode] {
+					seenCodes[geocode] = true
+					alert.Geocodes = append(alert.Geocodes, geocode)
+				}
+			}
+		}
+	}
+	return alert, nil
+}
+
+// Located reports whether the alert's area can be placed on a map: it covers
+// the whole territory, or has usable shapes or geocodes. Geocodes are matched
+// against the districts table when alerts are queried.
+func (a *Alert) Located() bool {
+	return !a.HasArea || len(a.Polygons) > 0 || len(a.Geocodes) > 0
+}
+
+// AreaWKT returns the alert's polygons as a WKT geometry collection, or "" if
+// it has none.
+func (a *Alert) AreaWKT() string {
+	if len(a.Polygons) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("GEOMETRYCOLLECTION(")
+	for i, ring := range a.Polygons {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString("POLYGON((")
+		for j, p := range ring {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.FormatFloat(p.lon, 'f', -1, 64))
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatFloat(p.lat, 'f', -1, 64))
+		}
+		b.WriteString("))")
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// parsePolygon parses a CAP polygon, "lat,lon lat,lon ...", closing it if needed.
+func parsePolygon(raw string) ([]point, bool) {
+	var ring []point
+	for _, pair := range strings.Fields(raw) {
+		p, ok := parsePoint(pair)
+		if !ok {
+			return nil, false
+		}
+		ring = append(ring, p)
+	}
+	if len(ring) < 3 {
+		return nil, false
+	}
+	if ring[0] != ring[len(ring)-1] {
+		ring = append(ring, ring[0])
+	}
+	if len(ring) < 4 {
+		return nil, false
+	}
+	return ring, true
+}
+
+// parseCircle parses a CAP circle, "lat,lon radius" with the radius in
+// kilometres, into a polygon approximating it.
+func parseCircle(raw string) ([]point, bool) {
+	fields := strings.Fields(raw)
+	if len(fields) != 2 {
+		return nil, false
+	}
+	center, ok := parsePoint(fields[0])
+	if !ok {
+		return nil, false
+	}
+	radius, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || radius <= 0 || radius > earthRadiusKm {
+		return nil, false
+	}
+
+	// Destination points along the circle on a sphere
+	lat1 := center.lat * math.Pi / 180
+	lon1 := center.lon * math.Pi / 180
+	angular := radius / earthRadiusKm
+	ring := make([]point, 0, circleSegments+1)
+	for i := 0; i < circleSegments; i++ {
+		bearing := 2 * math.Pi * float64(i) / circleSegments
+		lat2 := math.Asin(math.Sin(lat1)*math.Cos(angular) + math.Cos(lat1)*math.Sin(angular)*math.Cos(bearing))
+		lon2 := lon1 + math.Atan2(math.Sin(bearing)*math.Sin(angular)*math.Cos(lat1), math.Cos(angular)-math.Sin(lat1)*math.Sin(lat2))
+		ring = append(ring, point{
+			lat: lat2 * 180 / math.Pi,
+			lon: math.Mod(lon2*180/math.Pi+540, 360) - 180,
+		})
+	}
+	return append(ring, ring[0]), true
+}
+
+func parsePoint(pair string) (point, bool) {
+	latRaw, lonRaw, ok := strings.Cut(pair, ",")
+	if !ok {
+		return point{}, false
+	}
+	lat, err := strconv.ParseFloat(latRaw, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return point{}, false
+	}
+	lon, err := strconv.ParseFloat(lonRaw, 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return point{}, false
+	}
+	return point{lat: lat, lon: lon}, true
+}
+
+func parseSeverity(raw string) db.AlertSeverity {
+	switch value := db.AlertSeverity(strings.ToLower(strings.TrimSpace(raw))); value {
+	case db.AlertSeverityExtreme, db.AlertSeveritySevere, db.AlertSeverityModerate, db.AlertSeverityMinor:
+		return value
+	default:
+		return db.AlertSeverityUnknown
+	}
+}
+
+func parseUrgency(raw string) db.AlertUrgency {
+	switch value := db.AlertUrgency(strings.ToLower(strings.TrimSpace(raw))); value {
+	case db.AlertUrgencyImmediate, db.AlertUrgencyExpected, db.AlertUrgencyFuture, db.AlertUrgencyPast:
+		return value
+	default:
+		return db.AlertUrgencyUnknown
+	}
+}
+
+func parseCertainty(raw string) db.AlertCertainty {
+	normalized := strings.ToLower(strings.TrimSpace(raw))
+	if normalized == "very likely" {
+		// Deprecated CAP 1.0 value
+		normalized = string(db.AlertCertaintyLikely)
+	}
+	switch value := db.AlertCertainty(normalized); value {
+	case db.AlertCertaintyObserved, db.AlertCertaintyLikely, db.AlertCertaintyPossible, db.AlertCertaintyUnlikely:
+		return value
+	default:
+		return db.AlertCertaintyUnknown
+	}
+}
+
+func optionalDate(raw string) *time.Time {
+	if t, ok := parseDate(raw); ok {
+		return &t
+	}
+	return nil
+}
+
+func trimAll(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+	return trimmed
+}
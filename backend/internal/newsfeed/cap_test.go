@@ -0,0 +1,126 @@
+package newsfeed
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func capDocument(area string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<alert xmlns="urn:oasis:names:tc:emergency:cap:1.2">
+  <identifier>TEST-1</identifier>
+  <sender>warnings@example.org</sender>
+  <sent>2024-09-02T10:00:00+08:00</sent>
+  <status>Actual</status>
+  <msgType>Alert</msgType>
+  <scope>Public</scope>
+  <info>
+    <category>Met</category>
+    <event>Landslip Warning</event>
+    <urgency>Immediate</urgency>
+    <severity>Severe</severity>
+    <certainty>Observed</certainty>` + area + `
+  </info>
+</alert>`
+}
+
+func TestParseCAPArea(t *testing.T) {
+	tests := []struct {
+		name          string
+		area          string
+		hasArea       bool
+		polygons      int
+		skipped       int
+		geocodes      int
+		located       bool
+		territoryWide bool
+	}{
+		{
+			name:          "no area covers the territory",
+			located:       true,
+			territoryWide: true,
+		},
+		{
+			name:     "polygon and circle",
+			area:     `<area><areaDesc>Sha Tin</areaDesc><polygon>22.38,114.18 22.40,114.18 22.40,114.20 22.38,114.18</polygon><circle>22.38,114.19 2</circle></area>`,
+			hasArea:  true,
+			polygons: 2,
+			located:  true,
+		},
+		{
+			name:     "geocodes only",
+			area:     `<area><areaDesc>Sha Tin District</areaDesc><geocode><valueName>district</valueName><value>ST</value></geocode></area>`,
+			hasArea:  true,
+			geocodes: 1,
+			located:  true,
+		},
+		{
+			name:    "only malformed shapes",
+			area:    `<area><areaDesc>Somewhere</areaDesc><polygon>22.38,114.18 22.40</polygon><circle>22.38,114.19</circle></area>`,
+			hasArea: true,
+			skipped: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert, err := ParseCAP(strings.NewReader(capDocument(tt.area)))
+			if err != nil {
+				t.Fatalf("ParseCAP: %v", err)
+			}
+			if alert.HasArea != tt.hasArea || len(alert.Polygons) != tt.polygons ||
+				alert.Skipped != tt.skipped || len(alert.Geocodes) != tt.geocodes {
+				t.Errorf("area = has %v, %d polygons, %d skipped, %d geocodes; want %v, %d, %d, %d",
+					alert.HasArea, len(alert.Polygons), alert.Skipped, len(alert.Geocodes),
+					tt.hasArea, tt.polygons, tt.skipped, tt.geocodes)
+			}
+			if alert.Located() != tt.located {
+				t.Errorf("Located = %v, want %v", alert.Located(), tt.located)
+			}
+			if territoryWide := !alert.HasArea; territoryWide != tt.territoryWide {
+				t.Errorf("territory-wide = %v, want %v", territoryWide, tt.territoryWide)
+			}
+		})
+	}
+}
+
+func TestPermanentFailure(t *testing.T) {
+	_, invalid := ParseCAP(strings.NewReader(`<alert><identifier>TEST-1</identifier></alert>`))
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"not found", &fetchError{Status: http.StatusNotFound, Err: errors.New("unexpected status")}, true},
+		{"gone", &fetchError{Status: http.StatusGone, Err: errors.New("unexpected status")}, true},
+		{"invalid alert", invalid, true},
+		{"request timeout", &fetchError{Status: http.StatusRequestTimeout, Err: errors.New("unexpected status")}, false},
+		{"rate limited", &fetchError{Status: http.StatusTooManyRequests, Err: errors.New("unexpected status")}, false},
+		{"server error", &fetchError{Status: http.StatusServiceUnavailable, Err: errors.New("unexpected status")}, false},
+		{"no response", &fetchError{Err: errors.New("connection refused")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := permanentFailure(tt.err); got != tt.want {
+				t.Errorf("permanentFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertEntryHashChangesWithEntry(t *testing.T) {
+	published := time.Date(2024, 9, 2, 10, 0, 0, 0, time.UTC)
+	entry := Entry{Title: "Landslip Warning", Content: "Issued", URL: "https://example.org/cap/1.xml", PublishedAt: &published}
+	hash := alertEntryHash(entry)
+
+	if alertEntryHash(entry) != hash {
+		t.Error("hash of an unchanged entry differs")
+	}
+	updated := published.Add(time.Hour)
+	entry.PublishedAt = &updated
+	if alertEntryHash(entry) == hash {
+		t.Error("hash is unchanged after the entry was republished")
+	}
+}
@@ -0,0 +1,132 @@
+package newsfeed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFeedSize bounds how much of a response is read, so a misbehaving server
+// cannot exhaust memory.
+const maxFeedSize = 10 << 20
+
+const acceptHeader = "application/rss+xml, application/atom+xml, application/rdf+xml;q=0.9, application/xml;q=0.8, text/xml;q=0.8, */*;q=0.1"
+
+// fetchResult is the outcome of a conditional GET of a feed.
+type fetchResult struct {
+	Status       int
+	NotModified  bool
+	Body         []byte
+	URL          *url.URL // Where redirects ended up, for resolving relative links
+	ETag         string
+	LastModified string
+}
+
+// fetchError is a failed fetch. Status is 0 when no response was received.
+// RetryAfter is the delay the server asked for, if any.
+type fetchError struct {
+	Status     int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *fetchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *fetchError) Unwrap() error {
+	return e.Err
+}
+
+// fetch requests a feed, sending the validators from the last successful fetch
+// so that an unchanged feed costs a 304 instead of a full download.
+func fetch(ctx context.Context, client *http.Client, userAgent, feedURL, etag, lastModified string) (*fetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, &fetchError{Err: err}
+	}
+	req.Header.Set("Accept", acceptHeader)
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &fetchError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		// A 304 may omit the validators; keep the ones that produced it
+		return &fetchResult{
+			Status:       resp.StatusCode,
+			NotModified:  true,
+			ETag:         headerOr(resp.Header, "ETag", etag),
+			LastModified: headerOr(resp.Header, "Last-Modified", lastModified),
+		}, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, &fetchError{
+			Status:     resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Err:        fmt.Errorf("unexpected status %s", resp.Status),
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return nil, &fetchError{Status: resp.StatusCode, Err: fmt.Errorf("read feed: %w", err)}
+	}
+	if len(body) > maxFeedSize {
+		return nil, &fetchError{Status: resp.StatusCode, Err: fmt.Errorf("feed is larger than %d bytes", maxFeedSize)}
+	}
+
+	base, _ := url.Parse(feedURL)
+	if resp.Request != nil && resp.Request.URL != nil {
+		base = resp.Request.URL
+	}
+
+	return &fetchResult{
+		Status:       resp.StatusCode,
+		Body:         body,
+		URL:          base,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+func headerOr(header http.Header, name, fallback string) string {
+	if value := header.Get(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// parseRetryAfter reads a Retry-After header, given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
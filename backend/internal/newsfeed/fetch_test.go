@@ -0,0 +1,150 @@
+package newsfeed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testFeed = `<rss version="2.0"><channel><item><title>Item</title></item></channel></rss>`
+
+// conditionalServer serves testFeed with fixed validators and answers 304 to
+// requests that present them. It records the validators of each request.
+type conditionalServer struct {
+	*httptest.Server
+	etag, lastModified string
+	// sendValidatorsOn304 controls whether 304 responses repeat the validators
+	sendValidatorsOn304 bool
+
+	gotETags         []string
+	gotLastModifieds []string
+}
+
+func newConditionalServer(t *testing.T) *conditionalServer {
+	t.Helper()
+	s := &conditionalServer{
+		etag:                `"v1"`,
+		lastModified:        "Mon, 02 Sep 2024 10:00:00 GMT",
+		sendValidatorsOn304: true,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.gotETags = append(s.gotETags, r.Header.Get("If-None-Match"))
+		s.gotLastModifieds = append(s.gotLastModifieds, r.Header.Get("If-Modified-Since"))
+
+		if r.Header.Get("If-None-Match") == s.etag || r.Header.Get("If-Modified-Since") == s.lastModified {
+			if s.sendValidatorsOn304 {
+				w.Header().Set("ETag", s.etag)
+				w.Header().Set("Last-Modified", s.lastModified)
+			}
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", s.etag)
+		w.Header().Set("Last-Modified", s.lastModified)
+		w.Write([]byte(testFeed))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestFetchConditionalRoundTrip(t *testing.T) {
+	server := newConditionalServer(t)
+	ctx := context.Background()
+
+	first, err := fetch(ctx, server.Client(), "test", server.URL, "", "")
+	if err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	if first.NotModified || string(first.Body) != testFeed {
+		t.Fatalf("first fetch = %+v, want the feed", first)
+	}
+	if first.ETag != server.etag || first.LastModified != server.lastModified {
+		t.Errorf("validators = %q, %q; want %q, %q", first.ETag, first.LastModified, server.etag, server.lastModified)
+	}
+
+	second, err := fetch(ctx, server.Client(), "test", server.URL, first.ETag, first.LastModified)
+	if err != nil {
+		t.Fatalf("second fetch: %v", err)
+	}
+	if !second.NotModified || second.Status != http.StatusNotModified || len(second.Body) != 0 {
+		t.Errorf("second fetch = %+v, want 304 without a body", second)
+	}
+	if second.ETag != server.etag || second.LastModified != server.lastModified {
+		t.Errorf("validators after 304 = %q, %q", second.ETag, second.LastModified)
+	}
+
+	if server.gotETags[0] != "" || server.gotLastModifieds[0] != "" {
+		t.Errorf("first request sent validators %q, %q", server.gotETags[0], server.gotLastModifieds[0])
+	}
+	if server.gotETags[1] != server.etag || server.gotLastModifieds[1] != server.lastModified {
+		t.Errorf("second request sent If-None-Match %q, If-Modified-Since %q", server.gotETags[1], server.gotLastModifieds[1])
+	}
+}
+
+func TestFetchNotModifiedKeepsValidators(t *testing.T) {
+	server := newConditionalServer(t)
+	server.sendValidatorsOn304 = false
+
+	result, err := fetch(context.Background(), server.Client(), "test", server.URL, server.etag, server.lastModified)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if !result.NotModified {
+		t.Fatalf("fetch = %+v, want 304", result)
+	}
+	if result.ETag != server.etag || result.LastModified != server.lastModified {
+		t.Errorf("validators = %q, %q; want the ones sent", result.ETag, result.LastModified)
+	}
+}
+
+func TestFetchRetryAfterFeedsBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	result, err := fetch(context.Background(), server.Client(), "test", server.URL, "", "")
+	if err == nil {
+		t.Fatalf("fetch = %+v, want an error", result)
+	}
+	var fetchErr *fetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Status != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want a fetchError with status 503", err)
+	}
+	if fetchStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("fetchStatus = %d", fetchStatus(err))
+	}
+	if got := retryAfter(err); got != 10*time.Minute {
+		t.Fatalf("retryAfter = %s, want 10m", got)
+	}
+
+	// Retry-After outlasts the first backoff step, so it wins
+	delay := backoff(time.Minute, time.Hour, 1, retryAfter(err))
+	if delay < 10*time.Minute || delay > 11*time.Minute {
+		t.Errorf("backoff = %s, want 10m plus at most 10%% jitter", delay)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 9, 2, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-5", 0},
+		{"Mon, 02 Sep 2024 10:05:00 GMT", 5 * time.Minute},
+		{"Mon, 02 Sep 2024 09:00:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
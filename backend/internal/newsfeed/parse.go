@@ -0,0 +1,297 @@
+package newsfeed
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// Column limits of the news table.
+const (
+	maxTitleLength  = 255
+	maxSourceLength = 255
+	maxURLLength    = 512
+)
+
+var ErrUnknownFormat = errors.New("document is not an RSS or Atom feed")
+
+// Entry is a feed item reduced to the fields stored in news. Title and Content
+// are plain text; URL is absolute, or empty if the entry has no usable link.
+type Entry struct {
+	Title       string
+	Content     string
+	URL         string
+	PublishedAt *time.Time
+}
+
+// RSS 2.0, and RSS 1.0 (RDF), which keeps its items next to the channel.
+type rssDocument struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title          string  `xml:"title"`
+	Link           string  `xml:"link"`
+	GUID           rssGUID `xml:"guid"`
+	Description    string  `xml:"description"`
+	ContentEncoded string  `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate        string  `xml:"pubDate"`
+	DCDate         string  `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+
+type atomFeed struct {
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   atomText   `xml:"summary"`
+	Content   atomText   `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+// atomText is an Atom text construct. XHTML content is kept as markup.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Body  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// Parse reads an RSS 2.0, RSS 1.0 or Atom document. Relative links are resolved
+// against base, the URL the document was fetched from. Entries without a title
+// or content are skipped.
+func Parse(r io.Reader, base *url.URL) ([]Entry, error) {
+	decoder := newDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, ErrUnknownFormat
+			}
+			return nil, fmt.Errorf("parse feed: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(start.Name.Local) {
+		case "rss", "rdf":
+			var doc rssDocument
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return nil, fmt.Errorf("parse feed: %w", err)
+			}
+			return rssEntries(append(doc.Channel.Items, doc.Items...), base), nil
+		case "feed":
+			var doc atomFeed
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return nil, fmt.Errorf("parse feed: %w", err)
+			}
+			return atomEntries(doc.Entries, base), nil
+		default:
+			return nil, ErrUnknownFormat
+		}
+	}
+}
+
+// newDecoder returns a lenient XML decoder that understands non-UTF-8 encodings.
+func newDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+	// Feeds in the wild often use HTML entities and unescaped ampersands
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	return decoder
+}
+
+func rssEntries(items []rssItem, base *url.URL) []Entry {
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		link := item.Link
+		if link == "" && !strings.EqualFold(item.GUID.IsPermaLink, "false") {
+			link = item.GUID.Value
+		}
+		content := item.ContentEncoded
+		if strings.TrimSpace(content) == "" {
+			content = item.Description
+		}
+		published := item.PubDate
+		if published == "" {
+			published = item.DCDate
+		}
+
+		if entry, ok := newEntry(item.Title, content, link, published, base); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func atomEntries(items []atomEntry, base *url.URL) []Entry {
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		content := item.Content.text()
+		if strings.TrimSpace(content) == "" {
+			content = item.Summary.text()
+		}
+		published := item.Published
+		if published == "" {
+			published = item.Updated
+		}
+
+		if entry, ok := newEntry(item.Title.text(), content, alternateLink(item.Links), published, base); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// alternateLink returns the entry's link to its web page.
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+func (t atomText) text() string {
+	if strings.EqualFold(t.Type, "xhtml") {
+		return t.Inner
+	}
+	return t.Body
+}
+
+func newEntry(title, content, link, published string, base *url.URL) (Entry, bool) {
+	entry := Entry{
+		Title:   truncate(plainText(title), maxTitleLength),
+		Content: plainText(content),
+		URL:     resolveLink(link, base),
+	}
+	if entry.Title == "" {
+		// Some feeds carry only a description; use its beginning as the title
+		entry.Title = truncate(entry.Content, maxTitleLength)
+	}
+	if entry.Title == "" {
+		return Entry{}, false
+	}
+	if t, ok := parseDate(published); ok {
+		entry.PublishedAt = &t
+	}
+	return entry, true
+}
+
+// resolveLink makes link absolute. Links that are not http(s) or do not fit
+// the url column are dropped; the entry is then deduplicated by content only.
+func resolveLink(link string, base *url.URL) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+	parsed.Fragment = ""
+
+	resolved := parsed.String()
+	if len(resolved) > maxURLLength {
+		return ""
+	}
+	return resolved
+}
+
+// plainText strips markup from HTML text and collapses whitespace.
+func plainText(raw string) string {
+	if !strings.ContainsAny(raw, "<&") {
+		return strings.Join(strings.Fields(raw), " ")
+	}
+
+	var b strings.Builder
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(raw))
+	for {
+		switch tokenizer.Next() {
+		case nethtml.ErrorToken:
+			return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+		case nethtml.TextToken:
+			b.Write(tokenizer.Raw())
+		case nethtml.StartTagToken, nethtml.EndTagToken, nethtml.SelfClosingTagToken:
+			// Keep words in adjacent block elements apart
+			b.WriteByte(' ')
+		}
+	}
+}
+
+// truncate shortens s to at most limit characters.
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:limit]))
+}
+
+// dateLayouts are the date formats seen in RSS (RFC 822 and variants) and Atom (RFC 3339).
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func parseDate(raw string) (time.Time, bool) {
+	raw = strings.Join(strings.Fields(raw), " ")
+	if raw == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,185 @@
+package newsfeed
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// serveFeed starts a feed server that answers every request with body.
+func serveFeed(t *testing.T, contentType string, body []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// fetchEntries fetches a feed from a local server and parses it.
+func fetchEntries(t *testing.T, contentType string, body []byte) ([]Entry, *httptest.Server) {
+	t.Helper()
+	server := serveFeed(t, contentType, body)
+	result, err := fetch(context.Background(), server.Client(), "test", server.URL+"/feeds/news.xml", "", "")
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	entries, err := Parse(bytes.NewReader(result.Body), result.URL)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return entries, server
+}
+
+func TestParseRSS2(t *testing.T) {
+	entries, server := fetchEntries(t, "application/rss+xml", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Press releases</title>
+    <item>
+      <title>Shelter opens in Sha Tin</title>
+      <link>/releases/1.html#top</link>
+      <description>Short summary</description>
+      <content:encoded><![CDATA[<p>Temporary shelter&nbsp;open</p><p>Bring blankets</p>]]></content:encoded>
+      <pubDate>Mon, 02 Sep 2024 10:30:00 +0800</pubDate>
+    </item>
+    <item>
+      <description>No title &amp; no link</description>
+      <guid isPermaLink="false">tag:example,2024:2</guid>
+    </item>
+    <item>
+      <title></title>
+    </item>
+  </channel>
+</rss>`))
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+
+	first := entries[0]
+	if first.Title != "Shelter opens in Sha Tin" {
+		t.Errorf("title = %q", first.Title)
+	}
+	if first.Content != "Temporary shelter open Bring blankets" {
+		t.Errorf("content = %q, want content:encoded as plain text", first.Content)
+	}
+	if want := server.URL + "/releases/1.html"; first.URL != want {
+		t.Errorf("url = %q, want %q", first.URL, want)
+	}
+	want := time.Date(2024, 9, 2, 2, 30, 0, 0, time.UTC)
+	if first.PublishedAt == nil || !first.PublishedAt.Equal(want) {
+		t.Errorf("published = %v, want %v", first.PublishedAt, want)
+	}
+
+	second := entries[1]
+	if second.Title != "No title & no link" || second.URL != "" {
+		t.Errorf("second entry = %+v, want title from description and no URL", second)
+	}
+}
+
+func TestParseRSS1(t *testing.T) {
+	entries, _ := fetchEntries(t, "application/rdf+xml", []byte(`<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+         xmlns="http://purl.org/rss/1.0/"
+         xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://example.org/">
+    <title>Notices</title>
+  </channel>
+  <item rdf:about="https://example.org/notices/7">
+    <title>Water supply suspended</title>
+    <link>https://example.org/notices/7</link>
+    <description>Fresh water supply to Tai Po will be suspended.</description>
+    <dc:date>2024-09-02T08:00:00+08:00</dc:date>
+  </item>
+</rdf:RDF>`))
+
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Title != "Water supply suspended" || entry.URL != "https://example.org/notices/7" {
+		t.Errorf("entry = %+v", entry)
+	}
+	want := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+	if entry.PublishedAt == nil || !entry.PublishedAt.Equal(want) {
+		t.Errorf("published = %v, want %v from dc:date", entry.PublishedAt, want)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	entries, server := fetchEntries(t, "application/atom+xml", []byte(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Updates</title>
+  <entry>
+    <title type="html">Road closed &lt;b&gt;tonight&lt;/b&gt;</title>
+    <link rel="enclosure" href="/media/map.png"/>
+    <link rel="alternate" href="/updates/42"/>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Lung Cheung Road</p></div></content>
+    <updated>2024-09-02T12:00:00Z</updated>
+  </entry>
+  <entry>
+    <title>Summary only</title>
+    <link href="https://example.org/updates/43"/>
+    <summary>Use the summary when there is no content</summary>
+    <published>2024-09-01T12:00:00Z</published>
+    <updated>2024-09-02T12:00:00Z</updated>
+  </entry>
+</feed>`))
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	first := entries[0]
+	if first.Title != "Road closed tonight" {
+		t.Errorf("title = %q", first.Title)
+	}
+	if first.Content != "Lung Cheung Road" {
+		t.Errorf("content = %q", first.Content)
+	}
+	if want := server.URL + "/updates/42"; first.URL != want {
+		t.Errorf("url = %q, want the alternate link %q", first.URL, want)
+	}
+	if want := time.Date(2024, 9, 2, 12, 0, 0, 0, time.UTC); first.PublishedAt == nil || !first.PublishedAt.Equal(want) {
+		t.Errorf("published = %v, want %v from updated", first.PublishedAt, want)
+	}
+
+	second := entries[1]
+	if second.Content != "Use the summary when there is no content" {
+		t.Errorf("content = %q", second.Content)
+	}
+	if want := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC); second.PublishedAt == nil || !second.PublishedAt.Equal(want) {
+		t.Errorf("published = %v, want %v from published", second.PublishedAt, want)
+	}
+}
+
+func TestParseBig5(t *testing.T) {
+	// "颱風" and "沙田食水" encoded as Big5
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="Big5"?><rss version="2.0"><channel><item><title>`)
+	body.Write([]byte{0xbb, 0xe4, 0xad, 0xb7})
+	body.WriteString(`</title><description>`)
+	body.Write([]byte{0xa8, 0x46, 0xa5, 0xd0, 0xad, 0xb9, 0xa4, 0xf4})
+	body.WriteString(`</description><link>https://example.org/big5/1</link></item></channel></rss>`)
+
+	entries, _ := fetchEntries(t, "application/rss+xml", body.Bytes())
+
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if entries[0].Title != "颱風" || entries[0].Content != "沙田食水" {
+		t.Errorf("entry = %+v, want Big5 text decoded to UTF-8", entries[0])
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	entries, err := Parse(bytes.NewReader([]byte(`<html><body>Not a feed</body></html>`)), nil)
+	if err != ErrUnknownFormat {
+		t.Errorf("Parse = %v, %v; want ErrUnknownFormat", entries, err)
+	}
+}
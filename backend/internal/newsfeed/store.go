@@ -0,0 +1,150 @@
+package newsfeed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"hkers-backend/internal/config"
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/pgerr"
+	"hkers-backend/internal/news"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// newsStore is the part of the database that storing feed entries uses. Writes
+// return pgx.ErrNoRows when they change nothing, as the queries do.
+type newsStore interface {
+	GetNewsByURL(ctx context.Context, url pgtype.Text) (db.News, error)
+	GetNewsByContentHash(ctx context.Context, contentHash pgtype.Text) (db.News, error)
+	IngestNews(ctx context.Context, arg db.IngestNewsParams) (db.News, error)
+	UpdateIngestedNews(ctx context.Context, arg db.UpdateIngestedNewsParams) (db.News, error)
+}
+
+// dbNewsStore reads news directly and writes it through the runner, so that
+// ingested items are audited like any other change.
+type dbNewsStore struct {
+	*db.Queries
+	runner *dbtx.Runner
+}
+
+func (s dbNewsStore) IngestNews(ctx context.Context, arg db.IngestNewsParams) (db.News, error) {
+	return dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.News, error) {
+		return q.IngestNews(ctx, arg)
+	})
+}
+
+func (s dbNewsStore) UpdateIngestedNews(ctx context.Context, arg db.UpdateIngestedNewsParams) (db.News, error) {
+	return dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.News, error) {
+		return q.UpdateIngestedNews(ctx, arg)
+	})
+}
+
+// storeCounts tallies what storing a feed's entries changed.
+type storeCounts struct {
+	added   int
+	updated int
+	retry   int // Linked documents that failed and are fetched again on the next poll
+}
+
+// ingest parses a fetched document according to the feed's format and stores it.
+func (w *Worker) ingest(ctx context.Context, feed config.NewsFeedConfig, result *fetchResult) (storeCounts, error) {
+	if feed.Format == config.NewsFeedFormatCAP {
+		return w.storeCAP(ctx, feed, result)
+	}
+	entries, err := Parse(bytes.NewReader(result.Body), result.URL)
+	if err != nil {
+		return storeCounts{}, err
+	}
+	return w.store(ctx, feed, entries)
+}
+
+// store upserts a feed's entries into news, with tags suggested from their
+// text. An entry is the same item as an
+// existing one with its URL or, failing that, its content hash. Items from the
+// feed's source are refreshed when their entry changes; items from elsewhere,
+// such as ones added by editors, are left alone.
+func (w *Worker) store(ctx context.Context, feed config.NewsFeedConfig, entries []Entry) (storeCounts, error) {
+	var counts storeCounts
+	source := truncate(feed.Source, maxSourceLength)
+
+	for _, entry := range entries {
+		hash := pgtype.Text{String: news.ContentHash(entry.Title, entry.Content), Valid: true}
+		published := pgtype.Timestamptz{}
+		if entry.PublishedAt != nil {
+			published = pgtype.Timestamptz{Time: *entry.PublishedAt, Valid: true}
+		}
+
+		if entry.URL != "" {
+			existing, err := w.news.GetNewsByURL(ctx, optionalText(entry.URL))
+			if err == nil {
+				if existing.Source != source {
+					continue
+				}
+				changed, err := w.refresh(ctx, existing.ID, entry, hash, published)
+				if err != nil {
+					return counts, err
+				}
+				if changed {
+					counts.updated++
+				}
+				continue
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return counts, err
+			}
+		}
+
+		if _, err := w.news.GetNewsByContentHash(ctx, hash); err == nil {
+			continue
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return counts, err
+		}
+
+		_, err := w.news.IngestNews(ctx, db.IngestNewsParams{
+			Source:      source,
+			Title:       entry.Title,
+			Content:     optionalText(entry.Content),
+			Url:         optionalText(entry.URL),
+			PublishedAt: published,
+			RelevantTo:  news.SuggestTags(entry.Title, entry.Content).Encode(),
+			ContentHash: hash,
+		})
+		switch {
+		case err == nil:
+			counts.added++
+		case errors.Is(err, pgx.ErrNoRows):
+			// Stored concurrently, e.g. by another feed carrying the same story
+		default:
+			return counts, err
+		}
+	}
+	return counts, nil
+}
+
+// refresh updates an ingested item from its entry, reporting whether anything
+// changed. Its tags are suggested again unless an editor has reviewed them.
+func (w *Worker) refresh(ctx context.Context, id int32, entry Entry, hash pgtype.Text, published pgtype.Timestamptz) (bool, error) {
+	_, err := w.news.UpdateIngestedNews(ctx, db.UpdateIngestedNewsParams{
+		Title:       entry.Title,
+		Content:     optionalText(entry.Content),
+		PublishedAt: published,
+		ContentHash: hash,
+		RelevantTo:  news.SuggestTags(entry.Title, entry.Content).Encode(),
+		ID:          id,
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, pgx.ErrNoRows):
+		return false, nil
+	case pgerr.IsUniqueViolation(err):
+		// The new content duplicates another item; keep the old version
+		return false, nil
+	default:
+		return false, err
+	}
+}
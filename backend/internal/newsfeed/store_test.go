@@ -0,0 +1,213 @@
+package newsfeed
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"hkers-backend/internal/config"
+	"hkers-backend/internal/news"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// fakeNewsStore keeps news in memory, with the unique URL and content hash of
+// the news table.
+type fakeNewsStore struct {
+	items []db.News
+}
+
+func (s *fakeNewsStore) GetNewsByURL(_ context.Context, url pgtype.Text) (db.News, error) {
+	for _, item := range s.items {
+		if item.Url.Valid && item.Url == url {
+			return item, nil
+		}
+	}
+	return db.News{}, pgx.ErrNoRows
+}
+
+func (s *fakeNewsStore) GetNewsByContentHash(_ context.Context, contentHash pgtype.Text) (db.News, error) {
+	for _, item := range s.items {
+		if item.ContentHash == contentHash {
+			return item, nil
+		}
+	}
+	return db.News{}, pgx.ErrNoRows
+}
+
+func (s *fakeNewsStore) IngestNews(_ context.Context, arg db.IngestNewsParams) (db.News, error) {
+	for _, item := range s.items {
+		if (arg.Url.Valid && item.Url == arg.Url) || item.ContentHash == arg.ContentHash {
+			return db.News{}, pgx.ErrNoRows // ON CONFLICT DO NOTHING
+		}
+	}
+	item := db.News{
+		ID:          int32(len(s.items) + 1),
+		Source:      arg.Source,
+		Title:       arg.Title,
+		Content:     arg.Content,
+		Url:         arg.Url,
+		PublishedAt: arg.PublishedAt,
+		RelevantTo:  arg.RelevantTo,
+		ContentHash: arg.ContentHash,
+	}
+	s.items = append(s.items, item)
+	return item, nil
+}
+
+func (s *fakeNewsStore) UpdateIngestedNews(_ context.Context, arg db.UpdateIngestedNewsParams) (db.News, error) {
+	for i, item := range s.items {
+		if item.ID != arg.ID {
+			continue
+		}
+		if item.ContentHash == arg.ContentHash && item.PublishedAt == arg.PublishedAt {
+			return db.News{}, pgx.ErrNoRows
+		}
+		for _, other := range s.items {
+			if other.ID != item.ID && other.ContentHash == arg.ContentHash {
+				return db.News{}, &pgconn.PgError{Code: "23505"}
+			}
+		}
+		item.Title, item.Content, item.PublishedAt, item.ContentHash = arg.Title, arg.Content, arg.PublishedAt, arg.ContentHash
+		s.items[i] = item
+		return item, nil
+	}
+	return db.News{}, pgx.ErrNoRows
+}
+
+func newTestWorker(store *fakeNewsStore) *Worker {
+	return &Worker{news: store}
+}
+
+var testFeedConfig = config.NewsFeedConfig{Name: "press", Source: "Press releases", Format: config.NewsFeedFormatFeed}
+
+func TestStoreDeduplicatesByURL(t *testing.T) {
+	store := &fakeNewsStore{}
+	w := newTestWorker(store)
+	ctx := context.Background()
+	entry := Entry{Title: "Shelter opens", Content: "At the community hall", URL: "https://example.org/1"}
+
+	counts, err := w.store(ctx, testFeedConfig, []Entry{entry})
+	if err != nil || counts.added != 1 {
+		t.Fatalf("first store = %+v, %v; want 1 added", counts, err)
+	}
+
+	counts, err = w.store(ctx, testFeedConfig, []Entry{entry})
+	if err != nil || counts != (storeCounts{}) {
+		t.Fatalf("unchanged entry = %+v, %v; want nothing changed", counts, err)
+	}
+
+	entry.Content = "At the community hall, open until 10pm"
+	counts, err = w.store(ctx, testFeedConfig, []Entry{entry})
+	if err != nil || counts != (storeCounts{updated: 1}) {
+		t.Fatalf("changed entry = %+v, %v; want 1 updated", counts, err)
+	}
+	if len(store.items) != 1 || store.items[0].Content.String != entry.Content {
+		t.Errorf("items = %+v, want the one item refreshed", store.items)
+	}
+}
+
+func TestStoreLeavesOtherSourcesAlone(t *testing.T) {
+	store := &fakeNewsStore{}
+	w := newTestWorker(store)
+	ctx := context.Background()
+	entry := Entry{Title: "Road closed", Content: "Until noon", URL: "https://example.org/2"}
+
+	editor := config.NewsFeedConfig{Name: "other", Source: "Editor"}
+	if _, err := w.store(ctx, editor, []Entry{entry}); err != nil {
+		t.Fatal(err)
+	}
+
+	entry.Content = "Until 3pm"
+	counts, err := w.store(ctx, testFeedConfig, []Entry{entry})
+	if err != nil || counts != (storeCounts{}) {
+		t.Fatalf("store = %+v, %v; want an item from another source left alone", counts, err)
+	}
+	if store.items[0].Content.String != "Until noon" {
+		t.Errorf("content = %q, want it unchanged", store.items[0].Content.String)
+	}
+}
+
+func TestStoreDeduplicatesByContentHash(t *testing.T) {
+	store := &fakeNewsStore{}
+	w := newTestWorker(store)
+
+	entries := []Entry{
+		{Title: "Red rainstorm warning", Content: "Stay indoors.", URL: "https://example.org/en/3"},
+		// Same story under another URL, differing only in case and spacing
+		{Title: "red  rainstorm warning", Content: "Stay   indoors.", URL: "https://mirror.example.org/3"},
+		// Same story without a URL
+		{Title: "Red rainstorm warning", Content: "Stay indoors."},
+		{Title: "Red rainstorm warning cancelled", Content: "Stay indoors."},
+	}
+	counts, err := w.store(context.Background(), testFeedConfig, entries)
+	if err != nil || counts.added != 2 {
+		t.Fatalf("store = %+v, %v; want 2 added", counts, err)
+	}
+	if len(store.items) != 2 || store.items[0].Url.String != "https://example.org/en/3" {
+		t.Errorf("items = %+v, want the first copy kept", store.items)
+	}
+}
+
+func TestStoreKeepsOldVersionOnDuplicateRefresh(t *testing.T) {
+	store := &fakeNewsStore{}
+	w := newTestWorker(store)
+	ctx := context.Background()
+
+	entries := []Entry{
+		{Title: "Water suspended", Content: "Tai Po", URL: "https://example.org/4"},
+		{Title: "Water restored", Content: "Tai Po", URL: "https://example.org/5"},
+	}
+	if _, err := w.store(ctx, testFeedConfig, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first entry now repeats the second one's text
+	entries[0].Title = "Water restored"
+	counts, err := w.store(ctx, testFeedConfig, entries[:1])
+	if err != nil || counts != (storeCounts{}) {
+		t.Fatalf("store = %+v, %v; want the duplicate refresh skipped", counts, err)
+	}
+	if store.items[0].Title != "Water suspended" {
+		t.Errorf("title = %q, want the old version kept", store.items[0].Title)
+	}
+}
+
+func TestIngestFetchedFeed(t *testing.T) {
+	server := serveFeed(t, "application/rss+xml", []byte(`<rss version="2.0"><channel>
+<item><title>Typhoon signal No. 8</title><link>/warnings/8</link><description>Sha Tin shelters open</description></item>
+<item><title>Typhoon signal No. 8</title><link>/warnings/8</link><description>Sha Tin shelters open</description></item>
+</channel></rss>`))
+	store := &fakeNewsStore{}
+	w := newTestWorker(store)
+	ctx := context.Background()
+
+	result, err := fetch(ctx, server.Client(), "test", server.URL+"/rss.xml", "", "")
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	counts, err := w.ingest(ctx, testFeedConfig, result)
+	if err != nil || counts.added != 1 {
+		t.Fatalf("ingest = %+v, %v; want 1 added", counts, err)
+	}
+
+	item := store.items[0]
+	if item.Source != testFeedConfig.Source || item.Url.String != server.URL+"/warnings/8" {
+		t.Errorf("item = %+v", item)
+	}
+	if tags := decodeTestTags(t, item.RelevantTo); len(tags.Districts) != 1 || tags.Districts[0] != "ST" {
+		t.Errorf("districts = %v, want [ST] suggested from the text", tags.Districts)
+	}
+}
+
+func decodeTestTags(t *testing.T, raw []byte) news.Tags {
+	t.Helper()
+	var tags news.Tags
+	if err := json.Unmarshal(raw, &tags); err != nil {
+		t.Fatalf("relevant_to %s: %v", raw, err)
+	}
+	return tags
+}
@@ -0,0 +1,218 @@
+package newsfeed
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"hkers-backend/internal/config"
+	"hkers-backend/internal/core/dbtx"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// stateRetryDelay is how long a feed waits after its polling state could not be read.
+const stateRetryDelay = time.Minute
+
+// Worker polls the configured feeds and stores their entries in news. Polling
+// state lives in news_feeds, so cache validators and backoff survive restarts
+// and several instances can run workers without fetching a feed twice.
+type Worker struct {
+	queries *db.Queries
+	runner  *dbtx.Runner
+	news    newsStore
+	client  *http.Client
+	cfg     config.NewsConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorker creates a worker for the feeds in cfg. It does nothing until started.
+func NewWorker(pool *pgxpool.Pool, cfg *config.NewsConfig) *Worker {
+	queries := db.New(pool)
+	runner := dbtx.New(pool)
+	return &Worker{
+		queries: queries,
+		runner:  runner,
+		news:    dbNewsStore{Queries: queries, runner: runner},
+		client:  &http.Client{Timeout: cfg.FetchTimeout},
+		cfg:     *cfg,
+	}
+}
+
+// Start registers the configured feeds and polls each one in its own goroutine
+// until Stop is called or ctx is cancelled.
+func (w *Worker) Start(ctx context.Context) error {
+	if len(w.cfg.Feeds) == 0 {
+		return nil
+	}
+
+	for _, feed := range w.cfg.Feeds {
+		if err := w.queries.RegisterNewsFeed(ctx, db.RegisterNewsFeedParams{
+			Name: feed.Name,
+			Url:  feed.URL,
+		}); err != nil {
+			return err
+		}
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	for _, feed := range w.cfg.Feeds {
+		w.wg.Add(1)
+		go w.run(ctx, feed)
+	}
+	return nil
+}
+
+// Stop stops polling and waits for fetches in progress to finish.
+func (w *Worker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *Worker) run(ctx context.Context, feed config.NewsFeedConfig) {
+	defer w.wg.Done()
+
+	// Changes made while ingesting are attributed to the feed in the audit log
+	ctx = dbtx.WithRequestID(ctx, "newsfeed-"+feed.Name)
+
+	for {
+		timer := time.NewTimer(w.poll(ctx, feed))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// poll fetches the feed if it is due and returns how long to wait before
+// looking at it again.
+func (w *Worker) poll(ctx context.Context, feed config.NewsFeedConfig) time.Duration {
+	state, err := w.queries.ClaimNewsFeed(ctx, db.ClaimNewsFeedParams{
+		LeaseSeconds: w.lease().Seconds(),
+		Name:         feed.Name,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Not due yet, or another instance is polling it
+		state, err = w.queries.GetNewsFeed(ctx, feed.Name)
+		if err == nil {
+			return max(time.Until(state.NextFetchAt.Time), time.Second)
+		}
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("news feed %s: failed to read polling state: %v", feed.Name, err)
+		}
+		return stateRetryDelay
+	}
+
+	result, err := fetch(ctx, w.client, w.cfg.UserAgent, state.Url, state.Etag.String, state.LastModified.String)
+	var stored storeCounts
+	if err == nil && !result.NotModified {
+		stored, err = w.ingest(ctx, feed, result)
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the lease expires and the next start polls again
+		return 0
+	}
+
+	// Polling state is not audited, so it is written directly rather than
+	// through the runner
+	if err != nil {
+		status := fetchStatus(err)
+		if result != nil {
+			// The feed was fetched, but could not be parsed or stored
+			status = result.Status
+		}
+		delay := backoff(feed.Interval, w.cfg.MaxBackoff, state.ConsecutiveFailures+1, retryAfter(err))
+		log.Printf("news feed %s: poll failed (attempt %d, retrying in %s): %v",
+			feed.Name, state.ConsecutiveFailures+1, delay.Round(time.Second), err)
+		if recordErr := w.queries.RecordNewsFeedFailure(ctx, db.RecordNewsFeedFailureParams{
+			LastStatus:   optionalStatus(status),
+			LastError:    pgtype.Text{String: err.Error(), Valid: true},
+			DelaySeconds: delay.Seconds(),
+			Name:         feed.Name,
+		}); recordErr != nil {
+			log.Printf("news feed %s: failed to record failure: %v", feed.Name, recordErr)
+		}
+		return delay
+	}
+
+	if stored.added > 0 || stored.updated > 0 {
+		log.Printf("news feed %s: %d new, %d updated", feed.Name, stored.added, stored.updated)
+	}
+	etag, lastModified := result.ETag, result.LastModified
+	if stored.retry > 0 {
+		// Drop the validators so that the next poll gets the index in full and
+		// retries the documents that failed, rather than a 304
+		etag, lastModified = "", ""
+	}
+	if err := w.queries.RecordNewsFeedSuccess(ctx, db.RecordNewsFeedSuccessParams{
+		Etag:         optionalText(etag),
+		LastModified: optionalText(lastModified),
+		LastStatus:   optionalStatus(result.Status),
+		DelaySeconds: feed.Interval.Seconds(),
+		Name:         feed.Name,
+	}); err != nil {
+		log.Printf("news feed %s: failed to record success: %v", feed.Name, err)
+	}
+	return feed.Interval
+}
+
+// lease is how long a claimed feed is held by the instance polling it. It
+// outlasts a fetch, so a feed is only polled twice if an instance dies mid-poll.
+func (w *Worker) lease() time.Duration {
+	return 2*w.cfg.FetchTimeout + time.Minute
+}
+
+// backoff returns the delay before retrying a feed after consecutive failures.
+// It doubles from the polling interval up to maxBackoff (or the interval, if
+// longer), honours a longer Retry-After, and adds up to 10% jitter so feeds
+// that failed together do not retry together.
+func backoff(interval, maxBackoff time.Duration, failures int32, retryAfter time.Duration) time.Duration {
+	limit := max(maxBackoff, interval)
+	delay := interval
+	for i := int32(1); i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	delay = min(delay, limit)
+	delay = max(delay, retryAfter)
+	return delay + time.Duration(rand.Int63n(int64(delay/10)+1))
+}
+
+func retryAfter(err error) time.Duration {
+	var fetchErr *fetchError
+	if errors.As(err, &fetchErr) {
+		return fetchErr.RetryAfter
+	}
+	return 0
+}
+
+func fetchStatus(err error) int {
+	var fetchErr *fetchError
+	if errors.As(err, &fetchErr) {
+		return fetchErr.Status
+	}
+	return 0
+}
+
+func optionalStatus(status int) pgtype.Int4 {
+	return pgtype.Int4{Int32: int32(status), Valid: status > 0}
+}
+
+func optionalText(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
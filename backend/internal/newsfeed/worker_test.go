@@ -0,0 +1,35 @@
+package newsfeed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		interval   time.Duration
+		maxBackoff time.Duration
+		failures   int32
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{"first failure waits one interval", 5 * time.Minute, time.Hour, 1, 0, 5 * time.Minute},
+		{"doubles per failure", 5 * time.Minute, time.Hour, 3, 0, 20 * time.Minute},
+		{"capped at max backoff", 5 * time.Minute, time.Hour, 10, 0, time.Hour},
+		{"interval above max backoff", 2 * time.Hour, time.Hour, 4, 0, 2 * time.Hour},
+		{"shorter Retry-After is ignored", 5 * time.Minute, time.Hour, 3, time.Minute, 20 * time.Minute},
+		{"longer Retry-After wins", 5 * time.Minute, time.Hour, 1, 30 * time.Minute, 30 * time.Minute},
+		{"Retry-After may exceed max backoff", 5 * time.Minute, time.Hour, 1, 2 * time.Hour, 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				got := backoff(tt.interval, tt.maxBackoff, tt.failures, tt.retryAfter)
+				if got < tt.want || got > tt.want+tt.want/10 {
+					t.Fatalf("backoff = %s, want %s plus at most 10%% jitter", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// Handler handles role and permission administration HTTP requests.
+type Handler struct {
+	rbacService ServiceInterface
+}
+
+// NewHandler creates a new RBAC Handler instance.
+func NewHandler(rbacService ServiceInterface) HandlerInterface {
+	return &Handler{
+		rbacService: rbacService,
+	}
+}
+
+// createRoleRequest is the body accepted when creating a role.
+type createRoleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+// ListRoles returns every role with its permissions.
+// GET /api/v1/admin/roles
+func (h *Handler) ListRoles(ctx *gin.Context) {
+	roles, err := h.rbacService.ListRoles(ctx.Request.Context())
+	if err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to list roles")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"roles": roles,
+	})
+}
+
+// GetRole returns a role with its permissions.
+// GET /api/v1/admin/roles/:id
+func (h *Handler) GetRole(ctx *gin.Context) {
+	roleID, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	role, err := h.rbacService.GetRole(ctx.Request.Context(), roleID)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to get role")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, role)
+}
+
+// CreateRole creates a role with no permissions. The name must be an app_role value.
+// POST /api/v1/admin/roles
+func (h *Handler) CreateRole(ctx *gin.Context) {
+	var req createRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if !requireUser(ctx) {
+		return
+	}
+
+	role, err := h.rbacService.CreateRole(ctx.Request.Context(), db.AppRole(req.Name), req.Description)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to create role")
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, role)
+}
+
+// AddPermissionToRole grants a permission to a role.
+// PUT /api/v1/admin/roles/:id/permissions/:permission
+func (h *Handler) AddPermissionToRole(ctx *gin.Context) {
+	roleID, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+	if !requireUser(ctx) {
+		return
+	}
+
+	role, err := h.rbacService.AddPermissionToRole(ctx.Request.Context(), roleID, db.AppPermission(ctx.Param("permission")))
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to add permission")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, role)
+}
+
+// RemovePermissionFromRole revokes a permission from a role.
+// DELETE /api/v1/admin/roles/:id/permissions/:permission
+func (h *Handler) RemovePermissionFromRole(ctx *gin.Context) {
+	roleID, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+	if !requireUser(ctx) {
+		return
+	}
+
+	role, err := h.rbacService.RemovePermissionFromRole(ctx.Request.Context(), roleID, db.AppPermission(ctx.Param("permission")))
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to remove permission")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, role)
+}
+
+// ListRoleUsers returns the users holding a role.
+// GET /api/v1/admin/roles/:id/users
+func (h *Handler) ListRoleUsers(ctx *gin.Context) {
+	roleID, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	users, err := h.rbacService.ListRoleUsers(ctx.Request.Context(), roleID)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to list role users")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"users": users,
+	})
+}
+
+// GetUserRoles returns the roles held by a user.
+// GET /api/v1/admin/users/:id/roles
+func (h *Handler) GetUserRoles(ctx *gin.Context) {
+	userID, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	roles, err := h.rbacService.GetUserRoles(ctx.Request.Context(), userID)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to get user roles")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"roles": roles,
+	})
+}
+
+// AssignRoleToUser grants a role to a user.
+// PUT /api/v1/admin/users/:id/roles/:roleId
+func (h *Handler) AssignRoleToUser(ctx *gin.Context) {
+	h.changeUserRole(ctx, h.rbacService.AssignRoleToUser, "Failed to assign role")
+}
+
+// RemoveRoleFromUser revokes a role from a user. The last active admin keeps the admin role.
+// DELETE /api/v1/admin/users/:id/roles/:roleId
+func (h *Handler) RemoveRoleFromUser(ctx *gin.Context) {
+	h.changeUserRole(ctx, h.rbacService.RemoveRoleFromUser, "Failed to remove role")
+}
+
+// changeUserRole parses a user role change and applies it, returning the user's roles afterwards.
+func (h *Handler) changeUserRole(
+	ctx *gin.Context,
+	apply func(ctx context.Context, userID, roleID int32) error,
+	failureMessage string,
+) {
+	userID, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+	roleID, ok := parseID(ctx, "roleId")
+	if !ok {
+		return
+	}
+	if !requireUser(ctx) {
+		return
+	}
+
+	if err := apply(ctx.Request.Context(), userID, roleID); err != nil {
+		writeServiceError(ctx, err, failureMessage)
+		return
+	}
+
+	roles, err := h.rbacService.GetUserRoles(ctx.Request.Context(), userID)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to get user roles")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"roles": roles,
+	})
+}
+
+// parseID reads a positive int32 path parameter, writing a 400 response if it is invalid.
+func parseID(ctx *gin.Context, name string) (int32, bool) {
+	id, err := strconv.ParseInt(ctx.Param(name), 10, 32)
+	if err != nil || id <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid "+name)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// requireUser checks that an RBAC change is made by a user. Changes are always
+// attributed to a user in the audit log, so API keys cannot make them.
+func requireUser(ctx *gin.Context) bool {
+	if _, ok := middleware.GetUserIDFromContext(ctx); !ok {
+		response.Error(ctx, http.StatusForbidden, "Role changes can only be made by users")
+		return false
+	}
+	return true
+}
+
+// writeServiceError maps service errors to HTTP responses.
+func writeServiceError(ctx *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrUserNotFound), errors.Is(err, ErrPermissionNotFound):
+		response.Error(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidRole):
+		response.Error(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrRoleExists), errors.Is(err, ErrLastAdmin), errors.Is(err, ErrAdminLockout):
+		response.Error(ctx, http.StatusConflict, err.Error())
+	default:
+		response.Error(ctx, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,40 @@
+package rbac
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// ServiceInterface defines the interface for RBAC services
+type ServiceInterface interface {
+	GetPrincipal(ctx context.Context, userID int32) (*response.Principal, error)
+	GetUserPermissions(ctx context.Context, userID int32) ([]db.AppPermission, error)
+	HasPermission(ctx context.Context, userID int32, permission db.AppPermission) (bool, error)
+	ListRoles(ctx context.Context) ([]Role, error)
+	GetRole(ctx context.Context, roleID int32) (*Role, error)
+	CreateRole(ctx context.Context, name db.AppRole, description string) (*Role, error)
+	AddPermissionToRole(ctx context.Context, roleID int32, permission db.AppPermission) (*Role, error)
+	RemovePermissionFromRole(ctx context.Context, roleID int32, permission db.AppPermission) (*Role, error)
+	ListRoleUsers(ctx context.Context, roleID int32) ([]db.User, error)
+	GetUserRoles(ctx context.Context, userID int32) ([]db.Role, error)
+	AssignRoleToUser(ctx context.Context, userID, roleID int32) error
+	RemoveRoleFromUser(ctx context.Context, userID, roleID int32) error
+	InvalidatePrincipal(ctx context.Context, userID int32) error
+}
+
+// HandlerInterface defines the interface for role administration HTTP handlers
+type HandlerInterface interface {
+	ListRoles(ctx *gin.Context)
+	GetRole(ctx *gin.Context)
+	CreateRole(ctx *gin.Context)
+	AddPermissionToRole(ctx *gin.Context)
+	RemovePermissionFromRole(ctx *gin.Context)
+	ListRoleUsers(ctx *gin.Context)
+	GetUserRoles(ctx *gin.Context)
+	AssignRoleToUser(ctx *gin.Context)
+	RemoveRoleFromUser(ctx *gin.Context)
+}
@@ -0,0 +1,337 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"hkers-backend/internal/core/pgerr"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleExists         = errors.New("role already exists")
+	ErrInvalidRole        = errors.New("unknown role name")
+	ErrPermissionNotFound = errors.New("permission not found")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrLastAdmin          = errors.New("cannot remove the last active admin")
+	ErrAdminLockout       = errors.New("cannot remove assign_roles from the admin role")
+)
+
+// Role is a role together with the permissions it grants.
+type Role struct {
+	ID          int32              `json:"id"`
+	Name        db.AppRole         `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	Permissions []db.AppPermission `json:"permissions"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+// ListRoles returns every role with its permissions.
+func (s *Service) ListRoles(ctx context.Context) ([]Role, error) {
+	rows, err := s.queries.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]Role, 0, len(rows))
+	for _, row := range rows {
+		role, err := s.withPermissions(ctx, s.queries, row)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, nil
+}
+
+// GetRole returns a role with its permissions.
+func (s *Service) GetRole(ctx context.Context, roleID int32) (*Role, error) {
+	row, err := s.queries.GetRoleByID(ctx, roleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return s.withPermissions(ctx, s.queries, row)
+}
+
+// CreateRole creates a role with no permissions. Role names are the app_role
+// enum, so only a name defined in the schema that has no role yet can be created.
+func (s *Service) CreateRole(ctx context.Context, name db.AppRole, description string) (*Role, error) {
+	if !isKnownRole(name) {
+		return nil, ErrInvalidRole
+	}
+
+	var role *Role
+	err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		row, err := q.CreateRole(ctx, db.CreateRoleParams{
+			Name:        name,
+			Description: pgtype.Text{String: description, Valid: description != ""},
+		})
+		if err != nil {
+			if pgerr.IsUniqueViolation(err) {
+				return ErrRoleExists
+			}
+			return err
+		}
+		role, err = s.withPermissions(ctx, q, row)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// AddPermissionToRole grants a permission to every holder of the role.
+// Adding a permission the role already has is a no-op.
+func (s *Service) AddPermissionToRole(ctx context.Context, roleID int32, permission db.AppPermission) (*Role, error) {
+	var role *Role
+	err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		row, perm, err := s.lookupRolePermission(ctx, q, roleID, permission)
+		if err != nil {
+			return err
+		}
+		if _, err := q.AssignPermissionToRole(ctx, db.AssignPermissionToRoleParams{
+			RoleID:       row.ID,
+			PermissionID: perm.ID,
+		}); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		role, err = s.withPermissions(ctx, q, row)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateRoleHolders(ctx, roleID)
+	return role, nil
+}
+
+// RemovePermissionFromRole revokes a permission from every holder of the role.
+// The admin role always keeps assign_roles, so admins can never lock themselves out.
+func (s *Service) RemovePermissionFromRole(ctx context.Context, roleID int32, permission db.AppPermission) (*Role, error) {
+	var role *Role
+	err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		row, perm, err := s.lookupRolePermission(ctx, q, roleID, permission)
+		if err != nil {
+			return err
+		}
+		if row.Name == db.AppRoleAdmin && perm.Name == db.AppPermissionAssignRoles {
+			return ErrAdminLockout
+		}
+		if err := q.RemovePermissionFromRole(ctx, db.RemovePermissionFromRoleParams{
+			RoleID:       row.ID,
+			PermissionID: perm.ID,
+		}); err != nil {
+			return err
+		}
+		role, err = s.withPermissions(ctx, q, row)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateRoleHolders(ctx, roleID)
+	return role, nil
+}
+
+// ListRoleUsers returns the users holding a role.
+func (s *Service) ListRoleUsers(ctx context.Context, roleID int32) ([]db.User, error) {
+	if _, err := s.queries.GetRoleByID(ctx, roleID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	users, err := s.queries.GetUsersWithRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []db.User{}
+	}
+	return users, nil
+}
+
+// GetUserRoles returns the roles held by a user.
+func (s *Service) GetUserRoles(ctx context.Context, userID int32) ([]db.Role, error) {
+	if _, err := s.queries.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	roles, err := s.queries.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if roles == nil {
+		roles = []db.Role{}
+	}
+	return roles, nil
+}
+
+// AssignRoleToUser grants a role to a user.
+// Assigning a role the user already holds is a no-op.
+func (s *Service) AssignRoleToUser(ctx context.Context, userID, roleID int32) error {
+	err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		if _, err := s.lookupUserRole(ctx, q, userID, roleID); err != nil {
+			return err
+		}
+		if _, err := q.AssignRoleToUser(ctx, db.AssignRoleToUserParams{
+			UserID: userID,
+			RoleID: roleID,
+		}); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateAfterWrite(ctx, userID)
+	return nil
+}
+
+// RemoveRoleFromUser revokes a role from a user.
+// The last active admin cannot lose the admin role.
+func (s *Service) RemoveRoleFromUser(ctx context.Context, userID, roleID int32) error {
+	err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		user, err := s.lookupUserRole(ctx, q, userID, roleID)
+		if err != nil {
+			return err
+		}
+
+		// The role row lock serializes concurrent removals, so two admins
+		// cannot demote each other at the same time
+		role, err := q.LockRole(ctx, roleID)
+		if err != nil {
+			return err
+		}
+		if role.Name == db.AppRoleAdmin && user.IsActive.Bool {
+			held, err := holdsRole(ctx, q, userID, roleID)
+			if err != nil {
+				return err
+			}
+			admins, err := q.CountActiveUsersWithRole(ctx, roleID)
+			if err != nil {
+				return err
+			}
+			if held && admins <= 1 {
+				return ErrLastAdmin
+			}
+		}
+
+		return q.RemoveRoleFromUser(ctx, db.RemoveRoleFromUserParams{
+			UserID: userID,
+			RoleID: roleID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateAfterWrite(ctx, userID)
+	return nil
+}
+
+func (s *Service) withPermissions(ctx context.Context, q *db.Queries, row db.Role) (*Role, error) {
+	permissions, err := q.GetRolePermissions(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &Role{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: row.Description,
+		Permissions: make([]db.AppPermission, 0, len(permissions)),
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	for _, permission := range permissions {
+		role.Permissions = append(role.Permissions, permission.Name)
+	}
+	return role, nil
+}
+
+// lookupRolePermission resolves a role by ID and a permission by name.
+func (s *Service) lookupRolePermission(ctx context.Context, q *db.Queries, roleID int32, permission db.AppPermission) (db.Role, db.Permission, error) {
+	role, err := q.GetRoleByID(ctx, roleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Role{}, db.Permission{}, ErrRoleNotFound
+		}
+		return db.Role{}, db.Permission{}, err
+	}
+
+	perm, err := q.GetPermissionByName(ctx, permission)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || pgerr.IsInvalidTextInput(err) {
+			return db.Role{}, db.Permission{}, ErrPermissionNotFound
+		}
+		return db.Role{}, db.Permission{}, err
+	}
+	return role, perm, nil
+}
+
+// lookupUserRole checks that both the user and the role exist.
+func (s *Service) lookupUserRole(ctx context.Context, q *db.Queries, userID, roleID int32) (db.User, error) {
+	user, err := q.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrUserNotFound
+		}
+		return db.User{}, err
+	}
+	if _, err := q.GetRoleByID(ctx, roleID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrRoleNotFound
+		}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+// invalidateRoleHolders drops the cached principals of everyone holding the role
+// after its permissions change. Failures are logged rather than returned because
+// the write itself succeeded.
+func (s *Service) invalidateRoleHolders(ctx context.Context, roleID int32) {
+	users, err := s.queries.GetUsersWithRole(ctx, roleID)
+	if err != nil {
+		log.Printf("rbac: failed to list holders of role %d for cache invalidation: %v", roleID, err)
+		return
+	}
+	for _, user := range users {
+		s.invalidateAfterWrite(ctx, user.ID)
+	}
+}
+
+func holdsRole(ctx context.Context, q *db.Queries, userID, roleID int32) (bool, error) {
+	roles, err := q.GetUserRoles(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role.ID == roleID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isKnownRole(name db.AppRole) bool {
+	switch name {
+	case db.AppRoleAdmin, db.AppRoleManager, db.AppRoleUser:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,35 @@
+package rbac
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// RegisterRBACRoutes registers role and permission administration routes on the given router.
+func RegisterRBACRoutes(router *gin.Engine, rbacSvc ServiceInterface, jwtManager response.JWTManager) {
+	h := NewHandler(rbacSvc)
+
+	// Role administration routes - every change is audited with the acting admin
+	roles := router.Group("/api/v1/admin/roles")
+	roles.Use(middleware.JWTAuth(jwtManager), middleware.RequirePermission(db.AppPermissionAssignRoles))
+	{
+		roles.GET("", h.ListRoles)
+		roles.POST("", h.CreateRole)
+		roles.GET("/:id", h.GetRole)
+		roles.GET("/:id/users", h.ListRoleUsers)
+		roles.PUT("/:id/permissions/:permission", h.AddPermissionToRole)
+		roles.DELETE("/:id/permissions/:permission", h.RemovePermissionFromRole)
+	}
+
+	// User role assignment routes
+	users := router.Group("/api/v1/admin/users")
+	users.Use(middleware.JWTAuth(jwtManager), middleware.RequirePermission(db.AppPermissionAssignRoles))
+	{
+		users.GET("/:id/roles", h.GetUserRoles)
+		users.PUT("/:id/roles/:roleId", h.AssignRoleToUser)
+		users.DELETE("/:id/roles/:roleId", h.RemoveRoleFromUser)
+	}
+}
@@ -0,0 +1,167 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/response"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// principalCacheKeyPrefix namespaces cached principals in Redis.
+const principalCacheKeyPrefix = "rbac:principal:"
+
+// Service resolves users' live status, roles and permissions.
+type Service struct {
+	queries  *db.Queries
+	runner   *dbtx.Runner
+	redis    *redis.Client
+	cacheTTL time.Duration
+}
+
+// NewService creates a new RBAC service instance.
+// Principals are cached in Redis for cacheTTL; a nil client or a
+// non-positive TTL disables caching.
+func NewService(pool *pgxpool.Pool, redisClient *redis.Client, cacheTTL time.Duration) *Service {
+	return &Service{
+		queries:  db.New(pool),
+		runner:   dbtx.New(pool),
+		redis:    redisClient,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// GetPrincipal returns the user's current status, roles and permissions.
+// The result is cached until InvalidatePrincipal is called or the TTL passes.
+func (s *Service) GetPrincipal(ctx context.Context, userID int32) (*response.Principal, error) {
+	if cached, ok := s.getCachedPrincipal(ctx, userID); ok {
+		return cached, nil
+	}
+
+	user, err := s.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, response.ErrPrincipalNotFound
+		}
+		return nil, err
+	}
+
+	roles, err := s.queries.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.queries.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []db.AppPermission{}
+	}
+
+	principal := &response.Principal{
+		UserID:         user.ID,
+		Email:          user.Email.String,
+		Username:       user.Username,
+		OIDCSub:        user.OidcSub,
+		IsActive:       user.IsActive.Bool,
+		ApprovalStatus: user.ApprovalStatus,
+		Roles:          make([]db.AppRole, 0, len(roles)),
+		Permissions:    permissions,
+	}
+	for _, role := range roles {
+		principal.Roles = append(principal.Roles, role.Name)
+	}
+
+	s.setCachedPrincipal(ctx, principal)
+	return principal, nil
+}
+
+// GetUserPermissions returns every permission granted to the user through their roles.
+func (s *Service) GetUserPermissions(ctx context.Context, userID int32) ([]db.AppPermission, error) {
+	principal, err := s.GetPrincipal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return principal.Permissions, nil
+}
+
+// HasPermission reports whether the user has the given permission.
+func (s *Service) HasPermission(ctx context.Context, userID int32, permission db.AppPermission) (bool, error) {
+	return s.queries.CheckUserPermission(ctx, db.CheckUserPermissionParams{
+		ID:   userID,
+		Name: permission,
+	})
+}
+
+// InvalidatePrincipal drops the cached principal for a user.
+// Call it after changing a user's status or roles, or a role's permissions.
+func (s *Service) InvalidatePrincipal(ctx context.Context, userID int32) error {
+	if !s.cacheEnabled() {
+		return nil
+	}
+	return s.redis.Del(ctx, principalCacheKey(userID)).Err()
+}
+
+func (s *Service) cacheEnabled() bool {
+	return s.redis != nil && s.cacheTTL > 0
+}
+
+// getCachedPrincipal reads a principal from Redis. Cache failures are
+// treated as misses and the principal is loaded from the database, so a Redis
+// outage costs only latency here. The token revocation check, which has no
+// such fallback, fails with 503 instead (see auth.JWTManager.checkRevoked).
+func (s *Service) getCachedPrincipal(ctx context.Context, userID int32) (*response.Principal, bool) {
+	if !s.cacheEnabled() {
+		return nil, false
+	}
+
+	raw, err := s.redis.Get(ctx, principalCacheKey(userID)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("rbac: failed to read principal cache for user %d: %v", userID, err)
+		}
+		return nil, false
+	}
+
+	var principal response.Principal
+	if err := json.Unmarshal(raw, &principal); err != nil {
+		return nil, false
+	}
+	return &principal, true
+}
+
+func (s *Service) setCachedPrincipal(ctx context.Context, principal *response.Principal) {
+	if !s.cacheEnabled() {
+		return
+	}
+
+	raw, err := json.Marshal(principal)
+	if err != nil {
+		return
+	}
+	if err := s.redis.Set(ctx, principalCacheKey(principal.UserID), raw, s.cacheTTL).Err(); err != nil {
+		log.Printf("rbac: failed to write principal cache for user %d: %v", principal.UserID, err)
+	}
+}
+
+// invalidateAfterWrite drops the cached principal after a successful write.
+// Failures are logged rather than returned because the write itself succeeded.
+func (s *Service) invalidateAfterWrite(ctx context.Context, userID int32) {
+	if err := s.InvalidatePrincipal(ctx, userID); err != nil {
+		log.Printf("rbac: failed to invalidate principal cache for user %d: %v", userID, err)
+	}
+}
+
+func principalCacheKey(userID int32) string {
+	return principalCacheKeyPrefix + strconv.FormatInt(int64(userID), 10)
+}
@@ -12,7 +12,7 @@ import (
 )
 
 const countAuditLogs = `-- name: CountAuditLogs :one
-SELECT COUNT(*) FROM rbac_audit_logs
+SELECT COUNT(*) FROM audit_logs
 `
 
 func (q *Queries) CountAuditLogs(ctx context.Context) (int64, error) {
@@ -22,74 +22,334 @@ func (q *Queries) CountAuditLogs(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countAuditLogsFiltered = `-- name: CountAuditLogsFiltered :one
+SELECT COUNT(*) FROM audit_logs
+WHERE ($1::text IS NULL OR table_name = $1::text)
+  AND ($2::text IS NULL OR record_id = $2::text)
+  AND ($3::text IS NULL OR action = $3::text)
+  AND ($4::integer IS NULL OR changed_by = $4::integer)
+  AND ($5::text IS NULL OR request_id = $5::text)
+  AND ($6::timestamptz IS NULL OR changed_at >= $6::timestamptz)
+  AND ($7::timestamptz IS NULL OR changed_at <= $7::timestamptz)
+`
+
+type CountAuditLogsFilteredParams struct {
+	TableName   pgtype.Text        `json:"table_name"`
+	RecordID    pgtype.Text        `json:"record_id"`
+	Action      pgtype.Text        `json:"action"`
+	ChangedBy   pgtype.Int4        `json:"changed_by"`
+	RequestID   pgtype.Text        `json:"request_id"`
+	ChangedFrom pgtype.Timestamptz `json:"changed_from"`
+	ChangedTo   pgtype.Timestamptz `json:"changed_to"`
+}
+
+func (q *Queries) CountAuditLogsFiltered(ctx context.Context, arg CountAuditLogsFilteredParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAuditLogsFiltered,
+		arg.TableName,
+		arg.RecordID,
+		arg.Action,
+		arg.ChangedBy,
+		arg.RequestID,
+		arg.ChangedFrom,
+		arg.ChangedTo,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAuditCheckpoint = `-- name: CreateAuditCheckpoint :one
+INSERT INTO audit_checkpoints (first_id, last_id, entry_count, prev_hash, last_hash, entries, signature)
+SELECT
+    $1::integer,
+    $2::integer,
+    $3::integer,
+    $4::text,
+    $5::text,
+    jsonb_agg(to_jsonb(a) ORDER BY a.id),
+    $6::text
+FROM audit_logs a
+WHERE a.id BETWEEN $1::integer AND $2::integer
+RETURNING id, first_id, last_id, entry_count, prev_hash, last_hash, signature, created_at
+`
+
+type CreateAuditCheckpointParams struct {
+	FirstID    int32       `json:"first_id"`
+	LastID     int32       `json:"last_id"`
+	EntryCount int32       `json:"entry_count"`
+	PrevHash   pgtype.Text `json:"prev_hash"`
+	LastHash   string      `json:"last_hash"`
+	Signature  string      `json:"signature"`
+}
+
+type CreateAuditCheckpointRow struct {
+	ID         int32              `json:"id"`
+	FirstID    int32              `json:"first_id"`
+	LastID     int32              `json:"last_id"`
+	EntryCount int32              `json:"entry_count"`
+	PrevHash   pgtype.Text        `json:"prev_hash"`
+	LastHash   string             `json:"last_hash"`
+	Signature  string             `json:"signature"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+// Archives the entries first_id..last_id into a checkpoint.
+func (q *Queries) CreateAuditCheckpoint(ctx context.Context, arg CreateAuditCheckpointParams) (CreateAuditCheckpointRow, error) {
+	row := q.db.QueryRow(ctx, createAuditCheckpoint,
+		arg.FirstID,
+		arg.LastID,
+		arg.EntryCount,
+		arg.PrevHash,
+		arg.LastHash,
+		arg.Signature,
+	)
+	var i CreateAuditCheckpointRow
+	err := row.Scan(
+		&i.ID,
+		&i.FirstID,
+		&i.LastID,
+		&i.EntryCount,
+		&i.PrevHash,
+		&i.LastHash,
+		&i.Signature,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createAuditLog = `-- name: CreateAuditLog :one
-INSERT INTO rbac_audit_logs (table_name, action, old_data, new_data, changed_by)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, table_name, action, old_data, new_data, changed_by, changed_at
+INSERT INTO audit_logs (table_name, record_id, action, old_data, new_data, changed_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, table_name, record_id, action, old_data, new_data, changed_by, changed_at, request_id, prev_hash, row_hash
 `
 
 type CreateAuditLogParams struct {
 	TableName string      `json:"table_name"`
+	RecordID  pgtype.Text `json:"record_id"`
 	Action    string      `json:"action"`
 	OldData   []byte      `json:"old_data"`
 	NewData   []byte      `json:"new_data"`
 	ChangedBy pgtype.Int4 `json:"changed_by"`
 }
 
-func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (RbacAuditLog, error) {
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
 	row := q.db.QueryRow(ctx, createAuditLog,
 		arg.TableName,
+		arg.RecordID,
 		arg.Action,
 		arg.OldData,
 		arg.NewData,
 		arg.ChangedBy,
 	)
-	var i RbacAuditLog
+	var i AuditLog
 	err := row.Scan(
 		&i.ID,
 		&i.TableName,
+		&i.RecordID,
 		&i.Action,
 		&i.OldData,
 		&i.NewData,
 		&i.ChangedBy,
 		&i.ChangedAt,
+		&i.RequestID,
+		&i.PrevHash,
+		&i.RowHash,
 	)
 	return i, err
 }
 
-const deleteOldAuditLogs = `-- name: DeleteOldAuditLogs :exec
-DELETE FROM rbac_audit_logs WHERE changed_at < $1
+const deleteAuditLogsThrough = `-- name: DeleteAuditLogsThrough :execrows
+DELETE FROM audit_logs WHERE id <= $1
 `
 
-func (q *Queries) DeleteOldAuditLogs(ctx context.Context, changedAt pgtype.Timestamptz) error {
-	_, err := q.db.Exec(ctx, deleteOldAuditLogs, changedAt)
-	return err
+func (q *Queries) DeleteAuditLogsThrough(ctx context.Context, lastID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAuditLogsThrough, lastID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getAuditLogByID = `-- name: GetAuditLogByID :one
 
-SELECT id, table_name, action, old_data, new_data, changed_by, changed_at FROM rbac_audit_logs WHERE id = $1 LIMIT 1
+SELECT id, table_name, record_id, action, old_data, new_data, changed_by, changed_at, request_id, prev_hash, row_hash FROM audit_logs WHERE id = $1 LIMIT 1
 `
 
 // internal/db/queries/audit.sql
-// SQL queries for RBAC audit log operations (used by sqlc)
-func (q *Queries) GetAuditLogByID(ctx context.Context, id int32) (RbacAuditLog, error) {
+// SQL queries for audit log operations (used by sqlc)
+func (q *Queries) GetAuditLogByID(ctx context.Context, id int32) (AuditLog, error) {
 	row := q.db.QueryRow(ctx, getAuditLogByID, id)
-	var i RbacAuditLog
+	var i AuditLog
 	err := row.Scan(
 		&i.ID,
 		&i.TableName,
+		&i.RecordID,
 		&i.Action,
 		&i.OldData,
 		&i.NewData,
 		&i.ChangedBy,
 		&i.ChangedAt,
+		&i.RequestID,
+		&i.PrevHash,
+		&i.RowHash,
 	)
 	return i, err
 }
 
+const getAuditLogPruneBoundary = `-- name: GetAuditLogPruneBoundary :one
+SELECT COALESCE(
+    (SELECT MIN(id) - 1 FROM audit_logs WHERE changed_at >= $1::timestamptz),
+    (SELECT MAX(id) FROM audit_logs),
+    0
+)::integer AS last_id
+`
+
+// Last ID of the oldest entries changed before the cutoff. Pruning removes the start of
+// the chain, so it stops at the first entry changed at or after the cutoff.
+func (q *Queries) GetAuditLogPruneBoundary(ctx context.Context, cutoff pgtype.Timestamptz) (int32, error) {
+	row := q.db.QueryRow(ctx, getAuditLogPruneBoundary, cutoff)
+	var last_id int32
+	err := row.Scan(&last_id)
+	return last_id, err
+}
+
+const listAuditChainLinks = `-- name: ListAuditChainLinks :many
+SELECT a.id, a.prev_hash, a.row_hash, audit_log_hash(a)::text AS computed_hash
+FROM audit_logs a
+WHERE a.id > $1 AND a.id <= $2
+ORDER BY a.id
+LIMIT $3
+`
+
+type ListAuditChainLinksParams struct {
+	AfterID   int32 `json:"after_id"`
+	ThroughID int32 `json:"through_id"`
+	BatchSize int32 `json:"batch_size"`
+}
+
+type ListAuditChainLinksRow struct {
+	ID           int32       `json:"id"`
+	PrevHash     pgtype.Text `json:"prev_hash"`
+	RowHash      string      `json:"row_hash"`
+	ComputedHash string      `json:"computed_hash"`
+}
+
+// Chain links in ID order, each with the hash recomputed from the entry's contents.
+func (q *Queries) ListAuditChainLinks(ctx context.Context, arg ListAuditChainLinksParams) ([]ListAuditChainLinksRow, error) {
+	rows, err := q.db.Query(ctx, listAuditChainLinks, arg.AfterID, arg.ThroughID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAuditChainLinksRow
+	for rows.Next() {
+		var i ListAuditChainLinksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PrevHash,
+			&i.RowHash,
+			&i.ComputedHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditCheckpointLinks = `-- name: ListAuditCheckpointLinks :many
+SELECT e.id, e.prev_hash, e.row_hash, audit_log_hash(e)::text AS computed_hash
+FROM audit_checkpoints c, jsonb_populate_recordset(NULL::audit_logs, c.entries) e
+WHERE c.id = $1
+ORDER BY e.id
+`
+
+type ListAuditCheckpointLinksRow struct {
+	ID           int32       `json:"id"`
+	PrevHash     pgtype.Text `json:"prev_hash"`
+	RowHash      string      `json:"row_hash"`
+	ComputedHash string      `json:"computed_hash"`
+}
+
+// Archived links of a checkpoint in ID order, each with the hash recomputed from the
+// archived contents, so that edits to audit_checkpoints.entries show up as a break.
+func (q *Queries) ListAuditCheckpointLinks(ctx context.Context, id int32) ([]ListAuditCheckpointLinksRow, error) {
+	rows, err := q.db.Query(ctx, listAuditCheckpointLinks, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAuditCheckpointLinksRow
+	for rows.Next() {
+		var i ListAuditCheckpointLinksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PrevHash,
+			&i.RowHash,
+			&i.ComputedHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditCheckpoints = `-- name: ListAuditCheckpoints :many
+SELECT id, first_id, last_id, entry_count, prev_hash, last_hash, signature, created_at
+FROM audit_checkpoints
+ORDER BY last_id
+`
+
+type ListAuditCheckpointsRow struct {
+	ID         int32              `json:"id"`
+	FirstID    int32              `json:"first_id"`
+	LastID     int32              `json:"last_id"`
+	EntryCount int32              `json:"entry_count"`
+	PrevHash   pgtype.Text        `json:"prev_hash"`
+	LastHash   string             `json:"last_hash"`
+	Signature  string             `json:"signature"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+// Checkpoints oldest first, without their archived entries.
+func (q *Queries) ListAuditCheckpoints(ctx context.Context) ([]ListAuditCheckpointsRow, error) {
+	rows, err := q.db.Query(ctx, listAuditCheckpoints)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAuditCheckpointsRow
+	for rows.Next() {
+		var i ListAuditCheckpointsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstID,
+			&i.LastID,
+			&i.EntryCount,
+			&i.PrevHash,
+			&i.LastHash,
+			&i.Signature,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAuditLogs = `-- name: ListAuditLogs :many
-SELECT id, table_name, action, old_data, new_data, changed_by, changed_at FROM rbac_audit_logs
+SELECT id, table_name, record_id, action, old_data, new_data, changed_by, changed_at, request_id, prev_hash, row_hash FROM audit_logs
 ORDER BY changed_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -99,23 +359,27 @@ type ListAuditLogsParams struct {
 	Offset int32 `json:"offset"`
 }
 
-func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]RbacAuditLog, error) {
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
 	rows, err := q.db.Query(ctx, listAuditLogs, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []RbacAuditLog
+	var items []AuditLog
 	for rows.Next() {
-		var i RbacAuditLog
+		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
 			&i.TableName,
+			&i.RecordID,
 			&i.Action,
 			&i.OldData,
 			&i.NewData,
 			&i.ChangedBy,
 			&i.ChangedAt,
+			&i.RequestID,
+			&i.PrevHash,
+			&i.RowHash,
 		); err != nil {
 			return nil, err
 		}
@@ -128,7 +392,7 @@ func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([
 }
 
 const listAuditLogsByAction = `-- name: ListAuditLogsByAction :many
-SELECT id, table_name, action, old_data, new_data, changed_by, changed_at FROM rbac_audit_logs
+SELECT id, table_name, record_id, action, old_data, new_data, changed_by, changed_at, request_id, prev_hash, row_hash FROM audit_logs
 WHERE action = $1
 ORDER BY changed_at DESC
 LIMIT $2 OFFSET $3
@@ -140,23 +404,27 @@ type ListAuditLogsByActionParams struct {
 	Offset int32  `json:"offset"`
 }
 
-func (q *Queries) ListAuditLogsByAction(ctx context.Context, arg ListAuditLogsByActionParams) ([]RbacAuditLog, error) {
+func (q *Queries) ListAuditLogsByAction(ctx context.Context, arg ListAuditLogsByActionParams) ([]AuditLog, error) {
 	rows, err := q.db.Query(ctx, listAuditLogsByAction, arg.Action, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []RbacAuditLog
+	var items []AuditLog
 	for rows.Next() {
-		var i RbacAuditLog
+		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
 			&i.TableName,
+			&i.RecordID,
 			&i.Action,
 			&i.OldData,
 			&i.NewData,
 			&i.ChangedBy,
 			&i.ChangedAt,
+			&i.RequestID,
+			&i.PrevHash,
+			&i.RowHash,
 		); err != nil {
 			return nil, err
 		}
@@ -169,7 +437,7 @@ func (q *Queries) ListAuditLogsByAction(ctx context.Context, arg ListAuditLogsBy
 }
 
 const listAuditLogsByTable = `-- name: ListAuditLogsByTable :many
-SELECT id, table_name, action, old_data, new_data, changed_by, changed_at FROM rbac_audit_logs
+SELECT id, table_name, record_id, action, old_data, new_data, changed_by, changed_at, request_id, prev_hash, row_hash FROM audit_logs
 WHERE table_name = $1
 ORDER BY changed_at DESC
 LIMIT $2 OFFSET $3
@@ -181,23 +449,27 @@ type ListAuditLogsByTableParams struct {
 	Offset    int32  `json:"offset"`
 }
 
-func (q *Queries) ListAuditLogsByTable(ctx context.Context, arg ListAuditLogsByTableParams) ([]RbacAuditLog, error) {
+func (q *Queries) ListAuditLogsByTable(ctx context.Context, arg ListAuditLogsByTableParams) ([]AuditLog, error) {
 	rows, err := q.db.Query(ctx, listAuditLogsByTable, arg.TableName, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []RbacAuditLog
+	var items []AuditLog
 	for rows.Next() {
-		var i RbacAuditLog
+		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
 			&i.TableName,
+			&i.RecordID,
 			&i.Action,
 			&i.OldData,
 			&i.NewData,
 			&i.ChangedBy,
 			&i.ChangedAt,
+			&i.RequestID,
+			&i.PrevHash,
+			&i.RowHash,
 		); err != nil {
 			return nil, err
 		}
@@ -210,7 +482,7 @@ func (q *Queries) ListAuditLogsByTable(ctx context.Context, arg ListAuditLogsByT
 }
 
 const listAuditLogsByUser = `-- name: ListAuditLogsByUser :many
-SELECT id, table_name, action, old_data, new_data, changed_by, changed_at FROM rbac_audit_logs
+SELECT id, table_name, record_id, action, old_data, new_data, changed_by, changed_at, request_id, prev_hash, row_hash FROM audit_logs
 WHERE changed_by = $1
 ORDER BY changed_at DESC
 LIMIT $2 OFFSET $3
@@ -222,23 +494,95 @@ type ListAuditLogsByUserParams struct {
 	Offset    int32       `json:"offset"`
 }
 
-func (q *Queries) ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUserParams) ([]RbacAuditLog, error) {
+func (q *Queries) ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUserParams) ([]AuditLog, error) {
 	rows, err := q.db.Query(ctx, listAuditLogsByUser, arg.ChangedBy, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []RbacAuditLog
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TableName,
+			&i.RecordID,
+			&i.Action,
+			&i.OldData,
+			&i.NewData,
+			&i.ChangedBy,
+			&i.ChangedAt,
+			&i.RequestID,
+			&i.PrevHash,
+			&i.RowHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogsFiltered = `-- name: ListAuditLogsFiltered :many
+SELECT id, table_name, record_id, action, old_data, new_data, changed_by, changed_at, request_id, prev_hash, row_hash FROM audit_logs
+WHERE ($1::text IS NULL OR table_name = $1::text)
+  AND ($2::text IS NULL OR record_id = $2::text)
+  AND ($3::text IS NULL OR action = $3::text)
+  AND ($4::integer IS NULL OR changed_by = $4::integer)
+  AND ($5::text IS NULL OR request_id = $5::text)
+  AND ($6::timestamptz IS NULL OR changed_at >= $6::timestamptz)
+  AND ($7::timestamptz IS NULL OR changed_at <= $7::timestamptz)
+ORDER BY changed_at DESC, id DESC
+LIMIT $8 OFFSET $9
+`
+
+type ListAuditLogsFilteredParams struct {
+	TableName   pgtype.Text        `json:"table_name"`
+	RecordID    pgtype.Text        `json:"record_id"`
+	Action      pgtype.Text        `json:"action"`
+	ChangedBy   pgtype.Int4        `json:"changed_by"`
+	RequestID   pgtype.Text        `json:"request_id"`
+	ChangedFrom pgtype.Timestamptz `json:"changed_from"`
+	ChangedTo   pgtype.Timestamptz `json:"changed_to"`
+	RowLimit    int32              `json:"row_limit"`
+	RowOffset   int32              `json:"row_offset"`
+}
+
+// Combines the ListAuditLogsBy* filters; a NULL filter matches every row.
+func (q *Queries) ListAuditLogsFiltered(ctx context.Context, arg ListAuditLogsFilteredParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditLogsFiltered,
+		arg.TableName,
+		arg.RecordID,
+		arg.Action,
+		arg.ChangedBy,
+		arg.RequestID,
+		arg.ChangedFrom,
+		arg.ChangedTo,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
 	for rows.Next() {
-		var i RbacAuditLog
+		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
 			&i.TableName,
+			&i.RecordID,
 			&i.Action,
 			&i.OldData,
 			&i.NewData,
 			&i.ChangedBy,
 			&i.ChangedAt,
+			&i.RequestID,
+			&i.PrevHash,
+			&i.RowHash,
 		); err != nil {
 			return nil, err
 		}
@@ -251,7 +595,7 @@ func (q *Queries) ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUs
 }
 
 const listAuditLogsInDateRange = `-- name: ListAuditLogsInDateRange :many
-SELECT id, table_name, action, old_data, new_data, changed_by, changed_at FROM rbac_audit_logs
+SELECT id, table_name, record_id, action, old_data, new_data, changed_by, changed_at, request_id, prev_hash, row_hash FROM audit_logs
 WHERE changed_at >= $1 AND changed_at <= $2
 ORDER BY changed_at DESC
 LIMIT $3 OFFSET $4
@@ -264,7 +608,7 @@ type ListAuditLogsInDateRangeParams struct {
 	Offset      int32              `json:"offset"`
 }
 
-func (q *Queries) ListAuditLogsInDateRange(ctx context.Context, arg ListAuditLogsInDateRangeParams) ([]RbacAuditLog, error) {
+func (q *Queries) ListAuditLogsInDateRange(ctx context.Context, arg ListAuditLogsInDateRangeParams) ([]AuditLog, error) {
 	rows, err := q.db.Query(ctx, listAuditLogsInDateRange,
 		arg.ChangedAt,
 		arg.ChangedAt_2,
@@ -275,17 +619,21 @@ func (q *Queries) ListAuditLogsInDateRange(ctx context.Context, arg ListAuditLog
 		return nil, err
 	}
 	defer rows.Close()
-	var items []RbacAuditLog
+	var items []AuditLog
 	for rows.Next() {
-		var i RbacAuditLog
+		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
 			&i.TableName,
+			&i.RecordID,
 			&i.Action,
 			&i.OldData,
 			&i.NewData,
 			&i.ChangedBy,
 			&i.ChangedAt,
+			&i.RequestID,
+			&i.PrevHash,
+			&i.RowHash,
 		); err != nil {
 			return nil, err
 		}
@@ -296,3 +644,31 @@ func (q *Queries) ListAuditLogsInDateRange(ctx context.Context, arg ListAuditLog
 	}
 	return items, nil
 }
+
+const lockAuditChain = `-- name: LockAuditChain :exec
+SELECT pg_advisory_xact_lock(hashtext('audit_logs_chain'))
+`
+
+// Holds off appends to the chain (see chain_audit_log) until the transaction ends.
+func (q *Queries) LockAuditChain(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockAuditChain)
+	return err
+}
+
+const setAuditContext = `-- name: SetAuditContext :exec
+SELECT
+    set_config('app.current_user_id', $1::text, true),
+    set_config('app.request_id', $2::text, true)
+`
+
+type SetAuditContextParams struct {
+	UserID    string `json:"user_id"`
+	RequestID string `json:"request_id"`
+}
+
+// Attribute the current transaction's changes for audit triggers (SET LOCAL semantics).
+// Empty values are recorded as NULL.
+func (q *Queries) SetAuditContext(ctx context.Context, arg SetAuditContextParams) error {
+	_, err := q.db.Exec(ctx, setAuditContext, arg.UserID, arg.RequestID)
+	return err
+}
@@ -174,6 +174,29 @@ func (q *Queries) GetCheckinWithDetails(ctx context.Context, id int32) (GetCheck
 	return i, err
 }
 
+const getDistanceToStation = `-- name: GetDistanceToStation :one
+SELECT ST_Distance(
+    location,
+    ST_SetSRID(ST_MakePoint($1::float8, $2::float8), 4326)::geography
+)::float8 AS distance_meters
+FROM supply_stations
+WHERE id = $3
+`
+
+type GetDistanceToStationParams struct {
+	Lng       float64 `json:"lng"`
+	Lat       float64 `json:"lat"`
+	StationID int32   `json:"station_id"`
+}
+
+// Distance in meters between a station and the given point, used to verify on-scene check-ins
+func (q *Queries) GetDistanceToStation(ctx context.Context, arg GetDistanceToStationParams) (float64, error) {
+	row := q.db.QueryRow(ctx, getDistanceToStation, arg.Lng, arg.Lat, arg.StationID)
+	var distance_meters float64
+	err := row.Scan(&distance_meters)
+	return distance_meters, err
+}
+
 const hasUserCheckedInAtStation = `-- name: HasUserCheckedInAtStation :one
 SELECT EXISTS (
     SELECT 1 FROM checkins
@@ -26,7 +26,7 @@ const countDonationsByStatus = `-- name: CountDonationsByStatus :one
 SELECT COUNT(*) FROM donations WHERE status = $1
 `
 
-func (q *Queries) CountDonationsByStatus(ctx context.Context, status pgtype.Text) (int64, error) {
+func (q *Queries) CountDonationsByStatus(ctx context.Context, status DonationStatus) (int64, error) {
 	row := q.db.QueryRow(ctx, countDonationsByStatus, status)
 	var count int64
 	err := row.Scan(&count)
@@ -44,7 +44,7 @@ type CreateDonationParams struct {
 	StationID         pgtype.Int4        `json:"station_id"`
 	Supplies          []byte             `json:"supplies"`
 	DeliveryCode      string             `json:"delivery_code"`
-	Status            pgtype.Text        `json:"status"`
+	Status            DonationStatus     `json:"status"`
 	EstimatedDelivery pgtype.Timestamptz `json:"estimated_delivery"`
 }
 
@@ -72,6 +72,53 @@ func (q *Queries) CreateDonation(ctx context.Context, arg CreateDonationParams)
 	return i, err
 }
 
+const createDonationStatusHistory = `-- name: CreateDonationStatusHistory :one
+INSERT INTO donation_status_history (donation_id, from_status, to_status, changed_by, location, note)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    ST_SetSRID(ST_MakePoint($5::float8, $6::float8), 4326)::geography,
+    $7
+)
+RETURNING id, donation_id, from_status, to_status, changed_by, location, note, changed_at
+`
+
+type CreateDonationStatusHistoryParams struct {
+	DonationID int32              `json:"donation_id"`
+	FromStatus NullDonationStatus `json:"from_status"`
+	ToStatus   DonationStatus     `json:"to_status"`
+	ChangedBy  pgtype.Int4        `json:"changed_by"`
+	Lng        pgtype.Float8      `json:"lng"`
+	Lat        pgtype.Float8      `json:"lat"`
+	Note       pgtype.Text        `json:"note"`
+}
+
+func (q *Queries) CreateDonationStatusHistory(ctx context.Context, arg CreateDonationStatusHistoryParams) (DonationStatusHistory, error) {
+	row := q.db.QueryRow(ctx, createDonationStatusHistory,
+		arg.DonationID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.ChangedBy,
+		arg.Lng,
+		arg.Lat,
+		arg.Note,
+	)
+	var i DonationStatusHistory
+	err := row.Scan(
+		&i.ID,
+		&i.DonationID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.ChangedBy,
+		&i.Location,
+		&i.Note,
+		&i.ChangedAt,
+	)
+	return i, err
+}
+
 const deleteDonation = `-- name: DeleteDonation :exec
 DELETE FROM donations WHERE id = $1
 `
@@ -142,7 +189,7 @@ type GetDonationWithDetailsRow struct {
 	StationID         pgtype.Int4        `json:"station_id"`
 	Supplies          []byte             `json:"supplies"`
 	DeliveryCode      string             `json:"delivery_code"`
-	Status            pgtype.Text        `json:"status"`
+	Status            DonationStatus     `json:"status"`
 	EstimatedDelivery pgtype.Timestamptz `json:"estimated_delivery"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
@@ -169,6 +216,41 @@ func (q *Queries) GetDonationWithDetails(ctx context.Context, id int32) (GetDona
 	return i, err
 }
 
+const listDonationStatusHistory = `-- name: ListDonationStatusHistory :many
+SELECT id, donation_id, from_status, to_status, changed_by, location, note, changed_at FROM donation_status_history
+WHERE donation_id = $1
+ORDER BY changed_at, id
+`
+
+func (q *Queries) ListDonationStatusHistory(ctx context.Context, donationID int32) ([]DonationStatusHistory, error) {
+	rows, err := q.db.Query(ctx, listDonationStatusHistory, donationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DonationStatusHistory
+	for rows.Next() {
+		var i DonationStatusHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.DonationID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.ChangedBy,
+			&i.Location,
+			&i.Note,
+			&i.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listDonations = `-- name: ListDonations :many
 SELECT id, donor_id, station_id, supplies, delivery_code, status, estimated_delivery, created_at, updated_at FROM donations
 ORDER BY created_at DESC
@@ -290,9 +372,9 @@ LIMIT $2 OFFSET $3
 `
 
 type ListDonationsByStatusParams struct {
-	Status pgtype.Text `json:"status"`
-	Limit  int32       `json:"limit"`
-	Offset int32       `json:"offset"`
+	Status DonationStatus `json:"status"`
+	Limit  int32          `json:"limit"`
+	Offset int32          `json:"offset"`
 }
 
 func (q *Queries) ListDonationsByStatus(ctx context.Context, arg ListDonationsByStatusParams) ([]Donation, error) {
@@ -325,6 +407,38 @@ func (q *Queries) ListDonationsByStatus(ctx context.Context, arg ListDonationsBy
 	return items, nil
 }
 
+const transitionDonationStatus = `-- name: TransitionDonationStatus :one
+UPDATE donations
+SET status = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2 AND status = $3
+RETURNING id, donor_id, station_id, supplies, delivery_code, status, estimated_delivery, created_at, updated_at
+`
+
+type TransitionDonationStatusParams struct {
+	ToStatus   DonationStatus `json:"to_status"`
+	ID         int32          `json:"id"`
+	FromStatus DonationStatus `json:"from_status"`
+}
+
+// Compare-and-set status change; returns no rows if the status changed concurrently
+func (q *Queries) TransitionDonationStatus(ctx context.Context, arg TransitionDonationStatusParams) (Donation, error) {
+	row := q.db.QueryRow(ctx, transitionDonationStatus, arg.ToStatus, arg.ID, arg.FromStatus)
+	var i Donation
+	err := row.Scan(
+		&i.ID,
+		&i.DonorID,
+		&i.StationID,
+		&i.Supplies,
+		&i.DeliveryCode,
+		&i.Status,
+		&i.EstimatedDelivery,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateDonation = `-- name: UpdateDonation :one
 UPDATE donations
 SET supplies = $2,
@@ -338,7 +452,7 @@ RETURNING id, donor_id, station_id, supplies, delivery_code, status, estimated_d
 type UpdateDonationParams struct {
 	ID                int32              `json:"id"`
 	Supplies          []byte             `json:"supplies"`
-	Status            pgtype.Text        `json:"status"`
+	Status            DonationStatus     `json:"status"`
 	EstimatedDelivery pgtype.Timestamptz `json:"estimated_delivery"`
 }
 
@@ -373,8 +487,8 @@ RETURNING id, donor_id, station_id, supplies, delivery_code, status, estimated_d
 `
 
 type UpdateDonationStatusParams struct {
-	ID     int32       `json:"id"`
-	Status pgtype.Text `json:"status"`
+	ID     int32          `json:"id"`
+	Status DonationStatus `json:"status"`
 }
 
 func (q *Queries) UpdateDonationStatus(ctx context.Context, arg UpdateDonationStatusParams) (Donation, error) {
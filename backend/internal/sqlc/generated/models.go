@@ -11,6 +11,141 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AlertCertainty string
+
+const (
+	AlertCertaintyUnknown  AlertCertainty = "unknown"
+	AlertCertaintyUnlikely AlertCertainty = "unlikely"
+	AlertCertaintyPossible AlertCertainty = "possible"
+	AlertCertaintyLikely   AlertCertainty = "likely"
+	AlertCertaintyObserved AlertCertainty = "observed"
+)
+
+func (e *AlertCertainty) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AlertCertainty(s)
+	case string:
+		*e = AlertCertainty(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AlertCertainty: %T", src)
+	}
+	return nil
+}
+
+type NullAlertCertainty struct {
+	AlertCertainty AlertCertainty `json:"alert_certainty"`
+	Valid          bool           `json:"valid"` // Valid is true if AlertCertainty is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAlertCertainty) Scan(value interface{}) error {
+	if value == nil {
+		ns.AlertCertainty, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AlertCertainty.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAlertCertainty) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AlertCertainty), nil
+}
+
+type AlertSeverity string
+
+const (
+	AlertSeverityUnknown  AlertSeverity = "unknown"
+	AlertSeverityMinor    AlertSeverity = "minor"
+	AlertSeverityModerate AlertSeverity = "moderate"
+	AlertSeveritySevere   AlertSeverity = "severe"
+	AlertSeverityExtreme  AlertSeverity = "extreme"
+)
+
+func (e *AlertSeverity) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AlertSeverity(s)
+	case string:
+		*e = AlertSeverity(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AlertSeverity: %T", src)
+	}
+	return nil
+}
+
+type NullAlertSeverity struct {
+	AlertSeverity AlertSeverity `json:"alert_severity"`
+	Valid         bool          `json:"valid"` // Valid is true if AlertSeverity is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAlertSeverity) Scan(value interface{}) error {
+	if value == nil {
+		ns.AlertSeverity, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AlertSeverity.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAlertSeverity) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AlertSeverity), nil
+}
+
+type AlertUrgency string
+
+const (
+	AlertUrgencyUnknown   AlertUrgency = "unknown"
+	AlertUrgencyPast      AlertUrgency = "past"
+	AlertUrgencyFuture    AlertUrgency = "future"
+	AlertUrgencyExpected  AlertUrgency = "expected"
+	AlertUrgencyImmediate AlertUrgency = "immediate"
+)
+
+func (e *AlertUrgency) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AlertUrgency(s)
+	case string:
+		*e = AlertUrgency(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AlertUrgency: %T", src)
+	}
+	return nil
+}
+
+type NullAlertUrgency struct {
+	AlertUrgency AlertUrgency `json:"alert_urgency"`
+	Valid        bool         `json:"valid"` // Valid is true if AlertUrgency is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAlertUrgency) Scan(value interface{}) error {
+	if value == nil {
+		ns.AlertUrgency, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AlertUrgency.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAlertUrgency) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AlertUrgency), nil
+}
+
 type AppPermission string
 
 const (
@@ -38,6 +173,8 @@ const (
 	AppPermissionReadSupplyNeeds   AppPermission = "read_supply_needs"
 	AppPermissionUpdateSupplyNeeds AppPermission = "update_supply_needs"
 	AppPermissionDeleteSupplyNeeds AppPermission = "delete_supply_needs"
+	AppPermissionManageApiKeys     AppPermission = "manage_api_keys"
+	AppPermissionReadAuditLogs     AppPermission = "read_audit_logs"
 )
 
 func (e *AppPermission) Scan(src interface{}) error {
@@ -118,6 +255,178 @@ func (ns NullAppRole) Value() (driver.Value, error) {
 	return string(ns.AppRole), nil
 }
 
+type DonationStatus string
+
+const (
+	DonationStatusPending            DonationStatus = "pending"
+	DonationStatusInTransit          DonationStatus = "in_transit"
+	DonationStatusDelivered          DonationStatus = "delivered"
+	DonationStatusPartiallyDelivered DonationStatus = "partially_delivered"
+	DonationStatusCancelled          DonationStatus = "cancelled"
+	DonationStatusRejected           DonationStatus = "rejected"
+)
+
+func (e *DonationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DonationStatus(s)
+	case string:
+		*e = DonationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DonationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDonationStatus struct {
+	DonationStatus DonationStatus `json:"donation_status"`
+	Valid          bool           `json:"valid"` // Valid is true if DonationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDonationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DonationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DonationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDonationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DonationStatus), nil
+}
+
+type UrgencyLevel string
+
+const (
+	UrgencyLevelLow      UrgencyLevel = "low"
+	UrgencyLevelMedium   UrgencyLevel = "medium"
+	UrgencyLevelHigh     UrgencyLevel = "high"
+	UrgencyLevelCritical UrgencyLevel = "critical"
+)
+
+func (e *UrgencyLevel) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = UrgencyLevel(s)
+	case string:
+		*e = UrgencyLevel(s)
+	default:
+		return fmt.Errorf("unsupported scan type for UrgencyLevel: %T", src)
+	}
+	return nil
+}
+
+type NullUrgencyLevel struct {
+	UrgencyLevel UrgencyLevel `json:"urgency_level"`
+	Valid        bool         `json:"valid"` // Valid is true if UrgencyLevel is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullUrgencyLevel) Scan(value interface{}) error {
+	if value == nil {
+		ns.UrgencyLevel, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.UrgencyLevel.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullUrgencyLevel) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.UrgencyLevel), nil
+}
+
+type Alert struct {
+	ID            int32              `json:"id"`
+	NewsID        pgtype.Int4        `json:"news_id"`
+	Sender        string             `json:"sender"`
+	Identifier    string             `json:"identifier"`
+	SentAt        pgtype.Timestamptz `json:"sent_at"`
+	Status        string             `json:"status"`
+	MsgType       string             `json:"msg_type"`
+	Event         string             `json:"event"`
+	Headline      pgtype.Text        `json:"headline"`
+	Description   pgtype.Text        `json:"description"`
+	Instruction   pgtype.Text        `json:"instruction"`
+	Language      pgtype.Text        `json:"language"`
+	Categories    []string           `json:"categories"`
+	Severity      AlertSeverity      `json:"severity"`
+	Urgency       AlertUrgency       `json:"urgency"`
+	Certainty     AlertCertainty     `json:"certainty"`
+	EffectiveAt   pgtype.Timestamptz `json:"effective_at"`
+	OnsetAt       pgtype.Timestamptz `json:"onset_at"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	AreaDesc      pgtype.Text        `json:"area_desc"`
+	Area          interface{}        `json:"area"`
+	TerritoryWide bool               `json:"territory_wide"`
+	Geocodes      []byte             `json:"geocodes"`
+	Infos         []byte             `json:"infos"`
+	SourceUrl     pgtype.Text        `json:"source_url"`
+	SupersededAt  pgtype.Timestamptz `json:"superseded_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type AlertSourceFailure struct {
+	SourceUrl  string             `json:"source_url"`
+	FeedName   string             `json:"feed_name"`
+	EntryHash  string             `json:"entry_hash"`
+	LastStatus pgtype.Int4        `json:"last_status"`
+	LastError  string             `json:"last_error"`
+	FailedAt   pgtype.Timestamptz `json:"failed_at"`
+}
+
+type ApiKey struct {
+	ID                 int32              `json:"id"`
+	Name               string             `json:"name"`
+	Prefix             string             `json:"prefix"`
+	KeyHash            string             `json:"key_hash"`
+	RateLimitPerMinute int32              `json:"rate_limit_per_minute"`
+	CreatedBy          pgtype.Int4        `json:"created_by"`
+	LastUsedAt         pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt          pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+}
+
+type ApiKeyPermission struct {
+	ApiKeyID     int32 `json:"api_key_id"`
+	PermissionID int32 `json:"permission_id"`
+}
+
+type AuditCheckpoint struct {
+	ID         int32              `json:"id"`
+	FirstID    int32              `json:"first_id"`
+	LastID     int32              `json:"last_id"`
+	EntryCount int32              `json:"entry_count"`
+	PrevHash   pgtype.Text        `json:"prev_hash"`
+	LastHash   string             `json:"last_hash"`
+	Entries    []byte             `json:"entries"`
+	Signature  string             `json:"signature"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID        int32              `json:"id"`
+	TableName string             `json:"table_name"`
+	RecordID  pgtype.Text        `json:"record_id"`
+	Action    string             `json:"action"`
+	OldData   []byte             `json:"old_data"`
+	NewData   []byte             `json:"new_data"`
+	ChangedBy pgtype.Int4        `json:"changed_by"`
+	ChangedAt pgtype.Timestamptz `json:"changed_at"`
+	RequestID pgtype.Text        `json:"request_id"`
+	PrevHash  pgtype.Text        `json:"prev_hash"`
+	RowHash   string             `json:"row_hash"`
+}
+
 type Checkin struct {
 	ID              int32              `json:"id"`
 	UserID          pgtype.Int4        `json:"user_id"`
@@ -127,18 +436,36 @@ type Checkin struct {
 	Notes           pgtype.Text        `json:"notes"`
 }
 
+type District struct {
+	Code     string      `json:"code"`
+	NameEn   string      `json:"name_en"`
+	NameZh   string      `json:"name_zh"`
+	Boundary interface{} `json:"boundary"`
+}
+
 type Donation struct {
 	ID                int32              `json:"id"`
 	DonorID           pgtype.Int4        `json:"donor_id"`
 	StationID         pgtype.Int4        `json:"station_id"`
 	Supplies          []byte             `json:"supplies"`
 	DeliveryCode      string             `json:"delivery_code"`
-	Status            pgtype.Text        `json:"status"`
+	Status            DonationStatus     `json:"status"`
 	EstimatedDelivery pgtype.Timestamptz `json:"estimated_delivery"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
 }
 
+type DonationStatusHistory struct {
+	ID         int32              `json:"id"`
+	DonationID int32              `json:"donation_id"`
+	FromStatus NullDonationStatus `json:"from_status"`
+	ToStatus   DonationStatus     `json:"to_status"`
+	ChangedBy  pgtype.Int4        `json:"changed_by"`
+	Location   interface{}        `json:"location"`
+	Note       pgtype.Text        `json:"note"`
+	ChangedAt  pgtype.Timestamptz `json:"changed_at"`
+}
+
 type News struct {
 	ID          int32              `json:"id"`
 	Source      string             `json:"source"`
@@ -148,6 +475,21 @@ type News struct {
 	PublishedAt pgtype.Timestamptz `json:"published_at"`
 	FetchedAt   pgtype.Timestamptz `json:"fetched_at"`
 	RelevantTo  []byte             `json:"relevant_to"`
+	ContentHash pgtype.Text        `json:"content_hash"`
+}
+
+type NewsFeed struct {
+	Name                string             `json:"name"`
+	Url                 string             `json:"url"`
+	Etag                pgtype.Text        `json:"etag"`
+	LastModified        pgtype.Text        `json:"last_modified"`
+	ConsecutiveFailures int32              `json:"consecutive_failures"`
+	LastStatus          pgtype.Int4        `json:"last_status"`
+	LastError           pgtype.Text        `json:"last_error"`
+	LastFetchedAt       pgtype.Timestamptz `json:"last_fetched_at"`
+	LastSuccessAt       pgtype.Timestamptz `json:"last_success_at"`
+	NextFetchAt         pgtype.Timestamptz `json:"next_fetch_at"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
 }
 
 type Permission struct {
@@ -158,14 +500,16 @@ type Permission struct {
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
-type RbacAuditLog struct {
-	ID        int32              `json:"id"`
-	TableName string             `json:"table_name"`
-	Action    string             `json:"action"`
-	OldData   []byte             `json:"old_data"`
-	NewData   []byte             `json:"new_data"`
-	ChangedBy pgtype.Int4        `json:"changed_by"`
-	ChangedAt pgtype.Timestamptz `json:"changed_at"`
+type RefreshToken struct {
+	ID         int32              `json:"id"`
+	UserID     int32              `json:"user_id"`
+	FamilyID   string             `json:"family_id"`
+	TokenHash  string             `json:"token_hash"`
+	Generation int64              `json:"generation"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	UsedAt     pgtype.Timestamptz `json:"used_at"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
 type Role struct {
@@ -184,14 +528,15 @@ type RolePermission struct {
 }
 
 type SupplyNeed struct {
-	ID             int32              `json:"id"`
-	StationID      pgtype.Int4        `json:"station_id"`
-	SupplyType     string             `json:"supply_type"`
-	QuantityNeeded pgtype.Int4        `json:"quantity_needed"`
-	Description    pgtype.Text        `json:"description"`
-	UrgencyLevel   pgtype.Text        `json:"urgency_level"`
-	CreatedAt      pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	ID              int32              `json:"id"`
+	StationID       pgtype.Int4        `json:"station_id"`
+	SupplyType      string             `json:"supply_type"`
+	QuantityNeeded  pgtype.Int4        `json:"quantity_needed"`
+	Description     pgtype.Text        `json:"description"`
+	UrgencyLevel    UrgencyLevel       `json:"urgency_level"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	LastConfirmedAt pgtype.Timestamptz `json:"last_confirmed_at"`
 }
 
 type SupplyStation struct {
@@ -206,13 +551,14 @@ type SupplyStation struct {
 }
 
 type User struct {
-	ID          int32              `json:"id"`
-	OidcSub     string             `json:"oidc_sub"`
-	Username    string             `json:"username"`
-	Email       pgtype.Text        `json:"email"`
-	IsActive    pgtype.Bool        `json:"is_active"`
-	TrustPoints pgtype.Int4        `json:"trust_points"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ID             int32              `json:"id"`
+	OidcSub        string             `json:"oidc_sub"`
+	Username       string             `json:"username"`
+	Email          pgtype.Text        `json:"email"`
+	IsActive       pgtype.Bool        `json:"is_active"`
+	TrustPoints    pgtype.Int4        `json:"trust_points"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ApprovalStatus string             `json:"approval_status"`
 }
 
 type UserRole struct {
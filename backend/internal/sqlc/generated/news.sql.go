@@ -22,6 +22,18 @@ func (q *Queries) CountNews(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countNewsByAnyTags = `-- name: CountNewsByAnyTags :one
+SELECT COUNT(*) FROM news
+WHERE relevant_to @> ANY($1::jsonb[])
+`
+
+func (q *Queries) CountNewsByAnyTags(ctx context.Context, tags [][]byte) (int64, error) {
+	row := q.db.QueryRow(ctx, countNewsByAnyTags, tags)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countNewsBySource = `-- name: CountNewsBySource :one
 SELECT COUNT(*) FROM news WHERE source = $1
 `
@@ -33,10 +45,49 @@ func (q *Queries) CountNewsBySource(ctx context.Context, source string) (int64,
 	return count, err
 }
 
+const countNewsByTags = `-- name: CountNewsByTags :one
+SELECT COUNT(*) FROM news
+WHERE relevant_to @> $1::jsonb
+  AND ($2::text IS NULL OR source = $2::text)
+`
+
+type CountNewsByTagsParams struct {
+	Tags   []byte      `json:"tags"`
+	Source pgtype.Text `json:"source"`
+}
+
+func (q *Queries) CountNewsByTags(ctx context.Context, arg CountNewsByTagsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countNewsByTags, arg.Tags, arg.Source)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchNews = `-- name: CountSearchNews :one
+SELECT COUNT(*)
+FROM news n, news_search_query($1::text) AS q(query)
+WHERE news_search_vector(n.title, n.content) @@ q.query
+  AND ($2::text IS NULL OR n.source = $2::text)
+  AND ($3::jsonb IS NULL OR n.relevant_to @> $3::jsonb)
+`
+
+type CountSearchNewsParams struct {
+	Query  string      `json:"query"`
+	Source pgtype.Text `json:"source"`
+	Tags   []byte      `json:"tags"`
+}
+
+func (q *Queries) CountSearchNews(ctx context.Context, arg CountSearchNewsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchNews, arg.Query, arg.Source, arg.Tags)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createNews = `-- name: CreateNews :one
-INSERT INTO news (source, title, content, url, published_at, relevant_to)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, source, title, content, url, published_at, fetched_at, relevant_to
+INSERT INTO news (source, title, content, url, published_at, relevant_to, content_hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash
 `
 
 type CreateNewsParams struct {
@@ -46,6 +97,7 @@ type CreateNewsParams struct {
 	Url         pgtype.Text        `json:"url"`
 	PublishedAt pgtype.Timestamptz `json:"published_at"`
 	RelevantTo  []byte             `json:"relevant_to"`
+	ContentHash pgtype.Text        `json:"content_hash"`
 }
 
 func (q *Queries) CreateNews(ctx context.Context, arg CreateNewsParams) (News, error) {
@@ -56,6 +108,7 @@ func (q *Queries) CreateNews(ctx context.Context, arg CreateNewsParams) (News, e
 		arg.Url,
 		arg.PublishedAt,
 		arg.RelevantTo,
+		arg.ContentHash,
 	)
 	var i News
 	err := row.Scan(
@@ -67,6 +120,7 @@ func (q *Queries) CreateNews(ctx context.Context, arg CreateNewsParams) (News, e
 		&i.PublishedAt,
 		&i.FetchedAt,
 		&i.RelevantTo,
+		&i.ContentHash,
 	)
 	return i, err
 }
@@ -90,7 +144,7 @@ func (q *Queries) DeleteOldNews(ctx context.Context, fetchedAt pgtype.Timestampt
 }
 
 const getLatestNewsBySource = `-- name: GetLatestNewsBySource :one
-SELECT id, source, title, content, url, published_at, fetched_at, relevant_to FROM news
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news
 WHERE source = $1
 ORDER BY published_at DESC NULLS LAST
 LIMIT 1
@@ -108,13 +162,35 @@ func (q *Queries) GetLatestNewsBySource(ctx context.Context, source string) (New
 		&i.PublishedAt,
 		&i.FetchedAt,
 		&i.RelevantTo,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const getNewsByContentHash = `-- name: GetNewsByContentHash :one
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news WHERE content_hash = $1 LIMIT 1
+`
+
+func (q *Queries) GetNewsByContentHash(ctx context.Context, contentHash pgtype.Text) (News, error) {
+	row := q.db.QueryRow(ctx, getNewsByContentHash, contentHash)
+	var i News
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Title,
+		&i.Content,
+		&i.Url,
+		&i.PublishedAt,
+		&i.FetchedAt,
+		&i.RelevantTo,
+		&i.ContentHash,
 	)
 	return i, err
 }
 
 const getNewsByID = `-- name: GetNewsByID :one
 
-SELECT id, source, title, content, url, published_at, fetched_at, relevant_to FROM news WHERE id = $1 LIMIT 1
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news WHERE id = $1 LIMIT 1
 `
 
 // internal/db/queries/news.sql
@@ -131,12 +207,78 @@ func (q *Queries) GetNewsByID(ctx context.Context, id int32) (News, error) {
 		&i.PublishedAt,
 		&i.FetchedAt,
 		&i.RelevantTo,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const getNewsByURL = `-- name: GetNewsByURL :one
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news WHERE url = $1 LIMIT 1
+`
+
+func (q *Queries) GetNewsByURL(ctx context.Context, url pgtype.Text) (News, error) {
+	row := q.db.QueryRow(ctx, getNewsByURL, url)
+	var i News
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Title,
+		&i.Content,
+		&i.Url,
+		&i.PublishedAt,
+		&i.FetchedAt,
+		&i.RelevantTo,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
+const ingestNews = `-- name: IngestNews :one
+INSERT INTO news (source, title, content, url, published_at, relevant_to, content_hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT DO NOTHING
+RETURNING id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash
+`
+
+type IngestNewsParams struct {
+	Source      string             `json:"source"`
+	Title       string             `json:"title"`
+	Content     pgtype.Text        `json:"content"`
+	Url         pgtype.Text        `json:"url"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+	RelevantTo  []byte             `json:"relevant_to"`
+	ContentHash pgtype.Text        `json:"content_hash"`
+}
+
+// Inserts a fetched feed entry with its suggested tags. Returns no rows if an item
+// with the same URL or content hash already exists.
+func (q *Queries) IngestNews(ctx context.Context, arg IngestNewsParams) (News, error) {
+	row := q.db.QueryRow(ctx, ingestNews,
+		arg.Source,
+		arg.Title,
+		arg.Content,
+		arg.Url,
+		arg.PublishedAt,
+		arg.RelevantTo,
+		arg.ContentHash,
+	)
+	var i News
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Title,
+		&i.Content,
+		&i.Url,
+		&i.PublishedAt,
+		&i.FetchedAt,
+		&i.RelevantTo,
+		&i.ContentHash,
 	)
 	return i, err
 }
 
 const listNews = `-- name: ListNews :many
-SELECT id, source, title, content, url, published_at, fetched_at, relevant_to FROM news
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news
 ORDER BY published_at DESC NULLS LAST, fetched_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -164,6 +306,52 @@ func (q *Queries) ListNews(ctx context.Context, arg ListNewsParams) ([]News, err
 			&i.PublishedAt,
 			&i.FetchedAt,
 			&i.RelevantTo,
+			&i.ContentHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsByAnyTags = `-- name: ListNewsByAnyTags :many
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news
+WHERE relevant_to @> ANY($1::jsonb[])
+ORDER BY published_at DESC NULLS LAST, fetched_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListNewsByAnyTagsParams struct {
+	Tags      [][]byte `json:"tags"`
+	RowLimit  int32    `json:"row_limit"`
+	RowOffset int32    `json:"row_offset"`
+}
+
+// Items whose relevant_to contains any of the given tag objects, newest first. Each one
+// is a containment lookup on idx_news_relevant_to, combined in a bitmap index scan.
+func (q *Queries) ListNewsByAnyTags(ctx context.Context, arg ListNewsByAnyTagsParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsByAnyTags, arg.Tags, arg.RowLimit, arg.RowOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Source,
+			&i.Title,
+			&i.Content,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FetchedAt,
+			&i.RelevantTo,
+			&i.ContentHash,
 		); err != nil {
 			return nil, err
 		}
@@ -176,7 +364,7 @@ func (q *Queries) ListNews(ctx context.Context, arg ListNewsParams) ([]News, err
 }
 
 const listNewsBySource = `-- name: ListNewsBySource :many
-SELECT id, source, title, content, url, published_at, fetched_at, relevant_to FROM news
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news
 WHERE source = $1
 ORDER BY published_at DESC NULLS LAST, fetched_at DESC
 LIMIT $2 OFFSET $3
@@ -206,6 +394,59 @@ func (q *Queries) ListNewsBySource(ctx context.Context, arg ListNewsBySourcePara
 			&i.PublishedAt,
 			&i.FetchedAt,
 			&i.RelevantTo,
+			&i.ContentHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewsByTags = `-- name: ListNewsByTags :many
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news
+WHERE relevant_to @> $1::jsonb
+  AND ($2::text IS NULL OR source = $2::text)
+ORDER BY published_at DESC NULLS LAST, fetched_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListNewsByTagsParams struct {
+	Tags      []byte      `json:"tags"`
+	Source    pgtype.Text `json:"source"`
+	RowLimit  int32       `json:"row_limit"`
+	RowOffset int32       `json:"row_offset"`
+}
+
+// Items whose relevant_to contains tags, e.g. {"districts": ["KC"]}, newest first.
+// Uses idx_news_relevant_to.
+func (q *Queries) ListNewsByTags(ctx context.Context, arg ListNewsByTagsParams) ([]News, error) {
+	rows, err := q.db.Query(ctx, listNewsByTags,
+		arg.Tags,
+		arg.Source,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []News
+	for rows.Next() {
+		var i News
+		if err := rows.Scan(
+			&i.ID,
+			&i.Source,
+			&i.Title,
+			&i.Content,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FetchedAt,
+			&i.RelevantTo,
+			&i.ContentHash,
 		); err != nil {
 			return nil, err
 		}
@@ -218,7 +459,7 @@ func (q *Queries) ListNewsBySource(ctx context.Context, arg ListNewsBySourcePara
 }
 
 const listRecentNews = `-- name: ListRecentNews :many
-SELECT id, source, title, content, url, published_at, fetched_at, relevant_to FROM news
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news
 WHERE published_at >= $1
 ORDER BY published_at DESC NULLS LAST
 LIMIT $2
@@ -247,6 +488,132 @@ func (q *Queries) ListRecentNews(ctx context.Context, arg ListRecentNewsParams)
 			&i.PublishedAt,
 			&i.FetchedAt,
 			&i.RelevantTo,
+			&i.ContentHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStationDistricts = `-- name: ListStationDistricts :many
+SELECT d.code FROM districts d
+JOIN supply_stations s ON ST_Covers(d.boundary, s.location)
+WHERE s.id = $1
+ORDER BY d.code
+`
+
+// Codes of the districts whose boundary contains the station, usually one.
+func (q *Queries) ListStationDistricts(ctx context.Context, id int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, listStationDistricts, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		items = append(items, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnknownStationIDs = `-- name: ListUnknownStationIDs :many
+SELECT t.id::int FROM unnest($1::int[]) AS t(id)
+WHERE NOT EXISTS (SELECT 1 FROM supply_stations s WHERE s.id = t.id)
+ORDER BY t.id
+`
+
+// The given station IDs that do not exist, for validating news tags.
+func (q *Queries) ListUnknownStationIDs(ctx context.Context, ids []int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listUnknownStationIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchNews = `-- name: SearchNews :many
+SELECT n.id, n.source, n.title, n.content, n.url, n.published_at, n.fetched_at, n.relevant_to, n.content_hash, ts_rank_cd(news_search_vector(n.title, n.content), q.query)::float4 AS rank
+FROM news n, news_search_query($1::text) AS q(query)
+WHERE news_search_vector(n.title, n.content) @@ q.query
+  AND ($2::text IS NULL OR n.source = $2::text)
+  AND ($3::jsonb IS NULL OR n.relevant_to @> $3::jsonb)
+ORDER BY rank DESC, n.published_at DESC NULLS LAST, n.id DESC
+LIMIT $4 OFFSET $5
+`
+
+type SearchNewsParams struct {
+	Query     string      `json:"query"`
+	Source    pgtype.Text `json:"source"`
+	Tags      []byte      `json:"tags"`
+	RowLimit  int32       `json:"row_limit"`
+	RowOffset int32       `json:"row_offset"`
+}
+
+type SearchNewsRow struct {
+	ID          int32              `json:"id"`
+	Source      string             `json:"source"`
+	Title       string             `json:"title"`
+	Content     pgtype.Text        `json:"content"`
+	Url         pgtype.Text        `json:"url"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+	FetchedAt   pgtype.Timestamptz `json:"fetched_at"`
+	RelevantTo  []byte             `json:"relevant_to"`
+	ContentHash pgtype.Text        `json:"content_hash"`
+	Rank        float32            `json:"rank"`
+}
+
+// Full-text search over title and content, best matches first. See news_search_query
+// in schema.sql for how English and Chinese queries are parsed.
+func (q *Queries) SearchNews(ctx context.Context, arg SearchNewsParams) ([]SearchNewsRow, error) {
+	rows, err := q.db.Query(ctx, searchNews,
+		arg.Query,
+		arg.Source,
+		arg.Tags,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchNewsRow
+	for rows.Next() {
+		var i SearchNewsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Source,
+			&i.Title,
+			&i.Content,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FetchedAt,
+			&i.RelevantTo,
+			&i.ContentHash,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -259,7 +626,7 @@ func (q *Queries) ListRecentNews(ctx context.Context, arg ListRecentNewsParams)
 }
 
 const searchNewsByTitle = `-- name: SearchNewsByTitle :many
-SELECT id, source, title, content, url, published_at, fetched_at, relevant_to FROM news
+SELECT id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash FROM news
 WHERE title ILIKE '%' || $1 || '%'
 ORDER BY published_at DESC NULLS LAST
 LIMIT $2 OFFSET $3
@@ -289,6 +656,7 @@ func (q *Queries) SearchNewsByTitle(ctx context.Context, arg SearchNewsByTitlePa
 			&i.PublishedAt,
 			&i.FetchedAt,
 			&i.RelevantTo,
+			&i.ContentHash,
 		); err != nil {
 			return nil, err
 		}
@@ -300,6 +668,55 @@ func (q *Queries) SearchNewsByTitle(ctx context.Context, arg SearchNewsByTitlePa
 	return items, nil
 }
 
+const updateIngestedNews = `-- name: UpdateIngestedNews :one
+UPDATE news
+SET title = $1,
+    content = $2,
+    published_at = $3,
+    content_hash = $4,
+    relevant_to = CASE WHEN relevant_to @> '{"reviewed": true}' THEN relevant_to ELSE $5 END,
+    fetched_at = CURRENT_TIMESTAMP
+WHERE id = $6
+  AND (content_hash IS DISTINCT FROM $4
+       OR published_at IS DISTINCT FROM $3)
+RETURNING id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash
+`
+
+type UpdateIngestedNewsParams struct {
+	Title       string             `json:"title"`
+	Content     pgtype.Text        `json:"content"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+	ContentHash pgtype.Text        `json:"content_hash"`
+	RelevantTo  []byte             `json:"relevant_to"`
+	ID          int32              `json:"id"`
+}
+
+// Refreshes an ingested item whose feed entry changed. Returns no rows if nothing changed.
+// Suggested tags are replaced, but tags reviewed by an editor are kept.
+func (q *Queries) UpdateIngestedNews(ctx context.Context, arg UpdateIngestedNewsParams) (News, error) {
+	row := q.db.QueryRow(ctx, updateIngestedNews,
+		arg.Title,
+		arg.Content,
+		arg.PublishedAt,
+		arg.ContentHash,
+		arg.RelevantTo,
+		arg.ID,
+	)
+	var i News
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Title,
+		&i.Content,
+		&i.Url,
+		&i.PublishedAt,
+		&i.FetchedAt,
+		&i.RelevantTo,
+		&i.ContentHash,
+	)
+	return i, err
+}
+
 const updateNews = `-- name: UpdateNews :one
 UPDATE news
 SET source = $2,
@@ -307,9 +724,10 @@ SET source = $2,
     content = $4,
     url = $5,
     published_at = $6,
-    relevant_to = $7
+    relevant_to = $7,
+    content_hash = $8
 WHERE id = $1
-RETURNING id, source, title, content, url, published_at, fetched_at, relevant_to
+RETURNING id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash
 `
 
 type UpdateNewsParams struct {
@@ -320,6 +738,7 @@ type UpdateNewsParams struct {
 	Url         pgtype.Text        `json:"url"`
 	PublishedAt pgtype.Timestamptz `json:"published_at"`
 	RelevantTo  []byte             `json:"relevant_to"`
+	ContentHash pgtype.Text        `json:"content_hash"`
 }
 
 func (q *Queries) UpdateNews(ctx context.Context, arg UpdateNewsParams) (News, error) {
@@ -331,7 +750,35 @@ func (q *Queries) UpdateNews(ctx context.Context, arg UpdateNewsParams) (News, e
 		arg.Url,
 		arg.PublishedAt,
 		arg.RelevantTo,
+		arg.ContentHash,
+	)
+	var i News
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Title,
+		&i.Content,
+		&i.Url,
+		&i.PublishedAt,
+		&i.FetchedAt,
+		&i.RelevantTo,
+		&i.ContentHash,
 	)
+	return i, err
+}
+
+const updateNewsTags = `-- name: UpdateNewsTags :one
+UPDATE news SET relevant_to = $2 WHERE id = $1
+RETURNING id, source, title, content, url, published_at, fetched_at, relevant_to, content_hash
+`
+
+type UpdateNewsTagsParams struct {
+	ID         int32  `json:"id"`
+	RelevantTo []byte `json:"relevant_to"`
+}
+
+func (q *Queries) UpdateNewsTags(ctx context.Context, arg UpdateNewsTagsParams) (News, error) {
+	row := q.db.QueryRow(ctx, updateNewsTags, arg.ID, arg.RelevantTo)
 	var i News
 	err := row.Scan(
 		&i.ID,
@@ -342,6 +789,7 @@ func (q *Queries) UpdateNews(ctx context.Context, arg UpdateNewsParams) (News, e
 		&i.PublishedAt,
 		&i.FetchedAt,
 		&i.RelevantTo,
+		&i.ContentHash,
 	)
 	return i, err
 }
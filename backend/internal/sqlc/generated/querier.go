@@ -13,24 +13,55 @@ import (
 type Querier interface {
 	// Activate a user (admin only)
 	ActivateUser(ctx context.Context, id int32) (User, error)
+	AddAPIKeyPermission(ctx context.Context, arg AddAPIKeyPermissionParams) error
+	AlertSourceExists(ctx context.Context, sourceUrl pgtype.Text) (bool, error)
+	// Whether a linked CAP document failed for good while its index entry was unchanged.
+	AlertSourceFailed(ctx context.Context, arg AlertSourceFailedParams) (bool, error)
+	// Approve a pending or previously rejected user and activate their account
+	ApproveUser(ctx context.Context, id int32) (User, error)
 	AssignPermissionToRole(ctx context.Context, arg AssignPermissionToRoleParams) (RolePermission, error)
 	AssignRoleToUser(ctx context.Context, arg AssignRoleToUserParams) (UserRole, error)
 	CheckUserPermission(ctx context.Context, arg CheckUserPermissionParams) (bool, error)
+	// Claims a feed that is due by pushing next_fetch_at out by the lease. Returns
+	// no rows if the feed is not due, e.g. because another instance claimed it.
+	ClaimNewsFeed(ctx context.Context, arg ClaimNewsFeedParams) (NewsFeed, error)
+	// Record that a need is still current without changing it
+	ConfirmSupplyNeed(ctx context.Context, id int32) (SupplyNeed, error)
+	CountActiveUsersWithRole(ctx context.Context, roleID int32) (int64, error)
 	CountAuditLogs(ctx context.Context) (int64, error)
+	CountAuditLogsFiltered(ctx context.Context, arg CountAuditLogsFilteredParams) (int64, error)
 	CountCheckinsByStation(ctx context.Context, stationID pgtype.Int4) (int64, error)
+	CountDistricts(ctx context.Context) (int64, error)
 	CountDonations(ctx context.Context) (int64, error)
-	CountDonationsByStatus(ctx context.Context, status pgtype.Text) (int64, error)
+	CountDonationsByStatus(ctx context.Context, status DonationStatus) (int64, error)
 	CountNews(ctx context.Context) (int64, error)
+	CountNewsByAnyTags(ctx context.Context, tags [][]byte) (int64, error)
 	CountNewsBySource(ctx context.Context, source string) (int64, error)
+	CountNewsByTags(ctx context.Context, arg CountNewsByTagsParams) (int64, error)
+	CountPendingUsers(ctx context.Context) (int64, error)
+	CountSearchNews(ctx context.Context, arg CountSearchNewsParams) (int64, error)
 	CountStations(ctx context.Context) (int64, error)
 	CountUsers(ctx context.Context) (int64, error)
 	CountVerifiedStations(ctx context.Context) (int64, error)
-	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (RbacAuditLog, error)
+	// internal/db/queries/apikey.sql
+	// SQL queries for service account API keys (used by sqlc)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	// Stores a CAP alert. The area is given as WKT polygons, which are merged into one
+	// valid multipolygon. territory_wide is set only for alerts without any <area>.
+	// Returns no rows if the sender already published this identifier.
+	CreateAlert(ctx context.Context, arg CreateAlertParams) (int32, error)
+	// Archives the entries first_id..last_id into a checkpoint.
+	CreateAuditCheckpoint(ctx context.Context, arg CreateAuditCheckpointParams) (CreateAuditCheckpointRow, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateCheckin(ctx context.Context, arg CreateCheckinParams) (Checkin, error)
 	CreateCheckinWithoutLocation(ctx context.Context, arg CreateCheckinWithoutLocationParams) (Checkin, error)
 	CreateDonation(ctx context.Context, arg CreateDonationParams) (Donation, error)
+	CreateDonationStatusHistory(ctx context.Context, arg CreateDonationStatusHistoryParams) (DonationStatusHistory, error)
 	CreateNews(ctx context.Context, arg CreateNewsParams) (News, error)
 	CreatePermission(ctx context.Context, arg CreatePermissionParams) (Permission, error)
+	// internal/db/queries/refresh_token.sql
+	// SQL queries for refresh token rotation (used by sqlc)
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
 	CreateRole(ctx context.Context, arg CreateRoleParams) (Role, error)
 	CreateStation(ctx context.Context, arg CreateStationParams) (SupplyStation, error)
 	CreateSupplyNeed(ctx context.Context, arg CreateSupplyNeedParams) (SupplyNeed, error)
@@ -39,10 +70,11 @@ type Querier interface {
 	CreateUserFromOIDC(ctx context.Context, arg CreateUserFromOIDCParams) (User, error)
 	// Deactivate a user (admin only) - blocks login without deleting data
 	DeactivateUser(ctx context.Context, id int32) (User, error)
+	DeleteAlertSourceFailure(ctx context.Context, sourceUrl string) error
+	DeleteAuditLogsThrough(ctx context.Context, lastID int32) (int64, error)
 	DeleteCheckin(ctx context.Context, id int32) error
 	DeleteDonation(ctx context.Context, id int32) error
 	DeleteNews(ctx context.Context, id int32) error
-	DeleteOldAuditLogs(ctx context.Context, changedAt pgtype.Timestamptz) error
 	DeleteOldNews(ctx context.Context, fetchedAt pgtype.Timestamptz) error
 	DeletePermission(ctx context.Context, id int32) error
 	DeleteRole(ctx context.Context, id int32) error
@@ -51,29 +83,44 @@ type Querier interface {
 	DeleteSupplyNeedsByStation(ctx context.Context, stationID pgtype.Int4) error
 	DeleteUser(ctx context.Context, id int32) error
 	FindNearbyStations(ctx context.Context, arg FindNearbyStationsParams) ([]FindNearbyStationsRow, error)
+	// Nearby stations that have at least one need matching the optional supply type and urgency filters
+	FindNearbyStationsWithNeed(ctx context.Context, arg FindNearbyStationsWithNeedParams) ([]FindNearbyStationsWithNeedRow, error)
 	FindNearbyVerifiedStations(ctx context.Context, arg FindNearbyVerifiedStationsParams) ([]FindNearbyVerifiedStationsRow, error)
+	GetAPIKeyByID(ctx context.Context, id int32) (ApiKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetAPIKeyPermissions(ctx context.Context, apiKeyID int32) ([]AppPermission, error)
 	// Find an active user by their OIDC subject identifier (for login validation)
 	GetActiveUserByOIDCSub(ctx context.Context, oidcSub string) (User, error)
+	GetAlertByID(ctx context.Context, id int32) (GetAlertByIDRow, error)
 	// internal/db/queries/audit.sql
-	// SQL queries for RBAC audit log operations (used by sqlc)
-	GetAuditLogByID(ctx context.Context, id int32) (RbacAuditLog, error)
+	// SQL queries for audit log operations (used by sqlc)
+	GetAuditLogByID(ctx context.Context, id int32) (AuditLog, error)
+	// Last ID of the oldest entries changed before the cutoff. Pruning removes the start of
+	// the chain, so it stops at the first entry changed at or after the cutoff.
+	GetAuditLogPruneBoundary(ctx context.Context, cutoff pgtype.Timestamptz) (int32, error)
 	// internal/db/queries/checkin.sql
 	// SQL queries for check-in operations (used by sqlc)
 	GetCheckinByID(ctx context.Context, id int32) (Checkin, error)
 	GetCheckinByUserAndStation(ctx context.Context, arg GetCheckinByUserAndStationParams) (Checkin, error)
 	GetCheckinWithDetails(ctx context.Context, id int32) (GetCheckinWithDetailsRow, error)
+	// Distance in meters between a station and the given point, used to verify on-scene check-ins
+	GetDistanceToStation(ctx context.Context, arg GetDistanceToStationParams) (float64, error)
 	GetDonationByDeliveryCode(ctx context.Context, deliveryCode string) (Donation, error)
 	// internal/db/queries/donation.sql
 	// SQL queries for donation operations (used by sqlc)
 	GetDonationByID(ctx context.Context, id int32) (Donation, error)
 	GetDonationWithDetails(ctx context.Context, id int32) (GetDonationWithDetailsRow, error)
 	GetLatestNewsBySource(ctx context.Context, source string) (News, error)
+	GetNewsByContentHash(ctx context.Context, contentHash pgtype.Text) (News, error)
 	// internal/db/queries/news.sql
 	// SQL queries for news operations (used by sqlc)
 	GetNewsByID(ctx context.Context, id int32) (News, error)
+	GetNewsByURL(ctx context.Context, url pgtype.Text) (News, error)
+	GetNewsFeed(ctx context.Context, name string) (NewsFeed, error)
 	// ==================== Permissions ====================
 	GetPermissionByID(ctx context.Context, id int32) (Permission, error)
 	GetPermissionByName(ctx context.Context, name AppPermission) (Permission, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
 	// internal/db/queries/role.sql
 	// SQL queries for role and permission operations (used by sqlc)
 	// ==================== Roles ====================
@@ -85,6 +132,9 @@ type Querier interface {
 	// SQL queries for supply station operations (used by sqlc)
 	// ==================== Supply Stations ====================
 	GetStationByID(ctx context.Context, id int32) (SupplyStation, error)
+	// Render the stations inside web-mercator tile z/x/y as a Mapbox Vector Tile
+	// with verification and highest-urgency attributes
+	GetStationTile(ctx context.Context, arg GetStationTileParams) ([]byte, error)
 	// ==================== Supply Needs ====================
 	GetSupplyNeedByID(ctx context.Context, id int32) (SupplyNeed, error)
 	GetUserByEmail(ctx context.Context, email pgtype.Text) (User, error)
@@ -101,48 +151,122 @@ type Querier interface {
 	GetUsersWithRole(ctx context.Context, roleID int32) ([]User, error)
 	HasUserCheckedInAtStation(ctx context.Context, arg HasUserCheckedInAtStationParams) (bool, error)
 	IncrementVerificationCount(ctx context.Context, id int32) (SupplyStation, error)
-	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]RbacAuditLog, error)
-	ListAuditLogsByAction(ctx context.Context, arg ListAuditLogsByActionParams) ([]RbacAuditLog, error)
-	ListAuditLogsByTable(ctx context.Context, arg ListAuditLogsByTableParams) ([]RbacAuditLog, error)
-	ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUserParams) ([]RbacAuditLog, error)
-	ListAuditLogsInDateRange(ctx context.Context, arg ListAuditLogsInDateRangeParams) ([]RbacAuditLog, error)
+	// Inserts a fetched feed entry with its suggested tags. Returns no rows if an item
+	// with the same URL or content hash already exists.
+	IngestNews(ctx context.Context, arg IngestNewsParams) (News, error)
+	ListAPIKeys(ctx context.Context) ([]ApiKey, error)
+	// Active alerts, worst first, optionally only those covering a point.
+	ListActiveAlerts(ctx context.Context, arg ListActiveAlertsParams) ([]ListActiveAlertsRow, error)
+	// The active alerts covering each of the given stations, worst first.
+	ListActiveAlertsForStations(ctx context.Context, stationIds []int32) ([]ListActiveAlertsForStationsRow, error)
+	// Chain links in ID order, each with the hash recomputed from the entry's contents.
+	ListAuditChainLinks(ctx context.Context, arg ListAuditChainLinksParams) ([]ListAuditChainLinksRow, error)
+	// Archived links of a checkpoint in ID order, each with the hash recomputed from the
+	// archived contents, so that edits to audit_checkpoints.entries show up as a break.
+	ListAuditCheckpointLinks(ctx context.Context, id int32) ([]ListAuditCheckpointLinksRow, error)
+	// Checkpoints oldest first, without their archived entries.
+	ListAuditCheckpoints(ctx context.Context) ([]ListAuditCheckpointsRow, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListAuditLogsByAction(ctx context.Context, arg ListAuditLogsByActionParams) ([]AuditLog, error)
+	ListAuditLogsByTable(ctx context.Context, arg ListAuditLogsByTableParams) ([]AuditLog, error)
+	ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUserParams) ([]AuditLog, error)
+	// Combines the ListAuditLogsBy* filters; a NULL filter matches every row.
+	ListAuditLogsFiltered(ctx context.Context, arg ListAuditLogsFilteredParams) ([]AuditLog, error)
+	ListAuditLogsInDateRange(ctx context.Context, arg ListAuditLogsInDateRangeParams) ([]AuditLog, error)
 	ListCheckins(ctx context.Context, arg ListCheckinsParams) ([]Checkin, error)
 	ListCheckinsByStation(ctx context.Context, stationID pgtype.Int4) ([]Checkin, error)
 	ListCheckinsByUser(ctx context.Context, userID pgtype.Int4) ([]Checkin, error)
 	ListCheckinsWithUserDetails(ctx context.Context, stationID pgtype.Int4) ([]ListCheckinsWithUserDetailsRow, error)
+	ListDonationStatusHistory(ctx context.Context, donationID int32) ([]DonationStatusHistory, error)
 	ListDonations(ctx context.Context, arg ListDonationsParams) ([]Donation, error)
 	ListDonationsByDonor(ctx context.Context, donorID pgtype.Int4) ([]Donation, error)
 	ListDonationsByStation(ctx context.Context, stationID pgtype.Int4) ([]Donation, error)
 	ListDonationsByStatus(ctx context.Context, arg ListDonationsByStatusParams) ([]Donation, error)
 	ListNews(ctx context.Context, arg ListNewsParams) ([]News, error)
+	// Items whose relevant_to contains any of the given tag objects, newest first. Each one
+	// is a containment lookup on idx_news_relevant_to, combined in a bitmap index scan.
+	ListNewsByAnyTags(ctx context.Context, arg ListNewsByAnyTagsParams) ([]News, error)
 	ListNewsBySource(ctx context.Context, arg ListNewsBySourceParams) ([]News, error)
+	// Items whose relevant_to contains tags, e.g. {"districts": ["KC"]}, newest first.
+	// Uses idx_news_relevant_to.
+	ListNewsByTags(ctx context.Context, arg ListNewsByTagsParams) ([]News, error)
+	ListNewsFeeds(ctx context.Context) ([]NewsFeed, error)
+	// List users awaiting admin approval, oldest first. Active users are never pending,
+	// even if they predate approvals and kept the column default.
+	ListPendingUsers(ctx context.Context, arg ListPendingUsersParams) ([]User, error)
 	ListPermissions(ctx context.Context) ([]Permission, error)
 	ListRecentNews(ctx context.Context, arg ListRecentNewsParams) ([]News, error)
 	ListRoles(ctx context.Context) ([]Role, error)
+	// Codes of the districts whose boundary contains the station, usually one.
+	ListStationDistricts(ctx context.Context, id int32) ([]string, error)
+	// Keyset-paginated station export with decoded coordinates and aggregated needs,
+	// optionally restricted to a bounding box (used for GeoJSON streaming)
+	ListStationFeatures(ctx context.Context, arg ListStationFeaturesParams) ([]ListStationFeaturesRow, error)
 	ListStations(ctx context.Context, arg ListStationsParams) ([]SupplyStation, error)
 	ListStationsByUser(ctx context.Context, registeredBy pgtype.Int4) ([]SupplyStation, error)
 	ListSupplyNeedsByStation(ctx context.Context, stationID pgtype.Int4) ([]SupplyNeed, error)
+	// Needs for a batch of stations (avoids one query per station when embedding needs)
+	ListSupplyNeedsByStations(ctx context.Context, stationIds []int32) ([]SupplyNeed, error)
+	// The given station IDs that do not exist, for validating news tags.
+	ListUnknownStationIDs(ctx context.Context, ids []int32) ([]int32, error)
 	ListUnverifiedStations(ctx context.Context, arg ListUnverifiedStationsParams) ([]SupplyStation, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
 	ListVerifiedStations(ctx context.Context, arg ListVerifiedStationsParams) ([]SupplyStation, error)
+	// Holds off appends to the chain (see chain_audit_log) until the transaction ends.
+	LockAuditChain(ctx context.Context) error
+	LockRole(ctx context.Context, id int32) (Role, error)
+	// Compare-and-set: only an unused, unrevoked token can be rotated,
+	// so of two concurrent refreshes with the same token exactly one wins.
+	MarkRefreshTokenUsed(ctx context.Context, id int32) (int64, error)
+	RecordAlertSourceFailure(ctx context.Context, arg RecordAlertSourceFailureParams) error
+	RecordNewsFeedFailure(ctx context.Context, arg RecordNewsFeedFailureParams) error
+	RecordNewsFeedSuccess(ctx context.Context, arg RecordNewsFeedSuccessParams) error
+	// Adds a configured feed, or updates its URL. A new URL drops the cache
+	// validators and failure count of the old one and is fetched right away.
+	RegisterNewsFeed(ctx context.Context, arg RegisterNewsFeedParams) error
+	// Reject a pending user; the account stays inactive
+	RejectUser(ctx context.Context, id int32) (User, error)
 	RemoveAllPermissionsFromRole(ctx context.Context, roleID int32) error
 	RemoveAllRolesFromUser(ctx context.Context, userID int32) error
 	RemovePermissionFromRole(ctx context.Context, arg RemovePermissionFromRoleParams) error
 	RemoveRoleFromUser(ctx context.Context, arg RemoveRoleFromUserParams) error
+	RevokeAPIKey(ctx context.Context, id int32) (ApiKey, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	// Full-text search over title and content, best matches first. See news_search_query
+	// in schema.sql for how English and Chinese queries are parsed.
+	SearchNews(ctx context.Context, arg SearchNewsParams) ([]SearchNewsRow, error)
 	SearchNewsByTitle(ctx context.Context, arg SearchNewsByTitleParams) ([]News, error)
+	// Attribute the current transaction's changes for audit triggers (SET LOCAL semantics).
+	// Empty values are recorded as NULL.
+	SetAuditContext(ctx context.Context, arg SetAuditContextParams) error
 	SetStationVerified(ctx context.Context, arg SetStationVerifiedParams) (SupplyStation, error)
+	// Ends the alerts referenced by a later Update or Cancel. senders[i] and
+	// identifiers[i] identify the i-th referenced alert.
+	SupersedeAlerts(ctx context.Context, arg SupersedeAlertsParams) (int64, error)
+	// Record key usage, writing at most once a minute per key
+	TouchAPIKeyLastUsed(ctx context.Context, id int32) error
+	// Compare-and-set status change; returns no rows if the status changed concurrently
+	TransitionDonationStatus(ctx context.Context, arg TransitionDonationStatusParams) (Donation, error)
 	UpdateCheckinNotes(ctx context.Context, arg UpdateCheckinNotesParams) (Checkin, error)
 	UpdateDonation(ctx context.Context, arg UpdateDonationParams) (Donation, error)
 	UpdateDonationStatus(ctx context.Context, arg UpdateDonationStatusParams) (Donation, error)
+	// Refreshes an ingested item whose feed entry changed. Returns no rows if nothing changed.
+	// Suggested tags are replaced, but tags reviewed by an editor are kept.
+	UpdateIngestedNews(ctx context.Context, arg UpdateIngestedNewsParams) (News, error)
 	UpdateNews(ctx context.Context, arg UpdateNewsParams) (News, error)
+	UpdateNewsTags(ctx context.Context, arg UpdateNewsTagsParams) (News, error)
 	UpdatePermission(ctx context.Context, arg UpdatePermissionParams) (Permission, error)
 	UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, error)
 	UpdateStation(ctx context.Context, arg UpdateStationParams) (SupplyStation, error)
+	// Updating a need also counts as confirming that it is still current
 	UpdateSupplyNeed(ctx context.Context, arg UpdateSupplyNeedParams) (SupplyNeed, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	// Link an existing user to their OIDC account
 	UpdateUserOIDCSub(ctx context.Context, arg UpdateUserOIDCSubParams) (User, error)
 	UpdateUserTrustPoints(ctx context.Context, arg UpdateUserTrustPointsParams) (User, error)
+	// Stores a district boundary given as GeoJSON in the given SRID, e.g. 2326 for the
+	// Hong Kong 1980 Grid. Pieces are merged and invalid rings repaired.
+	UpsertDistrict(ctx context.Context, arg UpsertDistrictParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -59,6 +59,20 @@ func (q *Queries) AssignRoleToUser(ctx context.Context, arg AssignRoleToUserPara
 	return i, err
 }
 
+const countActiveUsersWithRole = `-- name: CountActiveUsersWithRole :one
+SELECT COUNT(*)
+FROM users u
+JOIN user_roles ur ON u.id = ur.user_id
+WHERE ur.role_id = $1 AND u.is_active = TRUE
+`
+
+func (q *Queries) CountActiveUsersWithRole(ctx context.Context, roleID int32) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveUsersWithRole, roleID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPermission = `-- name: CreatePermission :one
 INSERT INTO permissions (name, description)
 VALUES ($1, $2)
@@ -273,7 +287,7 @@ func (q *Queries) GetUserRoles(ctx context.Context, userID int32) ([]Role, error
 }
 
 const getUsersWithRole = `-- name: GetUsersWithRole :many
-SELECT u.id, u.oidc_sub, u.username, u.email, u.is_active, u.trust_points, u.created_at
+SELECT u.id, u.oidc_sub, u.username, u.email, u.is_active, u.trust_points, u.created_at, u.approval_status
 FROM users u
 JOIN user_roles ur ON u.id = ur.user_id
 WHERE ur.role_id = $1
@@ -297,6 +311,7 @@ func (q *Queries) GetUsersWithRole(ctx context.Context, roleID int32) ([]User, e
 			&i.IsActive,
 			&i.TrustPoints,
 			&i.CreatedAt,
+			&i.ApprovalStatus,
 		); err != nil {
 			return nil, err
 		}
@@ -368,6 +383,23 @@ func (q *Queries) ListRoles(ctx context.Context) ([]Role, error) {
 	return items, nil
 }
 
+const lockRole = `-- name: LockRole :one
+SELECT id, name, description, created_at, updated_at FROM roles WHERE id = $1 LIMIT 1 FOR UPDATE
+`
+
+func (q *Queries) LockRole(ctx context.Context, id int32) (Role, error) {
+	row := q.db.QueryRow(ctx, lockRole, id)
+	var i Role
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const removeAllPermissionsFromRole = `-- name: RemoveAllPermissionsFromRole :exec
 DELETE FROM role_permissions WHERE role_id = $1
 `
@@ -11,6 +11,31 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const confirmSupplyNeed = `-- name: ConfirmSupplyNeed :one
+UPDATE supply_needs
+SET last_confirmed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at, last_confirmed_at
+`
+
+// Record that a need is still current without changing it
+func (q *Queries) ConfirmSupplyNeed(ctx context.Context, id int32) (SupplyNeed, error) {
+	row := q.db.QueryRow(ctx, confirmSupplyNeed, id)
+	var i SupplyNeed
+	err := row.Scan(
+		&i.ID,
+		&i.StationID,
+		&i.SupplyType,
+		&i.QuantityNeeded,
+		&i.Description,
+		&i.UrgencyLevel,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastConfirmedAt,
+	)
+	return i, err
+}
+
 const countStations = `-- name: CountStations :one
 SELECT COUNT(*) FROM supply_stations
 `
@@ -70,15 +95,15 @@ func (q *Queries) CreateStation(ctx context.Context, arg CreateStationParams) (S
 const createSupplyNeed = `-- name: CreateSupplyNeed :one
 INSERT INTO supply_needs (station_id, supply_type, quantity_needed, description, urgency_level)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at
+RETURNING id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at, last_confirmed_at
 `
 
 type CreateSupplyNeedParams struct {
-	StationID      pgtype.Int4 `json:"station_id"`
-	SupplyType     string      `json:"supply_type"`
-	QuantityNeeded pgtype.Int4 `json:"quantity_needed"`
-	Description    pgtype.Text `json:"description"`
-	UrgencyLevel   pgtype.Text `json:"urgency_level"`
+	StationID      pgtype.Int4  `json:"station_id"`
+	SupplyType     string       `json:"supply_type"`
+	QuantityNeeded pgtype.Int4  `json:"quantity_needed"`
+	Description    pgtype.Text  `json:"description"`
+	UrgencyLevel   UrgencyLevel `json:"urgency_level"`
 }
 
 func (q *Queries) CreateSupplyNeed(ctx context.Context, arg CreateSupplyNeedParams) (SupplyNeed, error) {
@@ -99,6 +124,7 @@ func (q *Queries) CreateSupplyNeed(ctx context.Context, arg CreateSupplyNeedPara
 		&i.UrgencyLevel,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LastConfirmedAt,
 	)
 	return i, err
 }
@@ -193,6 +219,83 @@ func (q *Queries) FindNearbyStations(ctx context.Context, arg FindNearbyStations
 	return items, nil
 }
 
+const findNearbyStationsWithNeed = `-- name: FindNearbyStationsWithNeed :many
+SELECT s.id, s.registered_by, s.location, s.verification_count, s.verification_threshold, s.is_verified, s.created_at, s.updated_at,
+    ST_Distance(s.location, ST_SetSRID(ST_MakePoint($1::float8, $2::float8), 4326)::geography) as distance_meters
+FROM supply_stations s
+WHERE ST_DWithin(s.location, ST_SetSRID(ST_MakePoint($1::float8, $2::float8), 4326)::geography, $3::float8)
+  AND (NOT $4::boolean OR s.is_verified = TRUE)
+  AND EXISTS (
+      SELECT 1 FROM supply_needs n
+      WHERE n.station_id = s.id
+        AND ($5::text IS NULL OR n.supply_type = $5::text)
+        AND ($6::urgency_level IS NULL OR n.urgency_level = $6::urgency_level)
+  )
+ORDER BY distance_meters
+LIMIT $7
+`
+
+type FindNearbyStationsWithNeedParams struct {
+	Lng          float64          `json:"lng"`
+	Lat          float64          `json:"lat"`
+	RadiusMeters float64          `json:"radius_meters"`
+	VerifiedOnly bool             `json:"verified_only"`
+	SupplyType   pgtype.Text      `json:"supply_type"`
+	UrgencyLevel NullUrgencyLevel `json:"urgency_level"`
+	MaxResults   int32            `json:"max_results"`
+}
+
+type FindNearbyStationsWithNeedRow struct {
+	ID                    int32              `json:"id"`
+	RegisteredBy          pgtype.Int4        `json:"registered_by"`
+	Location              interface{}        `json:"location"`
+	VerificationCount     pgtype.Int4        `json:"verification_count"`
+	VerificationThreshold int32              `json:"verification_threshold"`
+	IsVerified            pgtype.Bool        `json:"is_verified"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `json:"updated_at"`
+	DistanceMeters        interface{}        `json:"distance_meters"`
+}
+
+// Nearby stations that have at least one need matching the optional supply type and urgency filters
+func (q *Queries) FindNearbyStationsWithNeed(ctx context.Context, arg FindNearbyStationsWithNeedParams) ([]FindNearbyStationsWithNeedRow, error) {
+	rows, err := q.db.Query(ctx, findNearbyStationsWithNeed,
+		arg.Lng,
+		arg.Lat,
+		arg.RadiusMeters,
+		arg.VerifiedOnly,
+		arg.SupplyType,
+		arg.UrgencyLevel,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindNearbyStationsWithNeedRow
+	for rows.Next() {
+		var i FindNearbyStationsWithNeedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RegisteredBy,
+			&i.Location,
+			&i.VerificationCount,
+			&i.VerificationThreshold,
+			&i.IsVerified,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DistanceMeters,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findNearbyVerifiedStations = `-- name: FindNearbyVerifiedStations :many
 SELECT id, registered_by, location, verification_count, verification_threshold, is_verified, created_at, updated_at,
     ST_Distance(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) as distance_meters
@@ -282,9 +385,51 @@ func (q *Queries) GetStationByID(ctx context.Context, id int32) (SupplyStation,
 	return i, err
 }
 
-const getSupplyNeedByID = `-- name: GetSupplyNeedByID :one
+const getStationTile = `-- name: GetStationTile :one
+WITH bounds AS (
+    SELECT ST_TileEnvelope($1::int, $2::int, $3::int) AS geom
+),
+tile_points AS (
+    SELECT
+        s.id,
+        COALESCE(s.is_verified, false) AS is_verified,
+        COALESCE(s.verification_count, 0) AS verification_count,
+        s.verification_threshold,
+        COALESCE(needs.need_count, 0) AS need_count,
+        COALESCE(needs.max_urgency::text, 'none') AS max_urgency,
+        ST_AsMVTGeom(ST_Transform(s.location::geometry, 3857), bounds.geom, 4096, 64, true) AS geom
+    FROM supply_stations s
+    CROSS JOIN bounds
+    LEFT JOIN LATERAL (
+        SELECT
+            COUNT(*) AS need_count,
+            MAX(n.urgency_level) AS max_urgency
+        FROM supply_needs n
+        WHERE n.station_id = s.id
+    ) needs ON true
+    WHERE s.location::geometry && ST_Transform(bounds.geom, 4326)
+)
+SELECT COALESCE(ST_AsMVT(tile_points, 'stations', 4096, 'geom'), ''::bytea)::bytea AS tile
+FROM tile_points
+`
+
+type GetStationTileParams struct {
+	Z int32 `json:"z"`
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+}
 
-SELECT id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at FROM supply_needs WHERE id = $1 LIMIT 1
+// Render the stations inside web-mercator tile z/x/y as a Mapbox Vector Tile
+// with verification and highest-urgency attributes
+func (q *Queries) GetStationTile(ctx context.Context, arg GetStationTileParams) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getStationTile, arg.Z, arg.X, arg.Y)
+	var tile []byte
+	err := row.Scan(&tile)
+	return tile, err
+}
+
+const getSupplyNeedByID = `-- name: GetSupplyNeedByID :one
+SELECT id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at, last_confirmed_at FROM supply_needs WHERE id = $1 LIMIT 1
 `
 
 // ==================== Supply Needs ====================
@@ -300,6 +445,7 @@ func (q *Queries) GetSupplyNeedByID(ctx context.Context, id int32) (SupplyNeed,
 		&i.UrgencyLevel,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LastConfirmedAt,
 	)
 	return i, err
 }
@@ -328,6 +474,104 @@ func (q *Queries) IncrementVerificationCount(ctx context.Context, id int32) (Sup
 	return i, err
 }
 
+const listStationFeatures = `-- name: ListStationFeatures :many
+SELECT
+    s.id,
+    ST_X(s.location::geometry)::float8 AS longitude,
+    ST_Y(s.location::geometry)::float8 AS latitude,
+    s.registered_by,
+    s.verification_count,
+    s.verification_threshold,
+    s.is_verified,
+    s.created_at,
+    s.updated_at,
+    COALESCE(
+        (SELECT jsonb_agg(jsonb_build_object(
+                    'supply_type', n.supply_type,
+                    'quantity_needed', n.quantity_needed,
+                    'urgency_level', n.urgency_level,
+                    'description', n.description,
+                    'updated_at', n.updated_at,
+                    'last_confirmed_at', n.last_confirmed_at
+                ) ORDER BY n.urgency_level DESC, n.supply_type)
+         FROM supply_needs n
+         WHERE n.station_id = s.id),
+        '[]'::jsonb
+    )::jsonb AS needs
+FROM supply_stations s
+WHERE s.id > $1
+  AND (
+      NOT $2::boolean
+      OR s.location && ST_MakeEnvelope($3::float8, $4::float8, $5::float8, $6::float8, 4326)::geography
+  )
+ORDER BY s.id
+LIMIT $7
+`
+
+type ListStationFeaturesParams struct {
+	AfterID   int32   `json:"after_id"`
+	UseBbox   bool    `json:"use_bbox"`
+	MinLng    float64 `json:"min_lng"`
+	MinLat    float64 `json:"min_lat"`
+	MaxLng    float64 `json:"max_lng"`
+	MaxLat    float64 `json:"max_lat"`
+	BatchSize int32   `json:"batch_size"`
+}
+
+type ListStationFeaturesRow struct {
+	ID                    int32              `json:"id"`
+	Longitude             float64            `json:"longitude"`
+	Latitude              float64            `json:"latitude"`
+	RegisteredBy          pgtype.Int4        `json:"registered_by"`
+	VerificationCount     pgtype.Int4        `json:"verification_count"`
+	VerificationThreshold int32              `json:"verification_threshold"`
+	IsVerified            pgtype.Bool        `json:"is_verified"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `json:"updated_at"`
+	Needs                 []byte             `json:"needs"`
+}
+
+// Keyset-paginated station export with decoded coordinates and aggregated needs,
+// optionally restricted to a bounding box (used for GeoJSON streaming)
+func (q *Queries) ListStationFeatures(ctx context.Context, arg ListStationFeaturesParams) ([]ListStationFeaturesRow, error) {
+	rows, err := q.db.Query(ctx, listStationFeatures,
+		arg.AfterID,
+		arg.UseBbox,
+		arg.MinLng,
+		arg.MinLat,
+		arg.MaxLng,
+		arg.MaxLat,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStationFeaturesRow
+	for rows.Next() {
+		var i ListStationFeaturesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Longitude,
+			&i.Latitude,
+			&i.RegisteredBy,
+			&i.VerificationCount,
+			&i.VerificationThreshold,
+			&i.IsVerified,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Needs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listStations = `-- name: ListStations :many
 SELECT id, registered_by, location, verification_count, verification_threshold, is_verified, created_at, updated_at FROM supply_stations
 ORDER BY created_at DESC
@@ -404,7 +648,7 @@ func (q *Queries) ListStationsByUser(ctx context.Context, registeredBy pgtype.In
 }
 
 const listSupplyNeedsByStation = `-- name: ListSupplyNeedsByStation :many
-SELECT id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at FROM supply_needs
+SELECT id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at, last_confirmed_at FROM supply_needs
 WHERE station_id = $1
 ORDER BY urgency_level DESC, created_at DESC
 `
@@ -427,6 +671,44 @@ func (q *Queries) ListSupplyNeedsByStation(ctx context.Context, stationID pgtype
 			&i.UrgencyLevel,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.LastConfirmedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSupplyNeedsByStations = `-- name: ListSupplyNeedsByStations :many
+SELECT id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at, last_confirmed_at FROM supply_needs
+WHERE station_id = ANY($1::int[])
+ORDER BY station_id, urgency_level DESC, created_at DESC
+`
+
+// Needs for a batch of stations (avoids one query per station when embedding needs)
+func (q *Queries) ListSupplyNeedsByStations(ctx context.Context, stationIds []int32) ([]SupplyNeed, error) {
+	rows, err := q.db.Query(ctx, listSupplyNeedsByStations, stationIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SupplyNeed
+	for rows.Next() {
+		var i SupplyNeed
+		if err := rows.Scan(
+			&i.ID,
+			&i.StationID,
+			&i.SupplyType,
+			&i.QuantityNeeded,
+			&i.Description,
+			&i.UrgencyLevel,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastConfirmedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -592,19 +874,21 @@ SET supply_type = $2,
     quantity_needed = $3,
     description = $4,
     urgency_level = $5,
-    updated_at = CURRENT_TIMESTAMP
+    updated_at = CURRENT_TIMESTAMP,
+    last_confirmed_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at
+RETURNING id, station_id, supply_type, quantity_needed, description, urgency_level, created_at, updated_at, last_confirmed_at
 `
 
 type UpdateSupplyNeedParams struct {
-	ID             int32       `json:"id"`
-	SupplyType     string      `json:"supply_type"`
-	QuantityNeeded pgtype.Int4 `json:"quantity_needed"`
-	Description    pgtype.Text `json:"description"`
-	UrgencyLevel   pgtype.Text `json:"urgency_level"`
+	ID             int32        `json:"id"`
+	SupplyType     string       `json:"supply_type"`
+	QuantityNeeded pgtype.Int4  `json:"quantity_needed"`
+	Description    pgtype.Text  `json:"description"`
+	UrgencyLevel   UrgencyLevel `json:"urgency_level"`
 }
 
+// Updating a need also counts as confirming that it is still current
 func (q *Queries) UpdateSupplyNeed(ctx context.Context, arg UpdateSupplyNeedParams) (SupplyNeed, error) {
 	row := q.db.QueryRow(ctx, updateSupplyNeed,
 		arg.ID,
@@ -623,6 +907,7 @@ func (q *Queries) UpdateSupplyNeed(ctx context.Context, arg UpdateSupplyNeedPara
 		&i.UrgencyLevel,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LastConfirmedAt,
 	)
 	return i, err
 }
@@ -12,7 +12,7 @@ import (
 )
 
 const activateUser = `-- name: ActivateUser :one
-UPDATE users SET is_active = TRUE WHERE id = $1 RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at
+UPDATE users SET is_active = TRUE, approval_status = 'approved' WHERE id = $1 RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status
 `
 
 // Activate a user (admin only)
@@ -27,6 +27,31 @@ func (q *Queries) ActivateUser(ctx context.Context, id int32) (User, error) {
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
+	)
+	return i, err
+}
+
+const approveUser = `-- name: ApproveUser :one
+UPDATE users
+SET is_active = TRUE, approval_status = 'approved'
+WHERE id = $1 AND approval_status <> 'approved'
+RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status
+`
+
+// Approve a pending or previously rejected user and activate their account
+func (q *Queries) ApproveUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRow(ctx, approveUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.OidcSub,
+		&i.Username,
+		&i.Email,
+		&i.IsActive,
+		&i.TrustPoints,
+		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
@@ -54,6 +79,17 @@ func (q *Queries) CheckUserPermission(ctx context.Context, arg CheckUserPermissi
 	return has_permission, err
 }
 
+const countPendingUsers = `-- name: CountPendingUsers :one
+SELECT COUNT(*) FROM users WHERE approval_status = 'pending' AND is_active = FALSE
+`
+
+func (q *Queries) CountPendingUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countPendingUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUsers = `-- name: CountUsers :one
 SELECT COUNT(*) FROM users
 `
@@ -68,7 +104,7 @@ func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (oidc_sub, username, email, trust_points)
 VALUES ($1, $2, $3, $4)
-RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at
+RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status
 `
 
 type CreateUserParams struct {
@@ -94,6 +130,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
@@ -101,7 +138,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 const createUserFromOIDC = `-- name: CreateUserFromOIDC :one
 INSERT INTO users (oidc_sub, username, email, is_active, trust_points)
 VALUES ($1, $2, $3, FALSE, 0)
-RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at
+RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status
 `
 
 type CreateUserFromOIDCParams struct {
@@ -122,12 +159,13 @@ func (q *Queries) CreateUserFromOIDC(ctx context.Context, arg CreateUserFromOIDC
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
 
 const deactivateUser = `-- name: DeactivateUser :one
-UPDATE users SET is_active = FALSE WHERE id = $1 RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at
+UPDATE users SET is_active = FALSE WHERE id = $1 RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status
 `
 
 // Deactivate a user (admin only) - blocks login without deleting data
@@ -142,6 +180,7 @@ func (q *Queries) DeactivateUser(ctx context.Context, id int32) (User, error) {
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
@@ -156,7 +195,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 }
 
 const getActiveUserByOIDCSub = `-- name: GetActiveUserByOIDCSub :one
-SELECT id, oidc_sub, username, email, is_active, trust_points, created_at FROM users WHERE oidc_sub = $1 AND is_active = TRUE LIMIT 1
+SELECT id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status FROM users WHERE oidc_sub = $1 AND is_active = TRUE LIMIT 1
 `
 
 // Find an active user by their OIDC subject identifier (for login validation)
@@ -171,12 +210,13 @@ func (q *Queries) GetActiveUserByOIDCSub(ctx context.Context, oidcSub string) (U
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, oidc_sub, username, email, is_active, trust_points, created_at FROM users WHERE email = $1 LIMIT 1
+SELECT id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status FROM users WHERE email = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email pgtype.Text) (User, error) {
@@ -190,13 +230,14 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email pgtype.Text) (User,
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
 
-SELECT id, oidc_sub, username, email, is_active, trust_points, created_at FROM users WHERE id = $1 LIMIT 1
+SELECT id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status FROM users WHERE id = $1 LIMIT 1
 `
 
 // internal/db/queries/user.sql
@@ -212,12 +253,13 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
 
 const getUserByOIDCSub = `-- name: GetUserByOIDCSub :one
-SELECT id, oidc_sub, username, email, is_active, trust_points, created_at FROM users WHERE oidc_sub = $1 LIMIT 1
+SELECT id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status FROM users WHERE oidc_sub = $1 LIMIT 1
 `
 
 // Find a user by their OIDC subject identifier
@@ -232,12 +274,13 @@ func (q *Queries) GetUserByOIDCSub(ctx context.Context, oidcSub string) (User, e
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, oidc_sub, username, email, is_active, trust_points, created_at FROM users WHERE username = $1 LIMIT 1
+SELECT id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status FROM users WHERE username = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
@@ -251,6 +294,7 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
@@ -286,7 +330,7 @@ func (q *Queries) GetUserPermissions(ctx context.Context, id int32) ([]AppPermis
 
 const getUserWithRoles = `-- name: GetUserWithRoles :many
 SELECT 
-    u.id, u.oidc_sub, u.username, u.email, u.is_active, u.trust_points, u.created_at,
+    u.id, u.oidc_sub, u.username, u.email, u.is_active, u.trust_points, u.created_at, u.approval_status,
     r.id as role_id,
     r.name as role_name,
     r.description as role_description
@@ -304,6 +348,7 @@ type GetUserWithRolesRow struct {
 	IsActive        pgtype.Bool        `json:"is_active"`
 	TrustPoints     pgtype.Int4        `json:"trust_points"`
 	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	ApprovalStatus  string             `json:"approval_status"`
 	RoleID          pgtype.Int4        `json:"role_id"`
 	RoleName        NullAppRole        `json:"role_name"`
 	RoleDescription pgtype.Text        `json:"role_description"`
@@ -326,6 +371,7 @@ func (q *Queries) GetUserWithRoles(ctx context.Context, id int32) ([]GetUserWith
 			&i.IsActive,
 			&i.TrustPoints,
 			&i.CreatedAt,
+			&i.ApprovalStatus,
 			&i.RoleID,
 			&i.RoleName,
 			&i.RoleDescription,
@@ -340,8 +386,51 @@ func (q *Queries) GetUserWithRoles(ctx context.Context, id int32) ([]GetUserWith
 	return items, nil
 }
 
+const listPendingUsers = `-- name: ListPendingUsers :many
+SELECT id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status FROM users
+WHERE approval_status = 'pending' AND is_active = FALSE
+ORDER BY created_at ASC
+LIMIT $1 OFFSET $2
+`
+
+type ListPendingUsersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+// List users awaiting admin approval, oldest first. Active users are never pending,
+// even if they predate approvals and kept the column default.
+func (q *Queries) ListPendingUsers(ctx context.Context, arg ListPendingUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listPendingUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.OidcSub,
+			&i.Username,
+			&i.Email,
+			&i.IsActive,
+			&i.TrustPoints,
+			&i.CreatedAt,
+			&i.ApprovalStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, oidc_sub, username, email, is_active, trust_points, created_at FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2
+SELECT id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2
 `
 
 type ListUsersParams struct {
@@ -366,6 +455,7 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.IsActive,
 			&i.TrustPoints,
 			&i.CreatedAt,
+			&i.ApprovalStatus,
 		); err != nil {
 			return nil, err
 		}
@@ -377,11 +467,35 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const rejectUser = `-- name: RejectUser :one
+UPDATE users
+SET is_active = FALSE, approval_status = 'rejected'
+WHERE id = $1 AND approval_status = 'pending' AND is_active = FALSE
+RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status
+`
+
+// Reject a pending user; the account stays inactive
+func (q *Queries) RejectUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRow(ctx, rejectUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.OidcSub,
+		&i.Username,
+		&i.Email,
+		&i.IsActive,
+		&i.TrustPoints,
+		&i.CreatedAt,
+		&i.ApprovalStatus,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET username = $2, email = $3
 WHERE id = $1
-RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at
+RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status
 `
 
 type UpdateUserParams struct {
@@ -401,12 +515,13 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
 
 const updateUserOIDCSub = `-- name: UpdateUserOIDCSub :one
-UPDATE users SET oidc_sub = $2 WHERE id = $1 RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at
+UPDATE users SET oidc_sub = $2 WHERE id = $1 RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status
 `
 
 type UpdateUserOIDCSubParams struct {
@@ -426,6 +541,7 @@ func (q *Queries) UpdateUserOIDCSub(ctx context.Context, arg UpdateUserOIDCSubPa
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
@@ -434,7 +550,7 @@ const updateUserTrustPoints = `-- name: UpdateUserTrustPoints :one
 UPDATE users
 SET trust_points = trust_points + $2
 WHERE id = $1
-RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at
+RETURNING id, oidc_sub, username, email, is_active, trust_points, created_at, approval_status
 `
 
 type UpdateUserTrustPointsParams struct {
@@ -453,6 +569,7 @@ func (q *Queries) UpdateUserTrustPoints(ctx context.Context, arg UpdateUserTrust
 		&i.IsActive,
 		&i.TrustPoints,
 		&i.CreatedAt,
+		&i.ApprovalStatus,
 	)
 	return i, err
 }
@@ -0,0 +1,114 @@
+-- internal/db/queries/alert.sql
+-- SQL queries for official CAP alerts (used by sqlc)
+-- "Active" means: status = 'Actual', msg_type Alert or Update, not superseded,
+-- effective and not expired. Where an alert applies is decided by alert_covers.
+
+-- name: CreateAlert :one
+-- Stores a CAP alert. The area is given as WKT polygons, which are merged into one
+-- valid multipolygon. territory_wide is set only for alerts without any <area>.
+-- Returns no rows if the sender already published this identifier.
+INSERT INTO alerts (
+    news_id, sender, identifier, sent_at, status, msg_type, event, headline,
+    description, instruction, language, categories, severity, urgency, certainty,
+    effective_at, onset_at, expires_at, area_desc, area, geocodes, infos, source_url,
+    territory_wide
+)
+VALUES (
+    sqlc.narg(news_id), sqlc.arg(sender), sqlc.arg(identifier), sqlc.arg(sent_at),
+    sqlc.arg(status), sqlc.arg(msg_type), sqlc.arg(event), sqlc.narg(headline),
+    sqlc.narg(description), sqlc.narg(instruction), sqlc.narg(language), sqlc.arg(categories)::text[],
+    sqlc.arg(severity), sqlc.arg(urgency), sqlc.arg(certainty),
+    sqlc.arg(effective_at), sqlc.narg(onset_at), sqlc.narg(expires_at), sqlc.narg(area_desc),
+    ST_Multi(ST_CollectionExtract(ST_UnaryUnion(ST_MakeValid(ST_GeomFromText(sqlc.narg(area_wkt)::text, 4326))), 3))::geography,
+    sqlc.arg(geocodes), sqlc.arg(infos), sqlc.narg(source_url),
+    sqlc.arg(territory_wide)
+)
+ON CONFLICT (sender, identifier) DO NOTHING
+RETURNING id;
+
+-- name: SupersedeAlerts :execrows
+-- Ends the alerts referenced by a later Update or Cancel. senders[i] and
+-- identifiers[i] identify the i-th referenced alert.
+UPDATE alerts
+SET superseded_at = CURRENT_TIMESTAMP
+WHERE superseded_at IS NULL
+  AND (sender, identifier) IN (
+      SELECT * FROM unnest(sqlc.arg(senders)::text[], sqlc.arg(identifiers)::text[])
+  );
+
+-- name: AlertSourceExists :one
+SELECT EXISTS (SELECT 1 FROM alerts WHERE source_url = $1);
+
+-- name: AlertSourceFailed :one
+-- Whether a linked CAP document failed for good while its index entry was unchanged.
+SELECT EXISTS (
+    SELECT 1 FROM alert_source_failures WHERE source_url = $1 AND entry_hash = $2
+);
+
+-- name: RecordAlertSourceFailure :exec
+INSERT INTO alert_source_failures (source_url, feed_name, entry_hash, last_status, last_error)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (source_url) DO UPDATE
+SET feed_name = EXCLUDED.feed_name,
+    entry_hash = EXCLUDED.entry_hash,
+    last_status = EXCLUDED.last_status,
+    last_error = EXCLUDED.last_error,
+    failed_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteAlertSourceFailure :exec
+DELETE FROM alert_source_failures WHERE source_url = $1;
+
+-- name: GetAlertByID :one
+SELECT id, news_id, sender, identifier, sent_at, status, msg_type, event, headline,
+    description, instruction, language, categories, severity, urgency, certainty,
+    effective_at, onset_at, expires_at, area_desc, ST_AsGeoJSON(area)::jsonb AS area,
+    territory_wide, geocodes, infos, superseded_at, created_at,
+    (status = 'Actual' AND msg_type IN ('Alert', 'Update') AND superseded_at IS NULL
+     AND effective_at <= CURRENT_TIMESTAMP
+     AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP))::boolean AS active
+FROM alerts
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListActiveAlerts :many
+-- Active alerts, worst first, optionally only those covering a point.
+SELECT id, news_id, sender, identifier, sent_at, status, msg_type, event, headline,
+    description, instruction, language, categories, severity, urgency, certainty,
+    effective_at, onset_at, expires_at, area_desc, ST_AsGeoJSON(area)::jsonb AS area,
+    territory_wide, geocodes, infos, superseded_at, created_at, TRUE AS active
+FROM alerts a
+WHERE status = 'Actual' AND msg_type IN ('Alert', 'Update') AND superseded_at IS NULL
+  AND effective_at <= CURRENT_TIMESTAMP
+  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+  AND (sqlc.narg(lat)::float8 IS NULL
+       OR alert_covers(a, ST_SetSRID(ST_MakePoint(sqlc.narg(lng)::float8, sqlc.narg(lat)::float8), 4326)::geography))
+ORDER BY severity DESC, urgency DESC, sent_at DESC, id DESC;
+
+-- name: ListActiveAlertsForStations :many
+-- The active alerts covering each of the given stations, worst first.
+SELECT s.id AS station_id, a.id, a.event, a.headline, a.severity, a.urgency, a.certainty,
+    a.effective_at, a.expires_at
+FROM supply_stations s
+JOIN alerts a ON alert_covers(a, s.location)
+WHERE s.id = ANY(sqlc.arg(station_ids)::int[])
+  AND a.status = 'Actual' AND a.msg_type IN ('Alert', 'Update') AND a.superseded_at IS NULL
+  AND a.effective_at <= CURRENT_TIMESTAMP
+  AND (a.expires_at IS NULL OR a.expires_at > CURRENT_TIMESTAMP)
+ORDER BY s.id, a.severity DESC, a.urgency DESC, a.sent_at DESC, a.id DESC;
+
+-- name: UpsertDistrict :exec
+-- Stores a district boundary given as GeoJSON in the given SRID, e.g. 2326 for the
+-- Hong Kong 1980 Grid. Pieces are merged and invalid rings repaired.
+INSERT INTO districts (code, name_en, name_zh, boundary)
+VALUES (
+    sqlc.arg(code), sqlc.arg(name_en), sqlc.arg(name_zh),
+    ST_Multi(ST_CollectionExtract(ST_UnaryUnion(ST_MakeValid(ST_Transform(
+        ST_SetSRID(ST_GeomFromGeoJSON(sqlc.arg(geometry)::text), sqlc.arg(srid)::int), 4326))), 3))::geography
+)
+ON CONFLICT (code) DO UPDATE
+SET name_en = EXCLUDED.name_en,
+    name_zh = EXCLUDED.name_zh,
+    boundary = EXCLUDED.boundary;
+
+-- name: CountDistricts :one
+SELECT COUNT(*) FROM districts;
@@ -0,0 +1,42 @@
+-- internal/db/queries/apikey.sql
+-- SQL queries for service account API keys (used by sqlc)
+
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, prefix, key_hash, rate_limit_per_minute, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAPIKeyByID :one
+SELECT * FROM api_keys WHERE id = $1 LIMIT 1;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT * FROM api_keys WHERE prefix = $1 LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+ORDER BY created_at DESC;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchAPIKeyLastUsed :exec
+-- Record key usage, writing at most once a minute per key
+UPDATE api_keys
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute');
+
+-- name: AddAPIKeyPermission :exec
+INSERT INTO api_key_permissions (api_key_id, permission_id)
+SELECT $1, p.id FROM permissions p WHERE p.name = $2
+ON CONFLICT DO NOTHING;
+
+-- name: GetAPIKeyPermissions :many
+SELECT p.name
+FROM api_key_permissions akp
+JOIN permissions p ON p.id = akp.permission_id
+WHERE akp.api_key_id = $1
+ORDER BY p.name;
@@ -1,46 +1,127 @@
 -- internal/db/queries/audit.sql
--- SQL queries for RBAC audit log operations (used by sqlc)
+-- SQL queries for audit log operations (used by sqlc)
 
 -- name: GetAuditLogByID :one
-SELECT * FROM rbac_audit_logs WHERE id = $1 LIMIT 1;
+SELECT * FROM audit_logs WHERE id = $1 LIMIT 1;
 
 -- name: ListAuditLogs :many
-SELECT * FROM rbac_audit_logs
+SELECT * FROM audit_logs
 ORDER BY changed_at DESC
 LIMIT $1 OFFSET $2;
 
+-- name: ListAuditLogsFiltered :many
+-- Combines the ListAuditLogsBy* filters; a NULL filter matches every row.
+SELECT * FROM audit_logs
+WHERE (sqlc.narg(table_name)::text IS NULL OR table_name = sqlc.narg(table_name)::text)
+  AND (sqlc.narg(record_id)::text IS NULL OR record_id = sqlc.narg(record_id)::text)
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action)::text)
+  AND (sqlc.narg(changed_by)::integer IS NULL OR changed_by = sqlc.narg(changed_by)::integer)
+  AND (sqlc.narg(request_id)::text IS NULL OR request_id = sqlc.narg(request_id)::text)
+  AND (sqlc.narg(changed_from)::timestamptz IS NULL OR changed_at >= sqlc.narg(changed_from)::timestamptz)
+  AND (sqlc.narg(changed_to)::timestamptz IS NULL OR changed_at <= sqlc.narg(changed_to)::timestamptz)
+ORDER BY changed_at DESC, id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountAuditLogsFiltered :one
+SELECT COUNT(*) FROM audit_logs
+WHERE (sqlc.narg(table_name)::text IS NULL OR table_name = sqlc.narg(table_name)::text)
+  AND (sqlc.narg(record_id)::text IS NULL OR record_id = sqlc.narg(record_id)::text)
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action)::text)
+  AND (sqlc.narg(changed_by)::integer IS NULL OR changed_by = sqlc.narg(changed_by)::integer)
+  AND (sqlc.narg(request_id)::text IS NULL OR request_id = sqlc.narg(request_id)::text)
+  AND (sqlc.narg(changed_from)::timestamptz IS NULL OR changed_at >= sqlc.narg(changed_from)::timestamptz)
+  AND (sqlc.narg(changed_to)::timestamptz IS NULL OR changed_at <= sqlc.narg(changed_to)::timestamptz);
+
 -- name: ListAuditLogsByTable :many
-SELECT * FROM rbac_audit_logs
+SELECT * FROM audit_logs
 WHERE table_name = $1
 ORDER BY changed_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: ListAuditLogsByAction :many
-SELECT * FROM rbac_audit_logs
+SELECT * FROM audit_logs
 WHERE action = $1
 ORDER BY changed_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: ListAuditLogsByUser :many
-SELECT * FROM rbac_audit_logs
+SELECT * FROM audit_logs
 WHERE changed_by = $1
 ORDER BY changed_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: ListAuditLogsInDateRange :many
-SELECT * FROM rbac_audit_logs
+SELECT * FROM audit_logs
 WHERE changed_at >= $1 AND changed_at <= $2
 ORDER BY changed_at DESC
 LIMIT $3 OFFSET $4;
 
 -- name: CountAuditLogs :one
-SELECT COUNT(*) FROM rbac_audit_logs;
+SELECT COUNT(*) FROM audit_logs;
 
 -- name: CreateAuditLog :one
-INSERT INTO rbac_audit_logs (table_name, action, old_data, new_data, changed_by)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO audit_logs (table_name, record_id, action, old_data, new_data, changed_by)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
--- name: DeleteOldAuditLogs :exec
-DELETE FROM rbac_audit_logs WHERE changed_at < $1;
+-- name: SetAuditContext :exec
+-- Attribute the current transaction's changes for audit triggers (SET LOCAL semantics).
+-- Empty values are recorded as NULL.
+SELECT
+    set_config('app.current_user_id', sqlc.arg(user_id)::text, true),
+    set_config('app.request_id', sqlc.arg(request_id)::text, true);
+
+-- Hash chain
+
+-- name: LockAuditChain :exec
+-- Holds off appends to the chain (see chain_audit_log) until the transaction ends.
+SELECT pg_advisory_xact_lock(hashtext('audit_logs_chain'));
+
+-- name: ListAuditCheckpointLinks :many
+-- Archived links of a checkpoint in ID order, each with the hash recomputed from the
+-- archived contents, so that edits to audit_checkpoints.entries show up as a break.
+SELECT e.id, e.prev_hash, e.row_hash, audit_log_hash(e)::text AS computed_hash
+FROM audit_checkpoints c, jsonb_populate_recordset(NULL::audit_logs, c.entries) e
+WHERE c.id = $1
+ORDER BY e.id;
+
+-- name: ListAuditChainLinks :many
+-- Chain links in ID order, each with the hash recomputed from the entry's contents.
+SELECT a.id, a.prev_hash, a.row_hash, audit_log_hash(a)::text AS computed_hash
+FROM audit_logs a
+WHERE a.id > sqlc.arg(after_id) AND a.id <= sqlc.arg(through_id)
+ORDER BY a.id
+LIMIT sqlc.arg(batch_size);
+
+-- name: GetAuditLogPruneBoundary :one
+-- Last ID of the oldest entries changed before the cutoff. Pruning removes the start of
+-- the chain, so it stops at the first entry changed at or after the cutoff.
+SELECT COALESCE(
+    (SELECT MIN(id) - 1 FROM audit_logs WHERE changed_at >= sqlc.arg(cutoff)::timestamptz),
+    (SELECT MAX(id) FROM audit_logs),
+    0
+)::integer AS last_id;
+
+-- name: CreateAuditCheckpoint :one
+-- Archives the entries first_id..last_id into a checkpoint.
+INSERT INTO audit_checkpoints (first_id, last_id, entry_count, prev_hash, last_hash, entries, signature)
+SELECT
+    sqlc.arg(first_id)::integer,
+    sqlc.arg(last_id)::integer,
+    sqlc.arg(entry_count)::integer,
+    sqlc.narg(prev_hash)::text,
+    sqlc.arg(last_hash)::text,
+    jsonb_agg(to_jsonb(a) ORDER BY a.id),
+    sqlc.arg(signature)::text
+FROM audit_logs a
+WHERE a.id BETWEEN sqlc.arg(first_id)::integer AND sqlc.arg(last_id)::integer
+RETURNING id, first_id, last_id, entry_count, prev_hash, last_hash, signature, created_at;
+
+-- name: DeleteAuditLogsThrough :execrows
+DELETE FROM audit_logs WHERE id <= sqlc.arg(last_id);
 
+-- name: ListAuditCheckpoints :many
+-- Checkpoints oldest first, without their archived entries.
+SELECT id, first_id, last_id, entry_count, prev_hash, last_hash, signature, created_at
+FROM audit_checkpoints
+ORDER BY last_id;
@@ -71,3 +71,11 @@ JOIN users u ON c.user_id = u.id
 WHERE c.station_id = $1
 ORDER BY c.checkin_time DESC;
 
+-- name: GetDistanceToStation :one
+-- Distance in meters between a station and the given point, used to verify on-scene check-ins
+SELECT ST_Distance(
+    location,
+    ST_SetSRID(ST_MakePoint(sqlc.arg(lng)::float8, sqlc.arg(lat)::float8), 4326)::geography
+)::float8 AS distance_meters
+FROM supply_stations
+WHERE id = sqlc.arg(station_id);
@@ -67,3 +67,27 @@ FROM donations d
 LEFT JOIN users u ON d.donor_id = u.id
 WHERE d.id = $1;
 
+-- name: TransitionDonationStatus :one
+-- Compare-and-set status change; returns no rows if the status changed concurrently
+UPDATE donations
+SET status = sqlc.arg(to_status),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: CreateDonationStatusHistory :one
+INSERT INTO donation_status_history (donation_id, from_status, to_status, changed_by, location, note)
+VALUES (
+    sqlc.arg(donation_id),
+    sqlc.narg(from_status),
+    sqlc.arg(to_status),
+    sqlc.narg(changed_by),
+    ST_SetSRID(ST_MakePoint(sqlc.narg(lng)::float8, sqlc.narg(lat)::float8), 4326)::geography,
+    sqlc.narg(note)
+)
+RETURNING *;
+
+-- name: ListDonationStatusHistory :many
+SELECT * FROM donation_status_history
+WHERE donation_id = $1
+ORDER BY changed_at, id;
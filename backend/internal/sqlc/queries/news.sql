@@ -28,8 +28,8 @@ SELECT COUNT(*) FROM news;
 SELECT COUNT(*) FROM news WHERE source = $1;
 
 -- name: CreateNews :one
-INSERT INTO news (source, title, content, url, published_at, relevant_to)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO news (source, title, content, url, published_at, relevant_to, content_hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: UpdateNews :one
@@ -39,7 +39,8 @@ SET source = $2,
     content = $4,
     url = $5,
     published_at = $6,
-    relevant_to = $7
+    relevant_to = $7,
+    content_hash = $8
 WHERE id = $1
 RETURNING *;
 
@@ -61,3 +62,93 @@ WHERE source = $1
 ORDER BY published_at DESC NULLS LAST
 LIMIT 1;
 
+
+-- name: SearchNews :many
+-- Full-text search over title and content, best matches first. See news_search_query
+-- in schema.sql for how English and Chinese queries are parsed.
+SELECT n.*, ts_rank_cd(news_search_vector(n.title, n.content), q.query)::float4 AS rank
+FROM news n, news_search_query(sqlc.arg(query)::text) AS q(query)
+WHERE news_search_vector(n.title, n.content) @@ q.query
+  AND (sqlc.narg(source)::text IS NULL OR n.source = sqlc.narg(source)::text)
+  AND (sqlc.narg(tags)::jsonb IS NULL OR n.relevant_to @> sqlc.narg(tags)::jsonb)
+ORDER BY rank DESC, n.published_at DESC NULLS LAST, n.id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountSearchNews :one
+SELECT COUNT(*)
+FROM news n, news_search_query(sqlc.arg(query)::text) AS q(query)
+WHERE news_search_vector(n.title, n.content) @@ q.query
+  AND (sqlc.narg(source)::text IS NULL OR n.source = sqlc.narg(source)::text)
+  AND (sqlc.narg(tags)::jsonb IS NULL OR n.relevant_to @> sqlc.narg(tags)::jsonb);
+
+-- name: GetNewsByURL :one
+SELECT * FROM news WHERE url = $1 LIMIT 1;
+
+-- name: GetNewsByContentHash :one
+SELECT * FROM news WHERE content_hash = $1 LIMIT 1;
+
+-- name: IngestNews :one
+-- Inserts a fetched feed entry with its suggested tags. Returns no rows if an item
+-- with the same URL or content hash already exists.
+INSERT INTO news (source, title, content, url, published_at, relevant_to, content_hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: UpdateIngestedNews :one
+-- Refreshes an ingested item whose feed entry changed. Returns no rows if nothing changed.
+-- Suggested tags are replaced, but tags reviewed by an editor are kept.
+UPDATE news
+SET title = sqlc.arg(title),
+    content = sqlc.arg(content),
+    published_at = sqlc.arg(published_at),
+    content_hash = sqlc.arg(content_hash),
+    relevant_to = CASE WHEN relevant_to @> '{"reviewed": true}' THEN relevant_to ELSE sqlc.arg(relevant_to) END,
+    fetched_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+  AND (content_hash IS DISTINCT FROM sqlc.arg(content_hash)
+       OR published_at IS DISTINCT FROM sqlc.arg(published_at))
+RETURNING *;
+
+-- name: ListNewsByTags :many
+-- Items whose relevant_to contains tags, e.g. {"districts": ["KC"]}, newest first.
+-- Uses idx_news_relevant_to.
+SELECT * FROM news
+WHERE relevant_to @> sqlc.arg(tags)::jsonb
+  AND (sqlc.narg(source)::text IS NULL OR source = sqlc.narg(source)::text)
+ORDER BY published_at DESC NULLS LAST, fetched_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountNewsByTags :one
+SELECT COUNT(*) FROM news
+WHERE relevant_to @> sqlc.arg(tags)::jsonb
+  AND (sqlc.narg(source)::text IS NULL OR source = sqlc.narg(source)::text);
+
+-- name: ListNewsByAnyTags :many
+-- Items whose relevant_to contains any of the given tag objects, newest first. Each one
+-- is a containment lookup on idx_news_relevant_to, combined in a bitmap index scan.
+SELECT * FROM news
+WHERE relevant_to @> ANY(sqlc.arg(tags)::jsonb[])
+ORDER BY published_at DESC NULLS LAST, fetched_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountNewsByAnyTags :one
+SELECT COUNT(*) FROM news
+WHERE relevant_to @> ANY(sqlc.arg(tags)::jsonb[]);
+
+-- name: ListStationDistricts :many
+-- Codes of the districts whose boundary contains the station, usually one.
+SELECT d.code FROM districts d
+JOIN supply_stations s ON ST_Covers(d.boundary, s.location)
+WHERE s.id = $1
+ORDER BY d.code;
+
+-- name: ListUnknownStationIDs :many
+-- The given station IDs that do not exist, for validating news tags.
+SELECT t.id::int FROM unnest(sqlc.arg(ids)::int[]) AS t(id)
+WHERE NOT EXISTS (SELECT 1 FROM supply_stations s WHERE s.id = t.id)
+ORDER BY t.id;
+
+-- name: UpdateNewsTags :one
+UPDATE news SET relevant_to = $2 WHERE id = $1
+RETURNING *;
@@ -0,0 +1,50 @@
+-- internal/db/queries/news_feeds.sql
+-- SQL queries for news feed polling state (used by sqlc)
+
+-- name: RegisterNewsFeed :exec
+-- Adds a configured feed, or updates its URL. A new URL drops the cache
+-- validators and failure count of the old one and is fetched right away.
+INSERT INTO news_feeds (name, url)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE
+SET url = EXCLUDED.url,
+    etag = NULL,
+    last_modified = NULL,
+    consecutive_failures = 0,
+    next_fetch_at = CURRENT_TIMESTAMP
+WHERE news_feeds.url <> EXCLUDED.url;
+
+-- name: GetNewsFeed :one
+SELECT * FROM news_feeds WHERE name = $1 LIMIT 1;
+
+-- name: ListNewsFeeds :many
+SELECT * FROM news_feeds ORDER BY name;
+
+-- name: ClaimNewsFeed :one
+-- Claims a feed that is due by pushing next_fetch_at out by the lease. Returns
+-- no rows if the feed is not due, e.g. because another instance claimed it.
+UPDATE news_feeds
+SET next_fetch_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(lease_seconds)::float8)
+WHERE name = sqlc.arg(name) AND next_fetch_at <= CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: RecordNewsFeedSuccess :exec
+UPDATE news_feeds
+SET etag = sqlc.narg(etag),
+    last_modified = sqlc.narg(last_modified),
+    consecutive_failures = 0,
+    last_status = sqlc.arg(last_status),
+    last_error = NULL,
+    last_fetched_at = CURRENT_TIMESTAMP,
+    last_success_at = CURRENT_TIMESTAMP,
+    next_fetch_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(delay_seconds)::float8)
+WHERE name = sqlc.arg(name);
+
+-- name: RecordNewsFeedFailure :exec
+UPDATE news_feeds
+SET consecutive_failures = consecutive_failures + 1,
+    last_status = sqlc.narg(last_status),
+    last_error = sqlc.arg(last_error),
+    last_fetched_at = CURRENT_TIMESTAMP,
+    next_fetch_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(delay_seconds)::float8)
+WHERE name = sqlc.arg(name);
@@ -0,0 +1,22 @@
+-- internal/db/queries/refresh_token.sql
+-- SQL queries for refresh token rotation (used by sqlc)
+
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (user_id, family_id, token_hash, generation, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetRefreshTokenByHash :one
+SELECT * FROM refresh_tokens WHERE token_hash = $1 LIMIT 1;
+
+-- name: MarkRefreshTokenUsed :execrows
+-- Compare-and-set: only an unused, unrevoked token can be rotated,
+-- so of two concurrent refreshes with the same token exactly one wins.
+UPDATE refresh_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE family_id = $1 AND revoked_at IS NULL;
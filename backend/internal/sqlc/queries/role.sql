@@ -6,6 +6,9 @@
 -- name: GetRoleByID :one
 SELECT * FROM roles WHERE id = $1 LIMIT 1;
 
+-- name: LockRole :one
+SELECT * FROM roles WHERE id = $1 LIMIT 1 FOR UPDATE;
+
 -- name: GetRoleByName :one
 SELECT * FROM roles WHERE name = $1 LIMIT 1;
 
@@ -102,3 +105,8 @@ JOIN user_roles ur ON u.id = ur.user_id
 WHERE ur.role_id = $1
 ORDER BY u.username;
 
+-- name: CountActiveUsersWithRole :one
+SELECT COUNT(*)
+FROM users u
+JOIN user_roles ur ON u.id = ur.user_id
+WHERE ur.role_id = $1 AND u.is_active = TRUE;
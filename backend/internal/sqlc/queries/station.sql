@@ -81,6 +81,86 @@ WHERE is_verified = TRUE
 ORDER BY distance_meters
 LIMIT $4;
 
+-- name: FindNearbyStationsWithNeed :many
+-- Nearby stations that have at least one need matching the optional supply type and urgency filters
+SELECT s.*,
+    ST_Distance(s.location, ST_SetSRID(ST_MakePoint(sqlc.arg(lng)::float8, sqlc.arg(lat)::float8), 4326)::geography) as distance_meters
+FROM supply_stations s
+WHERE ST_DWithin(s.location, ST_SetSRID(ST_MakePoint(sqlc.arg(lng)::float8, sqlc.arg(lat)::float8), 4326)::geography, sqlc.arg(radius_meters)::float8)
+  AND (NOT sqlc.arg(verified_only)::boolean OR s.is_verified = TRUE)
+  AND EXISTS (
+      SELECT 1 FROM supply_needs n
+      WHERE n.station_id = s.id
+        AND (sqlc.narg(supply_type)::text IS NULL OR n.supply_type = sqlc.narg(supply_type)::text)
+        AND (sqlc.narg(urgency_level)::urgency_level IS NULL OR n.urgency_level = sqlc.narg(urgency_level)::urgency_level)
+  )
+ORDER BY distance_meters
+LIMIT sqlc.arg(max_results);
+
+-- name: ListStationFeatures :many
+-- Keyset-paginated station export with decoded coordinates and aggregated needs,
+-- optionally restricted to a bounding box (used for GeoJSON streaming)
+SELECT
+    s.id,
+    ST_X(s.location::geometry)::float8 AS longitude,
+    ST_Y(s.location::geometry)::float8 AS latitude,
+    s.registered_by,
+    s.verification_count,
+    s.verification_threshold,
+    s.is_verified,
+    s.created_at,
+    s.updated_at,
+    COALESCE(
+        (SELECT jsonb_agg(jsonb_build_object(
+                    'supply_type', n.supply_type,
+                    'quantity_needed', n.quantity_needed,
+                    'urgency_level', n.urgency_level,
+                    'description', n.description,
+                    'updated_at', n.updated_at,
+                    'last_confirmed_at', n.last_confirmed_at
+                ) ORDER BY n.urgency_level DESC, n.supply_type)
+         FROM supply_needs n
+         WHERE n.station_id = s.id),
+        '[]'::jsonb
+    )::jsonb AS needs
+FROM supply_stations s
+WHERE s.id > sqlc.arg(after_id)
+  AND (
+      NOT sqlc.arg(use_bbox)::boolean
+      OR s.location && ST_MakeEnvelope(sqlc.arg(min_lng)::float8, sqlc.arg(min_lat)::float8, sqlc.arg(max_lng)::float8, sqlc.arg(max_lat)::float8, 4326)::geography
+  )
+ORDER BY s.id
+LIMIT sqlc.arg(batch_size);
+
+-- name: GetStationTile :one
+-- Render the stations inside web-mercator tile z/x/y as a Mapbox Vector Tile
+-- with verification and highest-urgency attributes
+WITH bounds AS (
+    SELECT ST_TileEnvelope(sqlc.arg(z)::int, sqlc.arg(x)::int, sqlc.arg(y)::int) AS geom
+),
+tile_points AS (
+    SELECT
+        s.id,
+        COALESCE(s.is_verified, false) AS is_verified,
+        COALESCE(s.verification_count, 0) AS verification_count,
+        s.verification_threshold,
+        COALESCE(needs.need_count, 0) AS need_count,
+        COALESCE(needs.max_urgency::text, 'none') AS max_urgency,
+        ST_AsMVTGeom(ST_Transform(s.location::geometry, 3857), bounds.geom, 4096, 64, true) AS geom
+    FROM supply_stations s
+    CROSS JOIN bounds
+    LEFT JOIN LATERAL (
+        SELECT
+            COUNT(*) AS need_count,
+            MAX(n.urgency_level) AS max_urgency
+        FROM supply_needs n
+        WHERE n.station_id = s.id
+    ) needs ON true
+    WHERE s.location::geometry && ST_Transform(bounds.geom, 4326)
+)
+SELECT COALESCE(ST_AsMVT(tile_points, 'stations', 4096, 'geom'), ''::bytea)::bytea AS tile
+FROM tile_points;
+
 -- ==================== Supply Needs ====================
 
 -- name: GetSupplyNeedByID :one
@@ -91,18 +171,33 @@ SELECT * FROM supply_needs
 WHERE station_id = $1
 ORDER BY urgency_level DESC, created_at DESC;
 
+-- name: ListSupplyNeedsByStations :many
+-- Needs for a batch of stations (avoids one query per station when embedding needs)
+SELECT * FROM supply_needs
+WHERE station_id = ANY(sqlc.arg(station_ids)::int[])
+ORDER BY station_id, urgency_level DESC, created_at DESC;
+
 -- name: CreateSupplyNeed :one
 INSERT INTO supply_needs (station_id, supply_type, quantity_needed, description, urgency_level)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: UpdateSupplyNeed :one
+-- Updating a need also counts as confirming that it is still current
 UPDATE supply_needs
 SET supply_type = $2,
     quantity_needed = $3,
     description = $4,
     urgency_level = $5,
-    updated_at = CURRENT_TIMESTAMP
+    updated_at = CURRENT_TIMESTAMP,
+    last_confirmed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: ConfirmSupplyNeed :one
+-- Record that a need is still current without changing it
+UPDATE supply_needs
+SET last_confirmed_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING *;
 
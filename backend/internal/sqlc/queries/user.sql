@@ -81,7 +81,7 @@ RETURNING *;
 
 -- name: ActivateUser :one
 -- Activate a user (admin only)
-UPDATE users SET is_active = TRUE WHERE id = $1 RETURNING *;
+UPDATE users SET is_active = TRUE, approval_status = 'approved' WHERE id = $1 RETURNING *;
 
 -- name: DeactivateUser :one
 -- Deactivate a user (admin only) - blocks login without deleting data
@@ -89,4 +89,29 @@ UPDATE users SET is_active = FALSE WHERE id = $1 RETURNING *;
 
 -- name: UpdateUserOIDCSub :one
 -- Link an existing user to their OIDC account
-UPDATE users SET oidc_sub = $2 WHERE id = $1 RETURNING *;
\ No newline at end of file
+UPDATE users SET oidc_sub = $2 WHERE id = $1 RETURNING *;
+
+-- name: ListPendingUsers :many
+-- List users awaiting admin approval, oldest first. Active users are never pending,
+-- even if they predate approvals and kept the column default.
+SELECT * FROM users
+WHERE approval_status = 'pending' AND is_active = FALSE
+ORDER BY created_at ASC
+LIMIT $1 OFFSET $2;
+
+-- name: CountPendingUsers :one
+SELECT COUNT(*) FROM users WHERE approval_status = 'pending' AND is_active = FALSE;
+
+-- name: ApproveUser :one
+-- Approve a pending or previously rejected user and activate their account
+UPDATE users
+SET is_active = TRUE, approval_status = 'approved'
+WHERE id = $1 AND approval_status <> 'approved'
+RETURNING *;
+
+-- name: RejectUser :one
+-- Reject a pending user; the account stays inactive
+UPDATE users
+SET is_active = FALSE, approval_status = 'rejected'
+WHERE id = $1 AND approval_status = 'pending' AND is_active = FALSE
+RETURNING *;
@@ -44,9 +44,31 @@ CREATE TYPE app_permission AS ENUM (
     'create_supply_needs',-- Add supply needs to stations
     'read_supply_needs',  -- View supply needs
     'update_supply_needs',-- Update supply needs (e.g., quantity, urgency)
-    'delete_supply_needs' -- Delete supply needs
+    'delete_supply_needs',-- Delete supply needs
+
+    -- Partner integrations
+    'manage_api_keys',    -- Create, list and revoke service account API keys
+
+    -- Auditing
+    'read_audit_logs'     -- Query the audit trail
 );
 
+-- Severity of a supply need. Declaration order is severity order, so
+-- ORDER BY urgency_level DESC lists critical needs first.
+CREATE TYPE urgency_level AS ENUM ('low', 'medium', 'high', 'critical');
+
+-- Lifecycle of a donation. Allowed transitions are enforced by the donation service:
+-- pending -> in_transit | cancelled | rejected
+-- in_transit -> delivered | partially_delivered | cancelled | rejected
+-- partially_delivered -> delivered
+CREATE TYPE donation_status AS ENUM ('pending', 'in_transit', 'delivered', 'partially_delivered', 'cancelled', 'rejected');
+
+-- CAP 1.2 severity, urgency and certainty of an official alert, lower-cased. As with
+-- urgency_level, declaration order is increasing, so ORDER BY ... DESC lists the worst first.
+CREATE TYPE alert_severity AS ENUM ('unknown', 'minor', 'moderate', 'severe', 'extreme');
+CREATE TYPE alert_urgency AS ENUM ('unknown', 'past', 'future', 'expected', 'immediate');
+CREATE TYPE alert_certainty AS ENUM ('unknown', 'unlikely', 'possible', 'likely', 'observed');
+
 -- Roles table: Defines user roles for RBAC.
 -- Includes timestamps for auditing and tracking changes.
 CREATE TABLE roles (
@@ -80,12 +102,13 @@ CREATE TABLE role_permissions (
 -- Trust points are used to adjust verification thresholds for supply stations they register.
 CREATE TABLE users (
     id SERIAL PRIMARY KEY,
-    oidc_sub VARCHAR(255) UNIQUE NOT NULL,  -- OIDC subject identifier (sub claim from ID token)
+    oidc_sub VARCHAR(512) UNIQUE NOT NULL,  -- OIDC subject namespaced by issuer: "<issuer>|<sub claim>"
     username VARCHAR(255) UNIQUE NOT NULL,
     email VARCHAR(255) UNIQUE,
     is_active BOOLEAN DEFAULT FALSE,  -- Must be TRUE for user to access the app
     trust_points INTEGER DEFAULT 0,  -- Increases when their registered stations are verified
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    approval_status VARCHAR(20) NOT NULL DEFAULT 'pending'  -- 'pending', 'approved' or 'rejected' (admin review of new sign-ups)
 );
 
 -- User_Roles junction table: Assigns roles to users (many-to-many for flexibility).
@@ -119,9 +142,10 @@ CREATE TABLE supply_needs (
     supply_type VARCHAR(255) NOT NULL,  -- e.g., 'water', 'food', 'medical'
     quantity_needed INTEGER,  -- Optional, could be approximate
     description TEXT,  -- Additional details
-    urgency_level VARCHAR(50),  -- e.g., 'high', 'medium', 'low'
+    urgency_level urgency_level NOT NULL DEFAULT 'medium',
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_confirmed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,  -- Last time someone confirmed the need is still current
     UNIQUE(station_id, supply_type)  -- Prevent duplicates for the same type per station
 );
 
@@ -132,12 +156,24 @@ CREATE TABLE donations (
     station_id INTEGER REFERENCES supply_stations(id) ON DELETE CASCADE,
     supplies JSONB NOT NULL,  -- Flexible: e.g., {"water": 100, "food": 50} or array of items
     delivery_code VARCHAR(50) UNIQUE NOT NULL,  -- Unique code for volunteers to reference
-    status VARCHAR(50) DEFAULT 'pending',  -- e.g., 'pending', 'in_transit', 'delivered'
+    status donation_status NOT NULL DEFAULT 'pending',
     estimated_delivery TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Donation_Status_History table: One row per status change, so coordinators can trace a delivery.
+CREATE TABLE donation_status_history (
+    id SERIAL PRIMARY KEY,
+    donation_id INTEGER NOT NULL REFERENCES donations(id) ON DELETE CASCADE,
+    from_status donation_status,  -- NULL for the entry recorded at registration
+    to_status donation_status NOT NULL,
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    location GEOGRAPHY(POINT, 4326),  -- Optional: where the change was reported
+    note TEXT,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Checkins table: Logs volunteer check-ins for verification.
 -- Can include GPS to verify proximity (optional).
 CREATE TABLE checkins (
@@ -159,7 +195,177 @@ CREATE TABLE news (
     url VARCHAR(512),  -- Link to original
     published_at TIMESTAMP WITH TIME ZONE,
     fetched_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    relevant_to JSONB  -- Optional: Tags or station_ids it's related to
+    relevant_to JSONB,  -- Tags: {"station_ids": [], "districts": [], "supply_types": [], "tags": [], "reviewed": false}
+    content_hash CHAR(64)  -- SHA-256 of the normalized title and content, for deduplication
+);
+
+-- Districts table: Boundaries of Hong Kong's 18 districts, keyed by the codes used in news tags
+-- (e.g. 'ST'). Loaded with `server districts load` from the government's district boundary data. Alerts that give
+-- their area as district geocodes cover the points inside these boundaries.
+CREATE TABLE districts (
+    code VARCHAR(10) PRIMARY KEY,  -- As in news.relevant_to districts, e.g. 'ST'
+    name_en VARCHAR(100) NOT NULL,  -- e.g. 'Sha Tin'
+    name_zh VARCHAR(100) NOT NULL,  -- e.g. '沙田'
+    boundary GEOGRAPHY(MULTIPOLYGON, 4326) NOT NULL
+);
+
+-- Alerts table: Official warnings (typhoon signals, rainstorm and landslip warnings)
+-- ingested from CAP 1.2 feeds. Each alert is also listed as a news item, which it links to.
+-- An alert is active while it is an Actual Alert or Update, effective, not expired and not
+-- superseded by a later Update or Cancel that references it.
+CREATE TABLE alerts (
+    id SERIAL PRIMARY KEY,
+    news_id INTEGER REFERENCES news(id) ON DELETE SET NULL,
+    sender VARCHAR(255) NOT NULL,
+    identifier VARCHAR(255) NOT NULL,  -- Unique per sender
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL,  -- Actual, Exercise, System, Test or Draft
+    msg_type VARCHAR(20) NOT NULL,  -- Alert, Update, Cancel, Ack or Error
+    event VARCHAR(255) NOT NULL,  -- e.g., 'Tropical Cyclone Warning Signal No. 8'
+    headline TEXT,
+    description TEXT,
+    instruction TEXT,
+    language VARCHAR(35),  -- Language of the texts above, from the first <info> block
+    categories TEXT[] NOT NULL DEFAULT '{}',  -- e.g., {Met,Geo}
+    severity alert_severity NOT NULL,
+    urgency alert_urgency NOT NULL,
+    certainty alert_certainty NOT NULL,
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL,  -- Defaults to sent_at
+    onset_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,  -- NULL: in force until superseded
+    area_desc TEXT,
+    area GEOGRAPHY(MULTIPOLYGON, 4326),  -- Union of all usable polygons and circles
+    territory_wide BOOLEAN NOT NULL DEFAULT FALSE,  -- No <area> at all: covers the sender's whole territory
+    geocodes JSONB NOT NULL DEFAULT '[]',  -- [{"name": "...", "value": "..."}], matched against districts
+    infos JSONB NOT NULL DEFAULT '[]',  -- Every <info> block's texts, e.g. in English and Chinese
+    source_url VARCHAR(2048),  -- Where the CAP document was fetched from
+    superseded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(sender, identifier)
+);
+
+-- Whether an alert applies at a location: territory-wide alerts apply everywhere, others where
+-- their area or the boundary of a district named by one of their geocodes (by code or name)
+-- covers the location. An alert whose <area> could not be located applies nowhere.
+CREATE OR REPLACE FUNCTION alert_covers(alert alerts, location GEOGRAPHY)
+RETURNS BOOLEAN AS $$
+    SELECT alert.territory_wide
+        OR (alert.area IS NOT NULL AND ST_Covers(alert.area, location))
+        OR EXISTS (
+            SELECT 1
+            FROM jsonb_array_elements(alert.geocodes) g
+            JOIN districts d ON upper(g->>'value') = d.code
+                OR lower(g->>'value') = lower(d.name_en)
+                OR g->>'value' = d.name_zh
+            WHERE ST_Covers(d.boundary, location)
+        );
+$$ LANGUAGE sql STABLE;
+
+-- News_Feeds table: Polling state of the RSS/Atom and CAP feeds ingested into news, one row per
+-- configured feed. next_fetch_at doubles as a lease, so only one instance polls a feed at a time.
+CREATE TABLE news_feeds (
+    name VARCHAR(100) PRIMARY KEY,  -- Feed name from NEWS_FEEDS
+    url VARCHAR(2048) NOT NULL,
+    etag TEXT,  -- Validators from the last successful fetch, sent back as If-None-Match
+    last_modified TEXT,  -- and If-Modified-Since
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_status INTEGER,  -- HTTP status of the last fetch; NULL if no response was received
+    last_error TEXT,
+    last_fetched_at TIMESTAMP WITH TIME ZONE,
+    last_success_at TIMESTAMP WITH TIME ZONE,
+    next_fetch_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Alert_Source_Failures table: CAP documents linked from a feed's index that failed in a way
+-- fetching them again will not fix, such as a 404 or malformed XML. They are skipped until the
+-- index entry linking them changes, so one bad document does not hold back the rest of the index.
+CREATE TABLE alert_source_failures (
+    source_url VARCHAR(2048) PRIMARY KEY,
+    feed_name VARCHAR(100) NOT NULL REFERENCES news_feeds(name) ON DELETE CASCADE,
+    entry_hash TEXT NOT NULL,  -- Hash of the index entry that linked the document
+    last_status INTEGER,
+    last_error TEXT NOT NULL,
+    failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- News full-text search. The built-in parsers cannot split Chinese, which has no spaces
+-- between words, so runs of CJK characters are indexed as overlapping bigrams (香港天文台 ->
+-- 香港 港天 天文 文台) and searched as phrases of the query's bigrams. Latin text is
+-- stemmed with the english configuration.
+CREATE OR REPLACE FUNCTION cjk_bigrams(input TEXT)
+RETURNS TEXT AS $$
+    SELECT COALESCE(string_agg(substr(run.chars[1], i, 2), ' ' ORDER BY run.n, i), '')
+    FROM regexp_matches(COALESCE(input, ''), '[\u3400-\u4dbf\u4e00-\u9fff\uf900-\ufaff]+', 'g') WITH ORDINALITY AS run(chars, n)
+    CROSS JOIN LATERAL generate_series(1, greatest(length(run.chars[1]) - 1, 1)) AS i;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Indexed terms of the CJK runs in a document: their bigrams followed by each run's last
+-- character (香港天文台 -> 香港 港天 天文 文台 台). Every character then starts a term, so a
+-- one-character query finds characters that end a run (水 in 急需食水) as well.
+CREATE OR REPLACE FUNCTION cjk_index_terms(input TEXT)
+RETURNS TEXT AS $$
+    SELECT COALESCE(string_agg(substr(run.chars[1], i, 2), ' ' ORDER BY run.n, i), '')
+    FROM regexp_matches(COALESCE(input, ''), '[\u3400-\u4dbf\u4e00-\u9fff\uf900-\ufaff]+', 'g') WITH ORDINALITY AS run(chars, n)
+    CROSS JOIN LATERAL generate_series(1, length(run.chars[1])) AS i;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Search document for a news item; title matches rank above content matches.
+CREATE OR REPLACE FUNCTION news_search_vector(title TEXT, content TEXT)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('english', COALESCE(title, '')) || to_tsvector('simple', cjk_index_terms(title)), 'A')
+        || setweight(to_tsvector('english', COALESCE(content, '')) || to_tsvector('simple', cjk_index_terms(content)), 'B');
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Parses a user's search: websearch syntax for Latin words, and for each run of CJK
+-- characters a phrase of its bigrams. A single character is a prefix, matching the
+-- bigrams it starts and, through cjk_index_terms, the runs it ends.
+CREATE OR REPLACE FUNCTION news_search_query(query TEXT)
+RETURNS tsquery AS $$
+    SELECT websearch_to_tsquery('english', regexp_replace(query, '[\u3400-\u4dbf\u4e00-\u9fff\uf900-\ufaff]+', ' ', 'g'))
+        && COALESCE(to_tsquery('simple', (
+            SELECT string_agg(
+                CASE WHEN length(run.chars[1]) = 1 THEN run.chars[1] || ':*'
+                     ELSE '(' || replace(cjk_bigrams(run.chars[1]), ' ', ' <-> ') || ')'
+                END, ' & ')
+            FROM regexp_matches(query, '[\u3400-\u4dbf\u4e00-\u9fff\uf900-\ufaff]+', 'g') AS run(chars)
+        )), ''::tsquery);
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Refresh_Tokens table: Opaque refresh tokens, stored as SHA-256 hashes.
+-- Every login starts a family; each refresh marks the presented token used and issues
+-- the next one in the same family. Presenting a used token again revokes the family.
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,  -- Hex SHA-256 of the token; the token itself is never stored
+    generation BIGINT NOT NULL DEFAULT 0,    -- User's token generation at issue time
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,        -- Set when the token is rotated
+    revoked_at TIMESTAMP WITH TIME ZONE,     -- Set when its family is revoked
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Api_Keys table: Service accounts for partner integrations (NGO inventory systems, government feeds).
+-- A key is shown once at creation; only its SHA-256 hash is stored, looked up by its public prefix.
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,                      -- Service account name, e.g. 'Red Cross inventory sync'
+    prefix VARCHAR(32) UNIQUE NOT NULL,              -- Public part of the key, safe to display
+    key_hash VARCHAR(64) NOT NULL,                   -- Hex SHA-256 of the full key
+    rate_limit_per_minute INTEGER NOT NULL DEFAULT 60,  -- 0 disables rate limiting
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Api_Key_Permissions junction table: Scopes each key to a subset of permissions.
+CREATE TABLE api_key_permissions (
+    api_key_id INTEGER REFERENCES api_keys(id) ON DELETE CASCADE NOT NULL,
+    permission_id INTEGER REFERENCES permissions(id) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (api_key_id, permission_id)
 );
 
 -- Trigger to update is_verified automatically
@@ -231,58 +437,178 @@ CREATE TRIGGER trigger_update_donations
 BEFORE UPDATE ON donations
 FOR EACH ROW EXECUTE FUNCTION update_updated_at();
 
--- Optional: Audit log table for tracking changes to roles/permissions (production best practice)
-CREATE TABLE rbac_audit_logs (
+-- Audit_Logs table: Every change to the audited tables below, written by the audit_changes trigger.
+-- changed_by has no foreign key so the trail outlives the users it mentions.
+-- Entries form a hash chain: row_hash covers the entry and prev_hash, the row_hash of the entry
+-- before it, so editing or deleting an entry breaks the link to the next one.
+CREATE TABLE audit_logs (
     id SERIAL PRIMARY KEY,
-    table_name VARCHAR(50) NOT NULL,  -- e.g., 'role_permissions'
-    action VARCHAR(10) NOT NULL,      -- e.g., 'INSERT', 'UPDATE', 'DELETE'
+    table_name VARCHAR(50) NOT NULL,  -- e.g., 'supply_stations'
+    record_id VARCHAR(64),            -- id column of the changed row
+    action VARCHAR(10) NOT NULL,      -- 'INSERT', 'UPDATE', 'DELETE', or an app-level action such as 'APPROVE'
     old_data JSONB,                   -- Old row data (for updates/deletes)
     new_data JSONB,                   -- New row data (for inserts/updates)
-    changed_by INTEGER REFERENCES users(id),  -- Who made the change (requires app to set)
-    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    changed_by INTEGER,               -- Who made the change (from app.current_user_id, set by the app per transaction)
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    request_id VARCHAR(64) DEFAULT NULLIF(current_setting('app.request_id', true), ''),  -- Request that made the change (set by the app per transaction)
+    prev_hash CHAR(64),               -- row_hash of the previous entry; NULL only for the first entry ever
+    row_hash CHAR(64) NOT NULL        -- Set by the chain_audit_log trigger
+);
+
+-- Audit_Checkpoints table: Segments of audit_logs archived by retention pruning.
+-- The signature (HMAC-SHA256 with AUDIT_CHECKPOINT_SECRET, computed by the app) covers the
+-- segment's ID range, size and boundary hashes; last_hash commits to every archived entry, and
+-- verification re-hashes the entries below to check that they still chain to it.
+CREATE TABLE audit_checkpoints (
+    id SERIAL PRIMARY KEY,
+    first_id INTEGER NOT NULL,        -- First archived audit_logs.id
+    last_id INTEGER NOT NULL,         -- Last archived audit_logs.id
+    entry_count INTEGER NOT NULL,
+    prev_hash CHAR(64),               -- prev_hash of the first archived entry
+    last_hash CHAR(64) NOT NULL,      -- row_hash of the last archived entry; the next entry links to it
+    entries JSONB NOT NULL,           -- The archived entries, oldest first
+    signature CHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
--- Example trigger for auditing role_permissions changes
+-- Hash of an audit log entry, over all its columns including prev_hash.
+CREATE OR REPLACE FUNCTION audit_log_hash(entry audit_logs)
+RETURNS CHAR(64) AS $$
+    SELECT encode(sha256(convert_to(jsonb_build_array(
+        entry.id, entry.table_name, entry.record_id, entry.action, entry.old_data, entry.new_data,
+        entry.changed_by, to_char(entry.changed_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+        entry.request_id, entry.prev_hash
+    )::text, 'UTF8')), 'hex');
+$$ LANGUAGE sql STABLE;
+
+-- Links each new entry to the latest one. The advisory lock serializes appends until commit,
+-- and the ID is drawn under the lock so that ID order is chain order. This is deliberate: a
+-- chain needs one writer at a time, so audited write transactions queue behind each other
+-- from their first audited change until they commit. Keep them short. A transaction that
+-- already holds row locks can deadlock with one holding the chain lock; Postgres aborts one
+-- of them (40P01) and dbtx.Runner.InTx retries it.
+CREATE OR REPLACE FUNCTION chain_audit_log()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('audit_logs_chain'));
+    NEW.id := nextval(pg_get_serial_sequence('audit_logs', 'id'));
+
+    SELECT row_hash INTO NEW.prev_hash FROM audit_logs ORDER BY id DESC LIMIT 1;
+    IF NOT FOUND THEN
+        -- Everything before this entry was pruned; link to the archive
+        SELECT last_hash INTO NEW.prev_hash FROM audit_checkpoints ORDER BY last_id DESC LIMIT 1;
+    END IF;
+
+    NEW.row_hash := audit_log_hash(NEW);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_chain_audit_log
+BEFORE INSERT ON audit_logs
+FOR EACH ROW EXECUTE FUNCTION chain_audit_log();
+
+-- Records a row change in audit_logs, attributed to app.current_user_id.
 -- Uses current_setting with missing_ok=true to handle cases where app.current_user_id isn't set (e.g., during seeding)
-CREATE OR REPLACE FUNCTION audit_rbac_changes()
+CREATE OR REPLACE FUNCTION audit_changes()
 RETURNS TRIGGER AS $$
 DECLARE
     current_user_id INTEGER;
+    old_row JSONB;
+    new_row JSONB;
 BEGIN
+    IF (TG_OP IN ('UPDATE', 'DELETE')) THEN
+        old_row := to_jsonb(OLD);
+    END IF;
+    IF (TG_OP IN ('INSERT', 'UPDATE')) THEN
+        new_row := to_jsonb(NEW);
+    END IF;
+
+    -- Updates that change nothing are not worth recording
+    IF (TG_OP = 'UPDATE' AND old_row = new_row) THEN
+        RETURN NULL;
+    END IF;
+
     -- Get current user ID, returns NULL if not set (e.g., during migrations/seeding)
     BEGIN
-        current_user_id := current_setting('app.current_user_id', true)::INTEGER;
+        current_user_id := NULLIF(current_setting('app.current_user_id', true), '')::INTEGER;
     EXCEPTION WHEN OTHERS THEN
         current_user_id := NULL;
     END;
 
-    IF (TG_OP = 'DELETE') THEN
-        INSERT INTO rbac_audit_logs (table_name, action, old_data, changed_by)
-        VALUES (TG_RELNAME, TG_OP, row_to_json(OLD), current_user_id);
-    ELSIF (TG_OP = 'UPDATE') THEN
-        INSERT INTO rbac_audit_logs (table_name, action, old_data, new_data, changed_by)
-        VALUES (TG_RELNAME, TG_OP, row_to_json(OLD), row_to_json(NEW), current_user_id);
-    ELSIF (TG_OP = 'INSERT') THEN
-        INSERT INTO rbac_audit_logs (table_name, action, new_data, changed_by)
-        VALUES (TG_RELNAME, TG_OP, row_to_json(NEW), current_user_id);
-    END IF;
+    INSERT INTO audit_logs (table_name, record_id, action, old_data, new_data, changed_by)
+    VALUES (TG_TABLE_NAME, COALESCE(new_row, old_row)->>'id', TG_OP, old_row, new_row, current_user_id);
     RETURN NULL;  -- For AFTER triggers
 END;
 $$ LANGUAGE plpgsql;
 
+CREATE TRIGGER trigger_audit_roles
+AFTER INSERT OR UPDATE OR DELETE ON roles
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
+
 CREATE TRIGGER trigger_audit_role_permissions
 AFTER INSERT OR UPDATE OR DELETE ON role_permissions
-FOR EACH ROW EXECUTE FUNCTION audit_rbac_changes();
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trigger_audit_users
+AFTER INSERT OR UPDATE OR DELETE ON users
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trigger_audit_user_roles
+AFTER INSERT OR UPDATE OR DELETE ON user_roles
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trigger_audit_supply_stations
+AFTER INSERT OR UPDATE OR DELETE ON supply_stations
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trigger_audit_supply_needs
+AFTER INSERT OR UPDATE OR DELETE ON supply_needs
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trigger_audit_donations
+AFTER INSERT OR UPDATE OR DELETE ON donations
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trigger_audit_checkins
+AFTER INSERT OR UPDATE OR DELETE ON checkins
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trigger_audit_news
+AFTER INSERT OR UPDATE OR DELETE ON news
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
+
+CREATE TRIGGER trigger_audit_alerts
+AFTER INSERT OR UPDATE OR DELETE ON alerts
+FOR EACH ROW EXECUTE FUNCTION audit_changes();
 
 -- Indexes for performance
 CREATE INDEX idx_supply_stations_location ON supply_stations USING GIST(location);
 CREATE INDEX idx_checkins_station_id ON checkins(station_id);
 CREATE INDEX idx_donations_station_id ON donations(station_id);
+CREATE INDEX idx_donation_status_history_donation_id ON donation_status_history(donation_id, changed_at);
 CREATE INDEX idx_supply_needs_station_id ON supply_needs(station_id);
 CREATE INDEX idx_news_source ON news(source);
+CREATE INDEX idx_news_search ON news USING GIN (news_search_vector(title, content));
+CREATE UNIQUE INDEX idx_news_url ON news(url) WHERE url IS NOT NULL;
+CREATE UNIQUE INDEX idx_news_content_hash ON news(content_hash) WHERE content_hash IS NOT NULL;
+-- Containment lookups on tags, e.g. relevant_to @> '{"districts": ["KC"]}'
+CREATE INDEX idx_news_relevant_to ON news USING GIN (relevant_to jsonb_path_ops);
+CREATE INDEX idx_alerts_area ON alerts USING GIST(area);
+CREATE INDEX idx_districts_boundary ON districts USING GIST(boundary);
+CREATE INDEX idx_alerts_in_force ON alerts(expires_at) WHERE superseded_at IS NULL;
+CREATE INDEX idx_alerts_source_url ON alerts(source_url);
+CREATE INDEX idx_alerts_news_id ON alerts(news_id);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
 CREATE INDEX idx_users_trust_points ON users(trust_points);
+CREATE INDEX idx_users_approval_status ON users(approval_status);
 CREATE INDEX idx_role_permissions_role_id ON role_permissions(role_id);
 CREATE INDEX idx_role_permissions_permission_id ON role_permissions(permission_id);
 CREATE INDEX idx_user_roles_user_id ON user_roles(user_id);
 CREATE INDEX idx_user_roles_role_id ON user_roles(role_id);
+CREATE INDEX idx_audit_logs_changed_at ON audit_logs(changed_at);
+CREATE INDEX idx_audit_logs_record ON audit_logs(table_name, record_id);
+CREATE INDEX idx_audit_logs_changed_by ON audit_logs(changed_by);
+CREATE INDEX idx_audit_logs_request_id ON audit_logs(request_id);
 
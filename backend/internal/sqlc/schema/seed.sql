@@ -31,7 +31,9 @@ INSERT INTO permissions (name, description) VALUES
     ('create_supply_needs', 'Add supply needs to stations'),
     ('read_supply_needs', 'View supply needs'),
     ('update_supply_needs', 'Update supply needs'),
-    ('delete_supply_needs', 'Delete supply needs');
+    ('delete_supply_needs', 'Delete supply needs'),
+    ('manage_api_keys', 'Create, list and revoke service account API keys'),
+    ('read_audit_logs', 'Query the audit trail');
 
 -- Example assignments: Assign permissions to roles
 -- For admin: all permissions
@@ -0,0 +1,110 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// geoJSONBatchSize is the number of stations fetched per round trip while streaming an export.
+const geoJSONBatchSize = 500
+
+// BoundingBox restricts a GeoJSON export to stations inside a WGS84 envelope.
+type BoundingBox struct {
+	MinLng float64
+	MinLat float64
+	MaxLng float64
+	MaxLat float64
+}
+
+// Feature is a single GeoJSON Point feature describing a station.
+type Feature struct {
+	Type       string            `json:"type"`
+	ID         int32             `json:"id"`
+	Geometry   PointGeometry     `json:"geometry"`
+	Properties FeatureProperties `json:"properties"`
+}
+
+// PointGeometry is a GeoJSON Point; coordinates are [longitude, latitude].
+type PointGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// FeatureProperties holds the station attributes exported alongside its geometry.
+// Needs is the JSON array aggregated by the database from supply_needs.
+type FeatureProperties struct {
+	RegisteredBy          pgtype.Int4        `json:"registered_by"`
+	IsVerified            bool               `json:"is_verified"`
+	VerificationCount     int32              `json:"verification_count"`
+	VerificationThreshold int32              `json:"verification_threshold"`
+	Needs                 json.RawMessage    `json:"needs"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `json:"updated_at"`
+}
+
+// StreamStationFeatures walks all stations (optionally inside bbox) in id order and
+// hands them to emit in batches, so exports never hold the full table in memory.
+// Iteration stops at the first error returned by the database or by emit.
+func (s *Service) StreamStationFeatures(ctx context.Context, bbox *BoundingBox, emit func([]Feature) error) error {
+	params := db.ListStationFeaturesParams{BatchSize: geoJSONBatchSize}
+	if bbox != nil {
+		params.UseBbox = true
+		params.MinLng = bbox.MinLng
+		params.MinLat = bbox.MinLat
+		params.MaxLng = bbox.MaxLng
+		params.MaxLat = bbox.MaxLat
+	}
+
+	for {
+		rows, err := s.queries.ListStationFeatures(ctx, params)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		features := make([]Feature, 0, len(rows))
+		for _, row := range rows {
+			features = append(features, toFeature(row))
+		}
+		if err := emit(features); err != nil {
+			return err
+		}
+
+		if len(rows) < geoJSONBatchSize {
+			return nil
+		}
+		params.AfterID = rows[len(rows)-1].ID
+	}
+}
+
+// toFeature converts an export row into a GeoJSON feature.
+func toFeature(row db.ListStationFeaturesRow) Feature {
+	needs := json.RawMessage(row.Needs)
+	if len(needs) == 0 {
+		needs = json.RawMessage("[]")
+	}
+
+	return Feature{
+		Type: "Feature",
+		ID:   row.ID,
+		Geometry: PointGeometry{
+			Type:        "Point",
+			Coordinates: [2]float64{row.Longitude, row.Latitude},
+		},
+		Properties: FeatureProperties{
+			RegisteredBy:          row.RegisteredBy,
+			IsVerified:            row.IsVerified.Bool,
+			VerificationCount:     row.VerificationCount.Int32,
+			VerificationThreshold: row.VerificationThreshold,
+			Needs:                 needs,
+			CreatedAt:             row.CreatedAt,
+			UpdatedAt:             row.UpdatedAt,
+		},
+	}
+}
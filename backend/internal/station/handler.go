@@ -0,0 +1,531 @@
+package station
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+
+	mvtContentType = "application/vnd.mapbox-vector-tile"
+)
+
+// Handler handles supply station HTTP requests.
+type Handler struct {
+	stationService ServiceInterface
+}
+
+// NewHandler creates a new station Handler instance.
+func NewHandler(stationService ServiceInterface) HandlerInterface {
+	return &Handler{
+		stationService: stationService,
+	}
+}
+
+// createStationRequest is the body accepted when registering a station.
+type createStationRequest struct {
+	Latitude  *float64 `json:"latitude" binding:"required,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" binding:"required,gte=-180,lte=180"`
+}
+
+// updateStationRequest is the body accepted when updating a station.
+type updateStationRequest struct {
+	Latitude              *float64 `json:"latitude" binding:"required,gte=-90,lte=90"`
+	Longitude             *float64 `json:"longitude" binding:"required,gte=-180,lte=180"`
+	VerificationThreshold *int32   `json:"verification_threshold" binding:"omitempty,gte=1"`
+}
+
+// supplyNeedRequest is the body accepted when creating or replacing a supply need.
+type supplyNeedRequest struct {
+	SupplyType     string  `json:"supply_type" binding:"required,max=255"`
+	QuantityNeeded *int32  `json:"quantity_needed" binding:"omitempty,gte=0"`
+	Description    *string `json:"description"`
+	UrgencyLevel   string  `json:"urgency_level" binding:"required"`
+}
+
+// CreateStation registers a new supply station for the authenticated user.
+// POST /api/v1/stations
+func (h *Handler) CreateStation(ctx *gin.Context) {
+	var req createStationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		response.Error(ctx, http.StatusUnauthorized, "Missing user in token")
+		return
+	}
+
+	station, err := h.stationService.CreateStation(ctx.Request.Context(), userID, *req.Latitude, *req.Longitude)
+	if err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to create station")
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, station)
+}
+
+// GetStation returns a single supply station.
+// GET /api/v1/stations/:id
+func (h *Handler) GetStation(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	station, err := h.stationService.GetStation(ctx.Request.Context(), id)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to get station")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, station)
+}
+
+// ListStations returns a page of supply stations.
+// GET /api/v1/stations?limit=&offset=&verified=
+func (h *Handler) ListStations(ctx *gin.Context) {
+	limit, offset, ok := response.ParsePagination(ctx, defaultPageLimit, maxPageLimit)
+	if !ok {
+		return
+	}
+
+	var verified *bool
+	if raw := ctx.Query("verified"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			response.Error(ctx, http.StatusBadRequest, "verified must be true or false")
+			return
+		}
+		verified = &v
+	}
+
+	stations, total, err := h.stationService.ListStations(ctx.Request.Context(), verified, limit, offset)
+	if err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to list stations")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"stations": stations,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// UpdateStation changes a station's location or verification threshold.
+// PUT /api/v1/stations/:id
+func (h *Handler) UpdateStation(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req updateStationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	station, err := h.stationService.UpdateStation(ctx.Request.Context(), id, UpdateStationInput{
+		Latitude:              *req.Latitude,
+		Longitude:             *req.Longitude,
+		VerificationThreshold: req.VerificationThreshold,
+	})
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to update station")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, station)
+}
+
+// DeleteStation removes a supply station.
+// DELETE /api/v1/stations/:id
+func (h *Handler) DeleteStation(ctx *gin.Context) {
+	id, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.stationService.DeleteStation(ctx.Request.Context(), id); err != nil {
+		writeServiceError(ctx, err, "Failed to delete station")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"message": "Station deleted successfully",
+	})
+}
+
+// FindNearbyStations returns stations near a point, sorted by distance, with
+// their needs and the official warnings in force at each.
+// GET /api/v1/stations/nearby?lat=&lng=&radius_m=&verified=&supply_type=&urgency=&limit=
+func (h *Handler) FindNearbyStations(ctx *gin.Context) {
+	lat, err := strconv.ParseFloat(ctx.Query("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		response.Error(ctx, http.StatusBadRequest, "lat is required and must be between -90 and 90")
+		return
+	}
+	lng, err := strconv.ParseFloat(ctx.Query("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		response.Error(ctx, http.StatusBadRequest, "lng is required and must be between -180 and 180")
+		return
+	}
+
+	radius := float64(DefaultNearbyRadiusMeters)
+	if raw := ctx.Query("radius_m"); raw != "" {
+		radius, err = strconv.ParseFloat(raw, 64)
+		if err != nil || radius <= 0 {
+			response.Error(ctx, http.StatusBadRequest, "radius_m must be a positive number")
+			return
+		}
+	}
+	if radius > MaxNearbyRadiusMeters {
+		radius = MaxNearbyRadiusMeters
+	}
+
+	limit := DefaultNearbyLimit
+	if raw := ctx.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			response.Error(ctx, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+	}
+	if limit > MaxNearbyLimit {
+		limit = MaxNearbyLimit
+	}
+
+	var verifiedOnly bool
+	if raw := ctx.Query("verified"); raw != "" {
+		verifiedOnly, err = strconv.ParseBool(raw)
+		if err != nil {
+			response.Error(ctx, http.StatusBadRequest, "verified must be true or false")
+			return
+		}
+	}
+
+	var urgency db.UrgencyLevel
+	if raw := ctx.Query("urgency"); raw != "" {
+		urgency, err = ParseUrgency(raw)
+		if err != nil {
+			response.Error(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	query := NearbyQuery{
+		Latitude:     lat,
+		Longitude:    lng,
+		RadiusMeters: radius,
+		VerifiedOnly: verifiedOnly,
+		SupplyType:   strings.TrimSpace(ctx.Query("supply_type")),
+		Urgency:      urgency,
+		Limit:        int32(limit),
+	}
+
+	stations, err := h.stationService.FindNearbyStations(ctx.Request.Context(), query)
+	if err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to search nearby stations")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"stations": stations,
+		"radius_m": radius,
+		"limit":    limit,
+	})
+}
+
+// ExportGeoJSON streams all stations as a GeoJSON FeatureCollection.
+// GET /api/v1/stations.geojson?bbox=minLng,minLat,maxLng,maxLat
+func (h *Handler) ExportGeoJSON(ctx *gin.Context) {
+	var bbox *BoundingBox
+	if raw := ctx.Query("bbox"); raw != "" {
+		parsed, ok := parseBoundingBox(raw)
+		if !ok {
+			response.Error(ctx, http.StatusBadRequest, "bbox must be minLng,minLat,maxLng,maxLat within WGS84 bounds")
+			return
+		}
+		bbox = parsed
+	}
+
+	// Headers are only committed once the first batch arrives, so a failure
+	// before any data is written can still be reported as a normal error.
+	started := false
+	err := h.stationService.StreamStationFeatures(ctx.Request.Context(), bbox, func(features []Feature) error {
+		for _, feature := range features {
+			encoded, err := json.Marshal(feature)
+			if err != nil {
+				return err
+			}
+			if !started {
+				ctx.Header("Content-Type", "application/geo+json")
+				ctx.Status(http.StatusOK)
+				if _, err := ctx.Writer.WriteString(`{"type":"FeatureCollection","features":[`); err != nil {
+					return err
+				}
+				started = true
+			} else if _, err := ctx.Writer.WriteString(","); err != nil {
+				return err
+			}
+			if _, err := ctx.Writer.Write(encoded); err != nil {
+				return err
+			}
+		}
+		ctx.Writer.Flush()
+		return nil
+	})
+
+	if err != nil {
+		if !started {
+			response.Error(ctx, http.StatusInternalServerError, "Failed to export stations")
+			return
+		}
+		// The status line is already sent; leave the document unterminated so
+		// clients fail to parse it rather than silently accept a partial export.
+		log.Printf("GeoJSON export aborted: %v", err)
+		return
+	}
+
+	if !started {
+		ctx.Header("Content-Type", "application/geo+json")
+		ctx.Status(http.StatusOK)
+		_, _ = ctx.Writer.WriteString(`{"type":"FeatureCollection","features":[`)
+	}
+	_, _ = ctx.Writer.WriteString("]}")
+}
+
+// GetStationTile returns a Mapbox Vector Tile of stations.
+// GET /api/v1/tiles/stations/:z/:x/:y.mvt
+func (h *Handler) GetStationTile(ctx *gin.Context) {
+	z, errZ := strconv.ParseInt(ctx.Param("z"), 10, 32)
+	x, errX := strconv.ParseInt(ctx.Param("x"), 10, 32)
+	rawY, hasExt := strings.CutSuffix(ctx.Param("tile"), ".mvt")
+	y, errY := strconv.ParseInt(rawY, 10, 32)
+	if errZ != nil || errX != nil || errY != nil || !hasExt {
+		response.Error(ctx, http.StatusBadRequest, "Tile path must be /:z/:x/:y.mvt")
+		return
+	}
+
+	tile, err := h.stationService.GetStationTile(ctx.Request.Context(), int32(z), int32(x), int32(y))
+	if err != nil {
+		if errors.Is(err, ErrInvalidTile) {
+			response.Error(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(ctx, http.StatusInternalServerError, "Failed to render tile")
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, max-age=60")
+	if len(tile) == 0 {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	ctx.Data(http.StatusOK, mvtContentType, tile)
+}
+
+// ListSupplyNeeds returns a station's supply needs, most urgent first.
+// GET /api/v1/stations/:id/needs
+func (h *Handler) ListSupplyNeeds(ctx *gin.Context) {
+	stationID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	needs, err := h.stationService.ListSupplyNeeds(ctx.Request.Context(), stationID)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to list supply needs")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"needs": needs,
+	})
+}
+
+// CreateSupplyNeed adds a supply need to a station.
+// POST /api/v1/stations/:id/needs
+func (h *Handler) CreateSupplyNeed(ctx *gin.Context) {
+	stationID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	input, ok := bindSupplyNeed(ctx)
+	if !ok {
+		return
+	}
+
+	need, err := h.stationService.CreateSupplyNeed(ctx.Request.Context(), stationID, input)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to create supply need")
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, need)
+}
+
+// UpdateSupplyNeed replaces a station's supply need.
+// PUT /api/v1/stations/:id/needs/:needId
+func (h *Handler) UpdateSupplyNeed(ctx *gin.Context) {
+	stationID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	needID, ok := parseIDParam(ctx, "needId")
+	if !ok {
+		return
+	}
+
+	input, ok := bindSupplyNeed(ctx)
+	if !ok {
+		return
+	}
+
+	need, err := h.stationService.UpdateSupplyNeed(ctx.Request.Context(), stationID, needID, input)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to update supply need")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, need)
+}
+
+// ConfirmSupplyNeed marks a supply need as still current.
+// POST /api/v1/stations/:id/needs/:needId/confirm
+func (h *Handler) ConfirmSupplyNeed(ctx *gin.Context) {
+	stationID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	needID, ok := parseIDParam(ctx, "needId")
+	if !ok {
+		return
+	}
+
+	need, err := h.stationService.ConfirmSupplyNeed(ctx.Request.Context(), stationID, needID)
+	if err != nil {
+		writeServiceError(ctx, err, "Failed to confirm supply need")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, need)
+}
+
+// DeleteSupplyNeed removes a station's supply need.
+// DELETE /api/v1/stations/:id/needs/:needId
+func (h *Handler) DeleteSupplyNeed(ctx *gin.Context) {
+	stationID, ok := parseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	needID, ok := parseIDParam(ctx, "needId")
+	if !ok {
+		return
+	}
+
+	if err := h.stationService.DeleteSupplyNeed(ctx.Request.Context(), stationID, needID); err != nil {
+		writeServiceError(ctx, err, "Failed to delete supply need")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"message": "Supply need deleted successfully",
+	})
+}
+
+// bindSupplyNeed parses and validates a supply need body, writing a 400 response on failure.
+func bindSupplyNeed(ctx *gin.Context) (SupplyNeedInput, bool) {
+	var req supplyNeedRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return SupplyNeedInput{}, false
+	}
+
+	supplyType := strings.TrimSpace(req.SupplyType)
+	if supplyType == "" {
+		response.Error(ctx, http.StatusBadRequest, "supply_type must not be blank")
+		return SupplyNeedInput{}, false
+	}
+
+	urgency, err := ParseUrgency(req.UrgencyLevel)
+	if err != nil {
+		response.Error(ctx, http.StatusBadRequest, err.Error())
+		return SupplyNeedInput{}, false
+	}
+
+	return SupplyNeedInput{
+		SupplyType:     supplyType,
+		QuantityNeeded: req.QuantityNeeded,
+		Description:    req.Description,
+		Urgency:        urgency,
+	}, true
+}
+
+// writeServiceError maps station service errors to HTTP responses.
+func writeServiceError(ctx *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrStationNotFound), errors.Is(err, ErrNeedNotFound):
+		response.Error(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrDuplicateNeed):
+		response.Error(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidUrgency):
+		response.Error(ctx, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(ctx, http.StatusInternalServerError, fallback)
+	}
+}
+
+// parseIDParam parses a positive int32 path parameter, writing a 400 response on failure.
+func parseIDParam(ctx *gin.Context, name string) (int32, bool) {
+	id, err := strconv.ParseInt(ctx.Param(name), 10, 32)
+	if err != nil || id <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid "+name)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// parseBoundingBox parses a "minLng,minLat,maxLng,maxLat" query value.
+func parseBoundingBox(raw string) (*BoundingBox, bool) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return nil, false
+	}
+
+	values := make([]float64, 4)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = v
+	}
+
+	bbox := &BoundingBox{MinLng: values[0], MinLat: values[1], MaxLng: values[2], MaxLat: values[3]}
+	if bbox.MinLng < -180 || bbox.MaxLng > 180 || bbox.MinLat < -90 || bbox.MaxLat > 90 {
+		return nil, false
+	}
+	if bbox.MinLng >= bbox.MaxLng || bbox.MinLat >= bbox.MaxLat {
+		return nil, false
+	}
+	return bbox, true
+}
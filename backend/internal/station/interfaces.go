@@ -0,0 +1,42 @@
+package station
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceInterface defines the interface for station services
+type ServiceInterface interface {
+	CreateStation(ctx context.Context, registeredBy int32, lat, lng float64) (*Station, error)
+	GetStation(ctx context.Context, id int32) (*Station, error)
+	ListStations(ctx context.Context, verified *bool, limit, offset int32) ([]Station, int64, error)
+	UpdateStation(ctx context.Context, id int32, input UpdateStationInput) (*Station, error)
+	DeleteStation(ctx context.Context, id int32) error
+	FindNearbyStations(ctx context.Context, query NearbyQuery) ([]NearbyStation, error)
+	StreamStationFeatures(ctx context.Context, bbox *BoundingBox, emit func([]Feature) error) error
+	GetStationTile(ctx context.Context, z, x, y int32) ([]byte, error)
+	InvalidateTiles(ctx context.Context) error
+	ListSupplyNeeds(ctx context.Context, stationID int32) ([]SupplyNeed, error)
+	CreateSupplyNeed(ctx context.Context, stationID int32, input SupplyNeedInput) (*SupplyNeed, error)
+	UpdateSupplyNeed(ctx context.Context, stationID, needID int32, input SupplyNeedInput) (*SupplyNeed, error)
+	ConfirmSupplyNeed(ctx context.Context, stationID, needID int32) (*SupplyNeed, error)
+	DeleteSupplyNeed(ctx context.Context, stationID, needID int32) error
+}
+
+// HandlerInterface defines the interface for station HTTP handlers
+type HandlerInterface interface {
+	CreateStation(ctx *gin.Context)
+	GetStation(ctx *gin.Context)
+	ListStations(ctx *gin.Context)
+	UpdateStation(ctx *gin.Context)
+	DeleteStation(ctx *gin.Context)
+	FindNearbyStations(ctx *gin.Context)
+	ExportGeoJSON(ctx *gin.Context)
+	GetStationTile(ctx *gin.Context)
+	ListSupplyNeeds(ctx *gin.Context)
+	CreateSupplyNeed(ctx *gin.Context)
+	UpdateSupplyNeed(ctx *gin.Context)
+	ConfirmSupplyNeed(ctx *gin.Context)
+	DeleteSupplyNeed(ctx *gin.Context)
+}
@@ -0,0 +1,187 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/pgerr"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+var (
+	ErrNeedNotFound   = errors.New("supply need not found")
+	ErrDuplicateNeed  = errors.New("station already has a need for this supply type")
+	ErrInvalidUrgency = errors.New("urgency must be one of critical, high, medium, low")
+)
+
+// SupplyNeed is a station's supply need with its staleness flag.
+// A need is stale when nobody has confirmed it within the configured window.
+type SupplyNeed struct {
+	db.SupplyNeed
+	Stale bool `json:"stale"`
+}
+
+// SupplyNeedInput holds the fields of a need that clients can set.
+type SupplyNeedInput struct {
+	SupplyType     string
+	QuantityNeeded *int32
+	Description    *string
+	Urgency        db.UrgencyLevel
+}
+
+// ParseUrgency validates a client-supplied urgency level (case-insensitive).
+func ParseUrgency(raw string) (db.UrgencyLevel, error) {
+	switch level := db.UrgencyLevel(strings.ToLower(strings.TrimSpace(raw))); level {
+	case db.UrgencyLevelCritical, db.UrgencyLevelHigh, db.UrgencyLevelMedium, db.UrgencyLevelLow:
+		return level, nil
+	default:
+		return "", ErrInvalidUrgency
+	}
+}
+
+// ListSupplyNeeds returns a station's needs, most urgent first.
+func (s *Service) ListSupplyNeeds(ctx context.Context, stationID int32) ([]SupplyNeed, error) {
+	if _, err := s.GetStation(ctx, stationID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.ListSupplyNeedsByStation(ctx, pgtype.Int4{Int32: stationID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	needs := make([]SupplyNeed, 0, len(rows))
+	for _, row := range rows {
+		needs = append(needs, s.toSupplyNeed(row, now))
+	}
+	return needs, nil
+}
+
+// CreateSupplyNeed adds a need to a station. Each supply type may appear once per station.
+func (s *Service) CreateSupplyNeed(ctx context.Context, stationID int32, input SupplyNeedInput) (*SupplyNeed, error) {
+	if _, err := s.GetStation(ctx, stationID); err != nil {
+		return nil, err
+	}
+
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.SupplyNeed, error) {
+		return q.CreateSupplyNeed(ctx, db.CreateSupplyNeedParams{
+			StationID:      pgtype.Int4{Int32: stationID, Valid: true},
+			SupplyType:     input.SupplyType,
+			QuantityNeeded: optionalInt4(input.QuantityNeeded),
+			Description:    optionalText(input.Description),
+			UrgencyLevel:   input.Urgency,
+		})
+	})
+	if err != nil {
+		if pgerr.IsUniqueViolation(err) {
+			return nil, ErrDuplicateNeed
+		}
+		return nil, err
+	}
+	s.invalidateTilesAfterWrite(ctx)
+
+	need := s.toSupplyNeed(row, time.Now())
+	return &need, nil
+}
+
+// UpdateSupplyNeed replaces a need's fields. Updating also confirms the need.
+func (s *Service) UpdateSupplyNeed(ctx context.Context, stationID, needID int32, input SupplyNeedInput) (*SupplyNeed, error) {
+	if err := s.checkNeedBelongsToStation(ctx, stationID, needID); err != nil {
+		return nil, err
+	}
+
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.SupplyNeed, error) {
+		return q.UpdateSupplyNeed(ctx, db.UpdateSupplyNeedParams{
+			ID:             needID,
+			SupplyType:     input.SupplyType,
+			QuantityNeeded: optionalInt4(input.QuantityNeeded),
+			Description:    optionalText(input.Description),
+			UrgencyLevel:   input.Urgency,
+		})
+	})
+	if err != nil {
+		if pgerr.IsUniqueViolation(err) {
+			return nil, ErrDuplicateNeed
+		}
+		return nil, err
+	}
+	s.invalidateTilesAfterWrite(ctx)
+
+	need := s.toSupplyNeed(row, time.Now())
+	return &need, nil
+}
+
+// ConfirmSupplyNeed records that a need is still current, clearing its stale flag.
+func (s *Service) ConfirmSupplyNeed(ctx context.Context, stationID, needID int32) (*SupplyNeed, error) {
+	if err := s.checkNeedBelongsToStation(ctx, stationID, needID); err != nil {
+		return nil, err
+	}
+
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.SupplyNeed, error) {
+		return q.ConfirmSupplyNeed(ctx, needID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	need := s.toSupplyNeed(row, time.Now())
+	return &need, nil
+}
+
+// DeleteSupplyNeed removes a need from a station.
+func (s *Service) DeleteSupplyNeed(ctx context.Context, stationID, needID int32) error {
+	if err := s.checkNeedBelongsToStation(ctx, stationID, needID); err != nil {
+		return err
+	}
+
+	if err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		return q.DeleteSupplyNeed(ctx, needID)
+	}); err != nil {
+		return err
+	}
+	s.invalidateTilesAfterWrite(ctx)
+	return nil
+}
+
+// checkNeedBelongsToStation returns ErrNeedNotFound unless the need exists on the station.
+func (s *Service) checkNeedBelongsToStation(ctx context.Context, stationID, needID int32) error {
+	need, err := s.queries.GetSupplyNeedByID(ctx, needID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNeedNotFound
+		}
+		return err
+	}
+	if !need.StationID.Valid || need.StationID.Int32 != stationID {
+		return ErrNeedNotFound
+	}
+	return nil
+}
+
+// toSupplyNeed wraps a need row, flagging it stale if it has not been confirmed recently.
+func (s *Service) toSupplyNeed(row db.SupplyNeed, now time.Time) SupplyNeed {
+	stale := s.needStaleAfter > 0 && row.LastConfirmedAt.Valid &&
+		now.Sub(row.LastConfirmedAt.Time) > s.needStaleAfter
+	return SupplyNeed{SupplyNeed: row, Stale: stale}
+}
+
+func optionalInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func optionalText(v *string) pgtype.Text {
+	if v == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *v, Valid: true}
+}
@@ -0,0 +1,47 @@
+package station
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"hkers-backend/internal/core/response"
+	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+// RegisterStationRoutes registers supply station routes on the given router.
+func RegisterStationRoutes(router *gin.Engine, stationSvc ServiceInterface, jwtManager response.JWTManager) {
+	h := NewHandler(stationSvc)
+
+	// Station routes - require JWT authentication
+	stations := router.Group("/api/v1/stations")
+	stations.Use(middleware.JWTAuth(jwtManager))
+	{
+		stations.GET("", middleware.RequirePermission(db.AppPermissionReadStations), h.ListStations)
+		stations.POST("", middleware.RequirePermission(db.AppPermissionCreateStations), h.CreateStation)
+		stations.GET("/nearby", middleware.RequirePermission(db.AppPermissionReadStations, db.AppPermissionReadSupplyNeeds), h.FindNearbyStations)
+		stations.GET("/:id", middleware.RequirePermission(db.AppPermissionReadStations), h.GetStation)
+		stations.PUT("/:id", middleware.RequirePermission(db.AppPermissionUpdateStations), h.UpdateStation)
+		stations.DELETE("/:id", middleware.RequirePermission(db.AppPermissionDeleteStations), h.DeleteStation)
+
+		// Supply needs nested under their station
+		stations.GET("/:id/needs", middleware.RequirePermission(db.AppPermissionReadSupplyNeeds), h.ListSupplyNeeds)
+		stations.POST("/:id/needs", middleware.RequirePermission(db.AppPermissionCreateSupplyNeeds), h.CreateSupplyNeed)
+		stations.PUT("/:id/needs/:needId", middleware.RequirePermission(db.AppPermissionUpdateSupplyNeeds), h.UpdateSupplyNeed)
+		stations.POST("/:id/needs/:needId/confirm", middleware.RequirePermission(db.AppPermissionUpdateSupplyNeeds), h.ConfirmSupplyNeed)
+		stations.DELETE("/:id/needs/:needId", middleware.RequirePermission(db.AppPermissionDeleteSupplyNeeds), h.DeleteSupplyNeed)
+	}
+
+	// GeoJSON export lives beside the group because its path is not nested under /stations/
+	export := router.Group("/api/v1")
+	export.Use(middleware.JWTAuth(jwtManager))
+	{
+		export.GET("/stations.geojson", middleware.RequirePermission(db.AppPermissionReadStations, db.AppPermissionReadSupplyNeeds), h.ExportGeoJSON)
+	}
+
+	// Vector tile routes - the last segment is "{y}.mvt"
+	tiles := router.Group("/api/v1/tiles")
+	tiles.Use(middleware.JWTAuth(jwtManager))
+	{
+		tiles.GET("/stations/:z/:x/:tile", middleware.RequirePermission(db.AppPermissionReadStations, db.AppPermissionReadSupplyNeeds), h.GetStationTile)
+	}
+}
@@ -0,0 +1,419 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"hkers-backend/internal/config"
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/geo"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+const (
+	// DefaultNearbyRadiusMeters is used when a nearby search omits a radius.
+	DefaultNearbyRadiusMeters = 5000
+	// MaxNearbyRadiusMeters caps nearby searches to protect the database.
+	MaxNearbyRadiusMeters = 50000
+	// DefaultNearbyLimit is used when a nearby search omits a limit.
+	DefaultNearbyLimit = 50
+	// MaxNearbyLimit caps the number of stations returned by a nearby search.
+	MaxNearbyLimit = 200
+
+	// baseVerificationThreshold is the number of check-ins required to verify a
+	// station registered by a user with no trust points.
+	baseVerificationThreshold = 5
+	// minVerificationThreshold is the lowest threshold any station can have.
+	minVerificationThreshold = 1
+)
+
+var (
+	ErrStationNotFound = errors.New("station not found")
+	ErrInvalidLocation = errors.New("station location could not be decoded")
+)
+
+// Station is the API representation of a supply station with its location decoded.
+type Station struct {
+	ID                    int32              `json:"id"`
+	RegisteredBy          pgtype.Int4        `json:"registered_by"`
+	Latitude              float64            `json:"latitude"`
+	Longitude             float64            `json:"longitude"`
+	VerificationCount     pgtype.Int4        `json:"verification_count"`
+	VerificationThreshold int32              `json:"verification_threshold"`
+	IsVerified            pgtype.Bool        `json:"is_verified"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `json:"updated_at"`
+}
+
+// UpdateStationInput holds the fields that can be changed on an existing station.
+// A nil VerificationThreshold keeps the current value.
+type UpdateStationInput struct {
+	Latitude              float64
+	Longitude             float64
+	VerificationThreshold *int32
+}
+
+// NearbyQuery describes a proximity search for stations.
+// Empty SupplyType/Urgency values disable the corresponding need filter.
+type NearbyQuery struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+	VerifiedOnly bool
+	SupplyType   string
+	Urgency      db.UrgencyLevel
+	Limit        int32
+}
+
+// NearbyStation is a station returned by a proximity search, with its needs
+// and the official warnings covering it embedded.
+type NearbyStation struct {
+	Station
+	DistanceMeters float64      `json:"distance_meters"`
+	SupplyNeeds    []SupplyNeed `json:"supply_needs"`
+	Warnings       []Warning    `json:"warnings"`
+}
+
+// Warning is an official alert in force at a station, worst first. The full
+// alert is available from /api/v1/alerts/:id.
+type Warning struct {
+	AlertID     int32              `json:"alert_id"`
+	Event       string             `json:"event"`
+	Headline    pgtype.Text        `json:"headline"`
+	Severity    db.AlertSeverity   `json:"severity"`
+	Urgency     db.AlertUrgency    `json:"urgency"`
+	Certainty   db.AlertCertainty  `json:"certainty"`
+	EffectiveAt pgtype.Timestamptz `json:"effective_at"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+}
+
+// Service handles supply station business logic.
+type Service struct {
+	queries        *db.Queries
+	runner         *dbtx.Runner
+	redis          *redis.Client
+	tileCacheTTL   time.Duration
+	needStaleAfter time.Duration
+}
+
+// NewService creates a new station service instance.
+// Vector tiles are cached in Redis for cfg.TileCacheTTL; a nil client or a
+// non-positive TTL disables caching.
+func NewService(pool *pgxpool.Pool, redisClient *redis.Client, cfg *config.StationConfig) *Service {
+	return &Service{
+		queries:        db.New(pool),
+		runner:         dbtx.New(pool),
+		redis:          redisClient,
+		tileCacheTTL:   cfg.TileCacheTTL,
+		needStaleAfter: cfg.NeedStaleAfter,
+	}
+}
+
+// CreateStation registers a new station at the given coordinates.
+// The verification threshold is derived from the registrant's trust points.
+func (s *Service) CreateStation(ctx context.Context, registeredBy int32, lat, lng float64) (*Station, error) {
+	threshold := int32(baseVerificationThreshold)
+	if registrant, err := s.queries.GetUserByID(ctx, registeredBy); err == nil {
+		threshold -= registrant.TrustPoints.Int32
+	}
+	if threshold < minVerificationThreshold {
+		threshold = minVerificationThreshold
+	}
+
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.SupplyStation, error) {
+		return q.CreateStation(ctx, db.CreateStationParams{
+			RegisteredBy:          pgtype.Int4{Int32: registeredBy, Valid: registeredBy > 0},
+			StMakepoint:           lng,
+			StMakepoint_2:         lat,
+			VerificationThreshold: threshold,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateTilesAfterWrite(ctx)
+	return toStation(row)
+}
+
+// GetStation retrieves a station by its ID.
+func (s *Service) GetStation(ctx context.Context, id int32) (*Station, error) {
+	row, err := s.queries.GetStationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStationNotFound
+		}
+		return nil, err
+	}
+	return toStation(row)
+}
+
+// ListStations returns a page of stations, optionally filtered by verification state,
+// together with the total number of stations matching the filter.
+func (s *Service) ListStations(ctx context.Context, verified *bool, limit, offset int32) ([]Station, int64, error) {
+	var (
+		rows  []db.SupplyStation
+		total int64
+		err   error
+	)
+
+	switch {
+	case verified == nil:
+		rows, err = s.queries.ListStations(ctx, db.ListStationsParams{Limit: limit, Offset: offset})
+		if err == nil {
+			total, err = s.queries.CountStations(ctx)
+		}
+	case *verified:
+		rows, err = s.queries.ListVerifiedStations(ctx, db.ListVerifiedStationsParams{Limit: limit, Offset: offset})
+		if err == nil {
+			total, err = s.queries.CountVerifiedStations(ctx)
+		}
+	default:
+		rows, err = s.queries.ListUnverifiedStations(ctx, db.ListUnverifiedStationsParams{Limit: limit, Offset: offset})
+		if err == nil {
+			var all, verifiedCount int64
+			if all, err = s.queries.CountStations(ctx); err == nil {
+				verifiedCount, err = s.queries.CountVerifiedStations(ctx)
+				total = all - verifiedCount
+			}
+		}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stations := make([]Station, 0, len(rows))
+	for _, row := range rows {
+		station, convErr := toStation(row)
+		if convErr != nil {
+			return nil, 0, convErr
+		}
+		stations = append(stations, *station)
+	}
+	return stations, total, nil
+}
+
+// UpdateStation changes a station's location and, optionally, its verification threshold.
+func (s *Service) UpdateStation(ctx context.Context, id int32, input UpdateStationInput) (*Station, error) {
+	current, err := s.queries.GetStationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStationNotFound
+		}
+		return nil, err
+	}
+
+	threshold := current.VerificationThreshold
+	if input.VerificationThreshold != nil {
+		threshold = *input.VerificationThreshold
+	}
+
+	row, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.SupplyStation, error) {
+		return q.UpdateStation(ctx, db.UpdateStationParams{
+			ID:                    id,
+			StMakepoint:           input.Longitude,
+			StMakepoint_2:         input.Latitude,
+			VerificationThreshold: threshold,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateTilesAfterWrite(ctx)
+	return toStation(row)
+}
+
+// DeleteStation removes a station and, via cascade, its needs, donations and check-ins.
+func (s *Service) DeleteStation(ctx context.Context, id int32) error {
+	if _, err := s.queries.GetStationByID(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrStationNotFound
+		}
+		return err
+	}
+	if err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		return q.DeleteStation(ctx, id)
+	}); err != nil {
+		return err
+	}
+	s.invalidateTilesAfterWrite(ctx)
+	return nil
+}
+
+// FindNearbyStations returns stations within the query radius sorted by distance,
+// each with its supply needs embedded. Radius and limit are clamped to sane bounds.
+func (s *Service) FindNearbyStations(ctx context.Context, query NearbyQuery) ([]NearbyStation, error) {
+	radius := query.RadiusMeters
+	if radius <= 0 {
+		radius = DefaultNearbyRadiusMeters
+	}
+	if radius > MaxNearbyRadiusMeters {
+		radius = MaxNearbyRadiusMeters
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultNearbyLimit
+	}
+	if limit > MaxNearbyLimit {
+		limit = MaxNearbyLimit
+	}
+
+	var (
+		rows []db.FindNearbyStationsRow
+		err  error
+	)
+	switch {
+	case query.SupplyType != "" || query.Urgency != "":
+		var filtered []db.FindNearbyStationsWithNeedRow
+		filtered, err = s.queries.FindNearbyStationsWithNeed(ctx, db.FindNearbyStationsWithNeedParams{
+			Lng:          query.Longitude,
+			Lat:          query.Latitude,
+			RadiusMeters: radius,
+			VerifiedOnly: query.VerifiedOnly,
+			SupplyType:   pgtype.Text{String: query.SupplyType, Valid: query.SupplyType != ""},
+			UrgencyLevel: db.NullUrgencyLevel{UrgencyLevel: query.Urgency, Valid: query.Urgency != ""},
+			MaxResults:   limit,
+		})
+		for _, row := range filtered {
+			rows = append(rows, db.FindNearbyStationsRow(row))
+		}
+	case query.VerifiedOnly:
+		var verified []db.FindNearbyVerifiedStationsRow
+		verified, err = s.queries.FindNearbyVerifiedStations(ctx, db.FindNearbyVerifiedStationsParams{
+			StMakepoint:   query.Longitude,
+			StMakepoint_2: query.Latitude,
+			StDwithin:     radius,
+			Limit:         limit,
+		})
+		for _, row := range verified {
+			rows = append(rows, db.FindNearbyStationsRow(row))
+		}
+	default:
+		rows, err = s.queries.FindNearbyStations(ctx, db.FindNearbyStationsParams{
+			StMakepoint:   query.Longitude,
+			StMakepoint_2: query.Latitude,
+			StDwithin:     radius,
+			Limit:         limit,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stationIDs := make([]int32, 0, len(rows))
+	for _, row := range rows {
+		stationIDs = append(stationIDs, row.ID)
+	}
+	needsByStation, err := s.needsForStations(ctx, stationIDs)
+	if err != nil {
+		return nil, err
+	}
+	warningsByStation, err := s.warningsForStations(ctx, stationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]NearbyStation, 0, len(rows))
+	for _, row := range rows {
+		station, convErr := toStation(db.SupplyStation{
+			ID:                    row.ID,
+			RegisteredBy:          row.RegisteredBy,
+			Location:              row.Location,
+			VerificationCount:     row.VerificationCount,
+			VerificationThreshold: row.VerificationThreshold,
+			IsVerified:            row.IsVerified,
+			CreatedAt:             row.CreatedAt,
+			UpdatedAt:             row.UpdatedAt,
+		})
+		if convErr != nil {
+			return nil, convErr
+		}
+
+		distance, _ := row.DistanceMeters.(float64)
+		needs := needsByStation[row.ID]
+		if needs == nil {
+			needs = []SupplyNeed{}
+		}
+		warnings := warningsByStation[row.ID]
+		if warnings == nil {
+			warnings = []Warning{}
+		}
+
+		results = append(results, NearbyStation{
+			Station:        *station,
+			DistanceMeters: distance,
+			SupplyNeeds:    needs,
+			Warnings:       warnings,
+		})
+	}
+	return results, nil
+}
+
+// needsForStations loads the supply needs of several stations in one query.
+func (s *Service) needsForStations(ctx context.Context, stationIDs []int32) (map[int32][]SupplyNeed, error) {
+	needsByStation := make(map[int32][]SupplyNeed, len(stationIDs))
+	if len(stationIDs) == 0 {
+		return needsByStation, nil
+	}
+
+	needs, err := s.queries.ListSupplyNeedsByStations(ctx, stationIDs)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, need := range needs {
+		needsByStation[need.StationID.Int32] = append(needsByStation[need.StationID.Int32], s.toSupplyNeed(need, now))
+	}
+	return needsByStation, nil
+}
+
+// warningsForStations loads the alerts in force at several stations in one query.
+func (s *Service) warningsForStations(ctx context.Context, stationIDs []int32) (map[int32][]Warning, error) {
+	warningsByStation := make(map[int32][]Warning, len(stationIDs))
+	if len(stationIDs) == 0 {
+		return warningsByStation, nil
+	}
+
+	rows, err := s.queries.ListActiveAlertsForStations(ctx, stationIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		warningsByStation[row.StationID] = append(warningsByStation[row.StationID], Warning{
+			AlertID:     row.ID,
+			Event:       row.Event,
+			Headline:    row.Headline,
+			Severity:    row.Severity,
+			Urgency:     row.Urgency,
+			Certainty:   row.Certainty,
+			EffectiveAt: row.EffectiveAt,
+			ExpiresAt:   row.ExpiresAt,
+		})
+	}
+	return warningsByStation, nil
+}
+
+// toStation converts a sqlc row into the API representation, decoding its location.
+func toStation(row db.SupplyStation) (*Station, error) {
+	point, err := geo.DecodePoint(row.Location)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidLocation, err)
+	}
+
+	return &Station{
+		ID:                    row.ID,
+		RegisteredBy:          row.RegisteredBy,
+		Latitude:              point.Latitude,
+		Longitude:             point.Longitude,
+		VerificationCount:     row.VerificationCount,
+		VerificationThreshold: row.VerificationThreshold,
+		IsVerified:            row.IsVerified,
+		CreatedAt:             row.CreatedAt,
+		UpdatedAt:             row.UpdatedAt,
+	}, nil
+}
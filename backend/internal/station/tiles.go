@@ -0,0 +1,96 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+const (
+	// MaxTileZoom is the deepest zoom level served by the tile endpoint.
+	MaxTileZoom = 22
+
+	// tileGenerationKey holds a counter that is bumped whenever station or need
+	// data changes. It is part of every tile cache key, so bumping it makes all
+	// previously cached tiles unreachable; they then expire through their TTL.
+	tileGenerationKey  = "tiles:stations:generation"
+	tileCacheKeyPrefix = "tiles:stations:"
+)
+
+var ErrInvalidTile = errors.New("tile coordinates out of range")
+
+// GetStationTile returns the Mapbox Vector Tile for z/x/y, served from Redis when cached.
+// An empty slice means the tile contains no stations.
+func (s *Service) GetStationTile(ctx context.Context, z, x, y int32) ([]byte, error) {
+	if z < 0 || z > MaxTileZoom {
+		return nil, ErrInvalidTile
+	}
+	maxIndex := int32(1) << z
+	if x < 0 || x >= maxIndex || y < 0 || y >= maxIndex {
+		return nil, ErrInvalidTile
+	}
+
+	key, cacheable := s.tileCacheKey(ctx, z, x, y)
+	if cacheable {
+		cached, err := s.redis.Get(ctx, key).Bytes()
+		if err == nil {
+			return cached, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("station: failed to read tile cache %s: %v", key, err)
+		}
+	}
+
+	tile, err := s.queries.GetStationTile(ctx, db.GetStationTileParams{Z: z, X: x, Y: y})
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		if err := s.redis.Set(ctx, key, tile, s.tileCacheTTL).Err(); err != nil {
+			log.Printf("station: failed to write tile cache %s: %v", key, err)
+		}
+	}
+	return tile, nil
+}
+
+// InvalidateTiles discards every cached station tile. It must be called after
+// any change to a station's location, verification state or supply needs.
+func (s *Service) InvalidateTiles(ctx context.Context) error {
+	if !s.tileCacheEnabled() {
+		return nil
+	}
+	return s.redis.Incr(ctx, tileGenerationKey).Err()
+}
+
+func (s *Service) tileCacheEnabled() bool {
+	return s.redis != nil && s.tileCacheTTL > 0
+}
+
+// tileCacheKey builds the cache key for a tile under the current generation.
+// Cache failures disable caching for the request instead of failing it.
+func (s *Service) tileCacheKey(ctx context.Context, z, x, y int32) (string, bool) {
+	if !s.tileCacheEnabled() {
+		return "", false
+	}
+
+	generation, err := s.redis.Get(ctx, tileGenerationKey).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("station: failed to read tile generation: %v", err)
+		return "", false
+	}
+	return fmt.Sprintf("%s%d:%d/%d/%d", tileCacheKeyPrefix, generation, z, x, y), true
+}
+
+// invalidateTilesAfterWrite bumps the tile generation after a successful write.
+// Failures are logged rather than returned because the write itself succeeded.
+func (s *Service) invalidateTilesAfterWrite(ctx context.Context) {
+	if err := s.InvalidateTiles(ctx); err != nil {
+		log.Printf("station: failed to invalidate tile cache: %v", err)
+	}
+}
@@ -1,42 +1,152 @@
 package user
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	"hkers-backend/internal/core/response"
 	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
 )
 
 // Handler handles user-related HTTP requests.
 type Handler struct {
-	// Add user service dependency here when needed
-	// userService *services.UserService
+	userService ServiceInterface
+	jwtManager  response.JWTManager
 }
 
 // NewHandler creates a new user Handler instance.
-func NewHandler() HandlerInterface {
-	return &Handler{}
+func NewHandler(userService ServiceInterface, jwtManager response.JWTManager) HandlerInterface {
+	return &Handler{
+		userService: userService,
+		jwtManager:  jwtManager,
+	}
+}
+
+// approvalDecisionRequest is the optional body for approve/reject requests.
+type approvalDecisionRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
 }
 
-// GetProfile returns the authenticated user's profile.
+// GetProfile returns the authenticated user's profile, including their current roles and permissions.
 // GET /user or GET /api/v1/me
-// Note: JWT middleware has already validated the token and extracted claims
+// Note: JWT middleware has already validated the token and loaded the live principal
 func (h *Handler) GetProfile(ctx *gin.Context) {
-	// Get user info from JWT claims (set by JWT middleware)
-	userID, _ := middleware.GetUserIDFromContext(ctx)
-	email, _ := middleware.GetEmailFromContext(ctx)
-	username, _ := middleware.GetUsernameFromContext(ctx)
-	oidcSub, _ := ctx.Get("oidc_sub")
-	isActive, _ := ctx.Get("is_active")
-
-	// Return user profile from JWT claims
+	principal, ok := middleware.GetPrincipalFromContext(ctx)
+	if !ok {
+		response.Error(ctx, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, principal)
+}
+
+// ListPendingUsers returns users awaiting admin approval.
+// GET /api/v1/admin/users/pending?limit=&offset=
+func (h *Handler) ListPendingUsers(ctx *gin.Context) {
+	limit, offset, ok := response.ParsePagination(ctx, defaultPageLimit, maxPageLimit)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.userService.ListPendingUsers(ctx.Request.Context(), limit, offset)
+	if err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to list pending users")
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, gin.H{
+		"users":  users,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// ApproveUser approves a pending user and activates their account.
+// POST /api/v1/admin/users/:id/approve
+func (h *Handler) ApproveUser(ctx *gin.Context) {
+	h.decide(ctx, h.userService.ApproveUser, "Failed to approve user")
+}
+
+// RejectUser rejects a pending user.
+// POST /api/v1/admin/users/:id/reject
+func (h *Handler) RejectUser(ctx *gin.Context) {
+	h.decide(ctx, h.userService.RejectUser, "Failed to reject user")
+}
+
+// RevokeUserTokens invalidates every access token issued to a user so far.
+// POST /api/v1/admin/users/:id/revoke-tokens
+func (h *Handler) RevokeUserTokens(ctx *gin.Context) {
+	userID, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || userID <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid id")
+		return
+	}
+
+	if _, err := h.userService.GetUserByID(ctx.Request.Context(), int32(userID)); err != nil {
+		response.Error(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := h.jwtManager.RevokeUserTokens(ctx.Request.Context(), int32(userID)); err != nil {
+		response.Error(ctx, http.StatusInternalServerError, "Failed to revoke tokens")
+		return
+	}
+
 	response.Success(ctx, http.StatusOK, gin.H{
-		"id":        userID,
-		"email":     email,
-		"username":  username,
-		"oidc_sub":  oidcSub,
-		"is_active": isActive,
+		"message": "All tokens for the user have been revoked",
 	})
 }
+
+// decide parses an approval decision request and applies it with the given service call.
+func (h *Handler) decide(
+	ctx *gin.Context,
+	apply func(ctx context.Context, userID, decidedBy int32, reason string) (*db.User, error),
+	failureMessage string,
+) {
+	userID, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || userID <= 0 {
+		response.Error(ctx, http.StatusBadRequest, "Invalid id")
+		return
+	}
+
+	// The body is optional; only a reason can be supplied
+	var req approvalDecisionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			response.Error(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	adminID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		response.Error(ctx, http.StatusUnauthorized, "Missing user in token")
+		return
+	}
+
+	updated, err := apply(ctx.Request.Context(), int32(userID), adminID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			response.Error(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, ErrUserNotPending):
+			response.Error(ctx, http.StatusConflict, err.Error())
+		default:
+			response.Error(ctx, http.StatusInternalServerError, failureMessage)
+		}
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, updated)
+}
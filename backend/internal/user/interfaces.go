@@ -12,9 +12,22 @@ import (
 type ServiceInterface interface {
 	ValidateOIDCLogin(ctx context.Context, oidcSub string) (*db.User, error)
 	GetOrCreateOIDCUser(ctx context.Context, oidcSub, nickname, email string) (*db.User, bool, error)
+	GetUserByID(ctx context.Context, id int32) (*db.User, error)
+	ListPendingUsers(ctx context.Context, limit, offset int32) ([]db.User, int64, error)
+	ApproveUser(ctx context.Context, userID, approvedBy int32, reason string) (*db.User, error)
+	RejectUser(ctx context.Context, userID, rejectedBy int32, reason string) (*db.User, error)
+}
+
+// PrincipalInvalidator drops cached user principals after a user's status changes
+type PrincipalInvalidator interface {
+	InvalidatePrincipal(ctx context.Context, userID int32) error
 }
 
 // HandlerInterface defines the interface for user HTTP handlers
 type HandlerInterface interface {
 	GetProfile(ctx *gin.Context)
+	ListPendingUsers(ctx *gin.Context)
+	ApproveUser(ctx *gin.Context)
+	RejectUser(ctx *gin.Context)
+	RevokeUserTokens(ctx *gin.Context)
 }
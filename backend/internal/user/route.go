@@ -5,11 +5,12 @@ import (
 
 	"hkers-backend/internal/core/response"
 	"hkers-backend/internal/middleware"
+	db "hkers-backend/internal/sqlc/generated"
 )
 
 // RegisterUserRoutes registers user routes on the given router.
-func RegisterUserRoutes(router *gin.Engine, jwtManager response.JWTManager) {
-	h := NewHandler()
+func RegisterUserRoutes(router *gin.Engine, userSvc ServiceInterface, jwtManager response.JWTManager) {
+	h := NewHandler(userSvc, jwtManager)
 
 	// API routes - require JWT authentication
 	api := router.Group("/api/v1")
@@ -17,4 +18,14 @@ func RegisterUserRoutes(router *gin.Engine, jwtManager response.JWTManager) {
 	{
 		api.GET("/me", h.GetProfile)
 	}
+
+	// Admin user-approval routes
+	admin := router.Group("/api/v1/admin/users")
+	admin.Use(middleware.JWTAuth(jwtManager))
+	{
+		admin.GET("/pending", middleware.RequirePermission(db.AppPermissionReadUsers), h.ListPendingUsers)
+		admin.POST("/:id/approve", middleware.RequirePermission(db.AppPermissionUpdateUsers), h.ApproveUser)
+		admin.POST("/:id/reject", middleware.RequirePermission(db.AppPermissionUpdateUsers), h.RejectUser)
+		admin.POST("/:id/revoke-tokens", middleware.RequirePermission(db.AppPermissionUpdateUsers), h.RevokeUserTokens)
+	}
 }
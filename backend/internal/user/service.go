@@ -2,11 +2,20 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"log"
+	"strconv"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"hkers-backend/internal/core/dbtx"
+	"hkers-backend/internal/core/pgerr"
 	db "hkers-backend/internal/sqlc/generated"
 )
 
@@ -14,17 +23,51 @@ var (
 	ErrUserNotFound   = errors.New("user not found")
 	ErrUserNotActive  = errors.New("user account is not active")
 	ErrUserNotAllowed = errors.New("user is not allowed to access this application")
+	ErrUserRejected   = errors.New("user registration was rejected")
+	ErrUserNotPending = errors.New("user is not awaiting approval")
+	ErrUsernameTaken  = errors.New("no unique username could be chosen")
+)
+
+// Unique constraints on users that new OIDC sign-ups can run into.
+const (
+	constraintUsersOIDCSub  = "users_oidc_sub_key"
+	constraintUsersUsername = "users_username_key"
+	constraintUsersEmail    = "users_email_key"
+)
+
+// usernameAttempts bounds how many suffixed usernames a new user is tried with.
+const usernameAttempts = 5
+
+// maxUsernameLength is the length of users.username.
+const maxUsernameLength = 255
+
+// Approval states stored in users.approval_status.
+const (
+	ApprovalStatusPending  = "pending"
+	ApprovalStatusApproved = "approved"
+	ApprovalStatusRejected = "rejected"
+)
+
+// Audit log actions recorded for approval decisions.
+const (
+	auditActionApprove = "APPROVE"
+	auditActionReject  = "REJECT"
 )
 
 // Service handles user-related business logic.
 type Service struct {
-	queries *db.Queries
+	queries    *db.Queries
+	runner     *dbtx.Runner
+	principals PrincipalInvalidator
 }
 
-// NewService creates a new user service instance.
-func NewService(pool *pgxpool.Pool) *Service {
+// NewService creates a new user service instance. Status changes invalidate
+// the user's cached principal through principals.
+func NewService(pool *pgxpool.Pool, principals PrincipalInvalidator) *Service {
 	return &Service{
-		queries: db.New(pool),
+		queries:    db.New(pool),
+		runner:     dbtx.New(pool),
+		principals: principals,
 	}
 }
 
@@ -36,6 +79,9 @@ func (s *Service) ValidateOIDCLogin(ctx context.Context, oidcSub string) (*db.Us
 		// Check if they exist but are inactive
 		existingUser, checkErr := s.queries.GetUserByOIDCSub(ctx, oidcSub)
 		if checkErr == nil && existingUser.ID > 0 {
+			if existingUser.ApprovalStatus == ApprovalStatusRejected {
+				return nil, ErrUserRejected
+			}
 			// User exists but is not active
 			return nil, ErrUserNotActive
 		}
@@ -47,6 +93,12 @@ func (s *Service) ValidateOIDCLogin(ctx context.Context, oidcSub string) (*db.Us
 
 // GetOrCreateOIDCUser gets an existing user by OIDC sub, or creates a new inactive user.
 // New users are created with is_active=false and require admin approval.
+// Usernames and emails are unique across all providers, so the same name or
+// address may already belong to an account at another provider. A taken
+// username is suffixed with a hash of the subject (then random characters),
+// and a taken email is left unset for an admin to sort out; ErrUsernameTaken
+// is returned if no free username is found. An empty username is derived from
+// the subject.
 func (s *Service) GetOrCreateOIDCUser(ctx context.Context, oidcSub, username, email string) (*db.User, bool, error) {
 	// First, try to get existing user
 	existingUser, err := s.queries.GetUserByOIDCSub(ctx, oidcSub)
@@ -55,17 +107,76 @@ func (s *Service) GetOrCreateOIDCUser(ctx context.Context, oidcSub, username, em
 		return &existingUser, false, nil
 	}
 
-	// User doesn't exist, create new inactive user
-	newUser, err := s.queries.CreateUserFromOIDC(ctx, db.CreateUserFromOIDCParams{
+	if username == "" {
+		username = "user-" + subjectHash(oidcSub)
+	}
+	params := db.CreateUserFromOIDCParams{
 		OidcSub:  oidcSub,
-		Username: username,
+		Username: truncateUsername(username, 0),
 		Email:    pgtype.Text{String: email, Valid: email != ""},
-	})
-	if err != nil {
-		return nil, false, err
 	}
 
-	return &newUser, true, nil // true = newly created
+	// User doesn't exist, create new inactive user
+	for attempt := 0; ; {
+		newUser, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.User, error) {
+			return q.CreateUserFromOIDC(ctx, params)
+		})
+		switch {
+		case err == nil:
+			return &newUser, true, nil // true = newly created
+		case pgerr.IsUniqueViolationOn(err, constraintUsersOIDCSub):
+			// A concurrent login created the user first
+			existingUser, getErr := s.queries.GetUserByOIDCSub(ctx, oidcSub)
+			if getErr != nil {
+				return nil, false, err
+			}
+			return &existingUser, false, nil
+		case pgerr.IsUniqueViolationOn(err, constraintUsersEmail) && params.Email.Valid:
+			log.Printf("user: email of new user %s is already registered, leaving it unset", oidcSub)
+			params.Email = pgtype.Text{}
+		case pgerr.IsUniqueViolationOn(err, constraintUsersUsername):
+			if attempt == usernameAttempts {
+				return nil, false, ErrUsernameTaken
+			}
+			suffix := subjectHash(oidcSub)
+			if attempt > 0 {
+				suffix = randomSuffix()
+			}
+			params.Username = truncateUsername(username, len(suffix)+1) + "-" + suffix
+			attempt++
+		default:
+			return nil, false, err
+		}
+	}
+}
+
+// subjectHash returns a short, stable hex digest of an OIDC subject.
+func subjectHash(oidcSub string) string {
+	sum := sha256.Sum256([]byte(oidcSub))
+	return hex.EncodeToString(sum[:4])
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b) // crypto/rand.Read never fails
+	return hex.EncodeToString(b)
+}
+
+// truncateUsername shortens username so that reserve more bytes still fit in
+// users.username, without splitting a character.
+func truncateUsername(username string, reserve int) string {
+	limit := maxUsernameLength - reserve
+	if len(username) <= limit {
+		return username
+	}
+	cut := 0
+	for i := range username {
+		if i > limit {
+			break
+		}
+		cut = i
+	}
+	return username[:cut]
 }
 
 // GetUserByID retrieves a user by their ID.
@@ -79,18 +190,129 @@ func (s *Service) GetUserByID(ctx context.Context, id int32) (*db.User, error) {
 
 // ActivateUser activates a user account (admin only).
 func (s *Service) ActivateUser(ctx context.Context, userID int32) (*db.User, error) {
-	user, err := s.queries.ActivateUser(ctx, userID)
+	user, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.User, error) {
+		return q.ActivateUser(ctx, userID)
+	})
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
+	s.invalidatePrincipal(ctx, userID)
 	return &user, nil
 }
 
 // DeactivateUser deactivates a user account (admin only).
 func (s *Service) DeactivateUser(ctx context.Context, userID int32) (*db.User, error) {
-	user, err := s.queries.DeactivateUser(ctx, userID)
+	user, err := dbtx.Write(ctx, s.runner, func(q *db.Queries) (db.User, error) {
+		return q.DeactivateUser(ctx, userID)
+	})
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
+	s.invalidatePrincipal(ctx, userID)
 	return &user, nil
 }
+
+// ListPendingUsers returns a page of users awaiting approval and the total pending count.
+func (s *Service) ListPendingUsers(ctx context.Context, limit, offset int32) ([]db.User, int64, error) {
+	users, err := s.queries.ListPendingUsers(ctx, db.ListPendingUsersParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.queries.CountPendingUsers(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if users == nil {
+		users = []db.User{}
+	}
+	return users, total, nil
+}
+
+// ApproveUser activates a pending (or previously rejected) user and records the
+// decision in the audit log on behalf of the approving admin.
+func (s *Service) ApproveUser(ctx context.Context, userID, approvedBy int32, reason string) (*db.User, error) {
+	return s.decideApproval(ctx, userID, approvedBy, reason, auditActionApprove, func(q *db.Queries) (db.User, error) {
+		return q.ApproveUser(ctx, userID)
+	})
+}
+
+// RejectUser rejects a pending user and records the decision in the audit log
+// on behalf of the rejecting admin.
+func (s *Service) RejectUser(ctx context.Context, userID, rejectedBy int32, reason string) (*db.User, error) {
+	return s.decideApproval(ctx, userID, rejectedBy, reason, auditActionReject, func(q *db.Queries) (db.User, error) {
+		return q.RejectUser(ctx, userID)
+	})
+}
+
+// approvalAuditData is the audit log payload for an approval decision.
+type approvalAuditData struct {
+	db.User
+	Reason string `json:"reason,omitempty"`
+}
+
+// decideApproval applies an approval decision and its audit log entry in one transaction.
+func (s *Service) decideApproval(
+	ctx context.Context,
+	userID, decidedBy int32,
+	reason, action string,
+	apply func(q *db.Queries) (db.User, error),
+) (*db.User, error) {
+	var after db.User
+	err := s.runner.InTx(ctx, func(q *db.Queries) error {
+		before, err := q.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		after, err = apply(q)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotPending
+			}
+			return err
+		}
+
+		oldData, err := json.Marshal(approvalAuditData{User: before})
+		if err != nil {
+			return err
+		}
+		newData, err := json.Marshal(approvalAuditData{User: after, Reason: reason})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAuditLog(ctx, db.CreateAuditLogParams{
+			TableName: "users",
+			RecordID:  pgtype.Text{String: strconv.FormatInt(int64(userID), 10), Valid: true},
+			Action:    action,
+			OldData:   oldData,
+			NewData:   newData,
+			ChangedBy: pgtype.Int4{Int32: decidedBy, Valid: decidedBy > 0},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidatePrincipal(ctx, userID)
+	return &after, nil
+}
+
+// invalidatePrincipal drops the user's cached principal after a status change.
+// Failures are logged rather than returned because the change itself succeeded.
+func (s *Service) invalidatePrincipal(ctx context.Context, userID int32) {
+	if s.principals == nil {
+		return
+	}
+	if err := s.principals.InvalidatePrincipal(ctx, userID); err != nil {
+		log.Printf("user: failed to invalidate principal for user %d: %v", userID, err)
+	}
+}